@@ -4,8 +4,12 @@ import (
 	"boot-whatsapp-golang/internal/config"
 	"boot-whatsapp-golang/internal/handlers"
 	"boot-whatsapp-golang/internal/middleware"
+	"boot-whatsapp-golang/internal/repository"
 	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/migrations"
 	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/metrics"
+	"boot-whatsapp-golang/pkg/validator"
 	"context"
 	"database/sql"
 	"errors"
@@ -33,6 +37,10 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:], logger.New("[MIGRATE] ", logger.INFO)))
+	}
+
 	fmt.Printf(Banner, Version)
 
 	log := logger.New("[API] ", logger.INFO)
@@ -43,6 +51,13 @@ func main() {
 		log.Fatalf("Falha ao carregar configuração: %v", err)
 	}
 	log.Info("Configuração carregada com sucesso")
+	validator.SetDefaultCountry(cfg.WhatsApp.DefaultCountry)
+	if cfg.Auth.AllowLegacyToken {
+		log.Warn("AUTH_ALLOW_LEGACY_TOKEN ativo: API_TOKEN + SESSIONKEY ainda são aceitos. Migre os clientes para chaves por tenant e desative.")
+	}
+	if cfg.Auth.AdminToken == "" {
+		log.Warn("ADMIN_TOKEN não configurado: endpoints de administração de tenants desabilitados")
+	}
 
 	db, err := sql.Open(cfg.Database.Driver, cfg.Database.DSN)
 	if err != nil {
@@ -60,16 +75,39 @@ func main() {
 	}
 	log.Info("Conectado ao banco de dados com sucesso")
 
+	if cfg.Database.AutoMigrate {
+		migrator, err := migrations.New(db, cfg.Database.Driver, log)
+		if err != nil {
+			log.Fatalf("Falha ao carregar migrações: %v", err)
+		}
+		count, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("Falha ao aplicar migrações: %v", err)
+		}
+		log.Infof("Schema atualizado (%d migração(ões) aplicada(s))", count)
+	} else {
+		log.Warn("DB_AUTO_MIGRATE desativado: aplique as migrações com 'whatsapp-bot migrate up'")
+	}
+
 	whatsappService, err := services.NewMultiTenantWhatsAppService(cfg, db, log)
 	if err != nil {
 		log.Fatalf("Falha ao inicializar serviço WhatsApp: %v", err)
 	}
 	log.Info("Serviço WhatsApp Multi Sessões inicializado")
 
+	tenantService := services.NewTenantService(repository.NewTenantRepository(db, log), log)
+
 	messageHandler := handlers.NewMultiTenantHandler(whatsappService, cfg, log)
 	sessionHandler := handlers.NewSessionHandler(whatsappService, log)
+	webhookHandler := handlers.NewWebhookHandler(whatsappService, log)
+	eventHandler := handlers.NewEventHandler(whatsappService, cfg, log)
+	messageQueryHandler := handlers.NewMessageHandler(whatsappService, log)
+	adminHandler := handlers.NewAdminHandler(tenantService, log)
+	groupHandler := handlers.NewGroupHandler(whatsappService, log)
+	campaignHandler := handlers.NewCampaignHandler(whatsappService, cfg, log)
+	templateHandler := handlers.NewTemplateHandler(whatsappService, log)
 
-	router := setupRouter(messageHandler, sessionHandler, cfg, log)
+	router := setupRouter(messageHandler, sessionHandler, webhookHandler, eventHandler, messageQueryHandler, adminHandler, groupHandler, campaignHandler, templateHandler, tenantService, whatsappService.StreamTokens(), cfg, log)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -84,13 +122,37 @@ func main() {
 		log.Infof("Servidor API escutando na porta %s", cfg.Server.Port)
 		log.Infof("Health check disponível em: http://localhost:%s/health", cfg.Server.Port)
 		log.Info("Endpoints disponíveis:")
+		log.Info("  POST /api/v1/admin/tenants - Cadastrar tenant (X-Admin-Token)")
+		log.Info("  POST /api/v1/admin/tenants/{tenantId}/keys - Criar chave de API do tenant")
 		log.Info("  POST /api/v1/whatsapp/register - Registrar nova sessão WhatsApp")
 		log.Info("  GET  /api/v1/whatsapp/qrcode/{sessionKey} - Obter QR code de sessão")
+		log.Info("  GET  /api/v1/whatsapp/pairing-code/{sessionKey} - Obter código de pareamento")
 		log.Info("  GET  /api/v1/whatsapp/sessions - Listar todas as sessões")
 		log.Info("  POST /api/v1/whatsapp/disconnect/{sessionKey} - Desconectar sessão")
 		log.Info("  DELETE /api/v1/whatsapp/sessions/{sessionKey} - Deletar sessão")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey} - Detalhes da sessão (limites e fila)")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey}/queue - Profundidade da fila de envio")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey}/scheduled - Envios agendados (send_at)")
+		log.Info("  PATCH/DELETE /api/v1/whatsapp/sessions/{sessionKey}/scheduled/{jobId} - Remarcar ou cancelar agendamento")
+		log.Info("  PUT  /api/v1/whatsapp/sessions/{sessionKey}/webhook - Configurar webhook da sessão")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey}/webhook/deliveries - Listar entregas de webhook")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey}/events - Stream de eventos (SSE / WebSocket em /events/ws)")
+		log.Info("  GET  /api/v1/whatsapp/sessions/{sessionKey}/groups - Listar/criar grupos (POST) e gerenciar participantes")
 		log.Info("  POST /api/v1/messages/text - Enviar mensagem de texto")
 		log.Info("  POST /api/v1/messages/media - Enviar mensagem com mídia")
+		log.Info("  POST /api/v1/messages/{location,contacts,poll} - Enviar localização, contatos ou enquete")
+		log.Info("  POST /api/v1/media - Pré-upload de mídia (devolve media_id)")
+		log.Info("  GET  /api/v1/messages/{messageId}/poll - Apuração de enquete")
+		log.Info("  GET  /api/v1/messages - Listar mensagens enviadas e recebidas")
+		log.Info("  PUT  /api/v1/messages/{messageId}/reaction - Reagir (PATCH edita, DELETE apaga a mensagem)")
+		log.Info("  GET  /api/v1/messages/{messageId} - Consultar status de uma mensagem")
+		log.Info("  GET  /api/v1/messages/jobs/{jobId} - Consultar job de envio assíncrono")
+		log.Info("  POST /api/v1/messages/template - Enviar mensagem a partir de um template")
+		log.Info("  POST /api/v1/contacts/check - Verificar números no WhatsApp (JID canônico)")
+		log.Info("  POST /api/v1/templates - Criar template (PUT grava nova versão, /preview renderiza)")
+		log.Info("  POST /api/v1/campaigns - Criar campanha (destinatários em /recipients, CSV ou JSON)")
+		log.Info("  POST /api/v1/campaigns/{campaignId}/{start,pause,resume,cancel} - Controlar campanha")
+		log.Info("  GET  /api/v1/campaigns/{campaignId}/recipients/export - Relatório da campanha em CSV")
 
 		serverErrors <- server.ListenAndServe()
 	}()
@@ -124,36 +186,122 @@ func main() {
 	}
 }
 
-func setupRouter(mh *handlers.MultiTenantHandler, sh *handlers.SessionHandler, cfg *config.Config, log *logger.Logger) *mux.Router {
+func setupRouter(mh *handlers.MultiTenantHandler, sh *handlers.SessionHandler, wh *handlers.WebhookHandler, eh *handlers.EventHandler, qh *handlers.MessageHandler, ah *handlers.AdminHandler, gh *handlers.GroupHandler, ch *handlers.CampaignHandler, th *handlers.TemplateHandler, tenants *services.TenantService, streamTokens *services.StreamTokens, cfg *config.Config, log *logger.Logger) *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/health", mh.Health).Methods("GET")
 
+	// /metrics fica fora do /api/v1: o scrape usa o METRICS_TOKEN, não as
+	// chaves dos tenants.
+	if cfg.Metrics.Enabled {
+		r.Handle("/metrics", middleware.MetricsAuthMiddleware(cfg, log)(metrics.Default.Handler())).Methods("GET")
+	}
+
+	// Registrado antes de /api/v1 para não passar pelo AuthMiddleware de tenant.
+	admin := r.PathPrefix("/api/v1/admin").Subrouter()
+	admin.HandleFunc("/tenants", ah.CreateTenant).Methods("POST")
+	admin.HandleFunc("/tenants", ah.ListTenants).Methods("GET")
+	admin.HandleFunc("/tenants/{tenantId}", ah.UpdateTenant).Methods("PATCH")
+	admin.HandleFunc("/tenants/{tenantId}/keys", ah.CreateAPIKey).Methods("POST")
+	admin.HandleFunc("/tenants/{tenantId}/keys", ah.ListAPIKeys).Methods("GET")
+	admin.HandleFunc("/tenants/{tenantId}/keys/{keyId}/rotate", ah.RotateAPIKey).Methods("POST")
+	admin.HandleFunc("/tenants/{tenantId}/keys/{keyId}", ah.RevokeAPIKey).Methods("DELETE")
+	admin.Use(middleware.AdminMiddleware(cfg, log))
+
+	// Os streams aceitam ?token= além do apitoken; registrados antes de
+	// /api/v1 para não passar pelo AuthMiddleware comum.
+	streamAuth := middleware.StreamAuthMiddleware(cfg, tenants, streamTokens, log)
+	r.Handle("/api/v1/whatsapp/sessions/{sessionKey}/events", streamAuth(http.HandlerFunc(eh.StreamSSE))).Methods("GET")
+	r.Handle("/api/v1/whatsapp/sessions/{sessionKey}/events/ws", streamAuth(http.HandlerFunc(eh.StreamWebSocket))).Methods("GET")
+
 	api := r.PathPrefix("/api/v1").Subrouter()
 
 	api.HandleFunc("/whatsapp/register", sh.RegisterSession).Methods("POST")
 	api.HandleFunc("/whatsapp/qrcode/{sessionKey}", sh.GetQRCode).Methods("GET")
+	api.HandleFunc("/whatsapp/pairing-code/{sessionKey}", sh.GetPairingCode).Methods("GET")
 	api.HandleFunc("/whatsapp/sessions", sh.ListSessions).Methods("GET")
 	api.HandleFunc("/whatsapp/disconnect/{sessionKey}", sh.DisconnectSession).Methods("POST")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}", sh.GetSession).Methods("GET")
 	api.HandleFunc("/whatsapp/sessions/{sessionKey}", sh.DeleteSession).Methods("DELETE")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/queue", sh.GetQueueDepth).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/scheduled", sh.ListScheduled).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/scheduled/{jobId}", sh.RescheduleMessage).Methods("PATCH")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/scheduled/{jobId}", sh.CancelScheduled).Methods("DELETE")
+
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/webhook", wh.SetWebhook).Methods("PUT")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/webhook", wh.GetWebhook).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/webhook", wh.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/webhook/deliveries", wh.ListDeliveries).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/webhook/deliveries/{deliveryId}/retry", wh.RetryDelivery).Methods("POST")
+
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/events/token", eh.IssueToken).Methods("POST")
+
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups", gh.ListGroups).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups", gh.CreateGroup).Methods("POST")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/join", gh.JoinGroup).Methods("POST")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}", gh.GetGroup).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}", gh.UpdateGroup).Methods("PATCH")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/participants", gh.UpdateParticipants).Methods("POST")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/picture", gh.SetPicture).Methods("PUT")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/picture", gh.RemovePicture).Methods("DELETE")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/invite-link", gh.GetInviteLink).Methods("GET")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/invite-link/revoke", gh.RevokeInviteLink).Methods("POST")
+	api.HandleFunc("/whatsapp/sessions/{sessionKey}/groups/{groupJid}/leave", gh.LeaveGroup).Methods("POST")
 
 	api.HandleFunc("/messages/text", mh.SendTextMessage).Methods("POST")
 	api.HandleFunc("/messages/media", mh.SendMediaMessage).Methods("POST")
+	api.HandleFunc("/messages/location", mh.SendLocationMessage).Methods("POST")
+	api.HandleFunc("/messages/contacts", mh.SendContactsMessage).Methods("POST")
+	api.HandleFunc("/messages/poll", mh.SendPollMessage).Methods("POST")
+	api.HandleFunc("/messages/template", mh.SendTemplateMessage).Methods("POST")
+	api.HandleFunc("/contacts/check", mh.CheckContacts).Methods("POST")
+	api.HandleFunc("/media", mh.UploadMedia).Methods("POST")
+	api.HandleFunc("/media/{mediaId}", mh.GetMedia).Methods("GET")
+	api.HandleFunc("/messages", qh.ListMessages).Methods("GET")
+	api.HandleFunc("/messages/jobs/{jobId}", qh.GetJob).Methods("GET")
+	api.HandleFunc("/messages/{messageId}", qh.GetMessage).Methods("GET")
+	api.HandleFunc("/messages/{messageId}", qh.EditMessage).Methods("PATCH")
+	api.HandleFunc("/messages/{messageId}", qh.RevokeMessage).Methods("DELETE")
+	api.HandleFunc("/messages/{messageId}/poll", qh.GetPollResults).Methods("GET")
+	api.HandleFunc("/messages/{messageId}/reaction", qh.ReactToMessage).Methods("PUT")
+	api.HandleFunc("/messages/{messageId}/reaction", qh.RemoveReaction).Methods("DELETE")
+
+	api.HandleFunc("/templates", th.CreateTemplate).Methods("POST")
+	api.HandleFunc("/templates", th.ListTemplates).Methods("GET")
+	api.HandleFunc("/templates/{templateId}", th.GetTemplate).Methods("GET")
+	api.HandleFunc("/templates/{templateId}", th.UpdateTemplate).Methods("PUT")
+	api.HandleFunc("/templates/{templateId}", th.DeleteTemplate).Methods("DELETE")
+	api.HandleFunc("/templates/{templateId}/versions", th.ListTemplateVersions).Methods("GET")
+	api.HandleFunc("/templates/{templateId}/preview", th.PreviewTemplate).Methods("POST")
+
+	api.HandleFunc("/campaigns", ch.CreateCampaign).Methods("POST")
+	api.HandleFunc("/campaigns", ch.ListCampaigns).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}", ch.GetCampaign).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}/recipients", ch.AddRecipients).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/recipients", ch.ListRecipients).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}/recipients/export", ch.ExportRecipients).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}/start", ch.StartCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/pause", ch.PauseCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/resume", ch.ResumeCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/cancel", ch.CancelCampaign).Methods("POST")
 
 	api.HandleFunc("/sendText", mh.SendTextMessage).Methods("POST")
 	api.HandleFunc("/sendMedia", mh.SendMediaMessage).Methods("POST")
 
+	r.Use(middleware.MetricsMiddleware())
 	r.Use(middleware.RecoveryMiddleware(log))
 	r.Use(middleware.LoggingMiddleware(log))
 	r.Use(middleware.CORSMiddleware())
 	r.Use(middleware.ContentTypeMiddleware())
 
 	api.Use(func(next http.Handler) http.Handler {
-		return middleware.AuthMiddleware(cfg, log)(next)
+		return middleware.AuthMiddleware(cfg, tenants, log)(next)
 	})
 
-	r.NotFoundHandler = http.HandlerFunc(mh.NotFound)
-	r.MethodNotAllowedHandler = http.HandlerFunc(mh.MethodNotAllowed)
+	// Os middlewares do mux só rodam em rotas casadas; 404 e 405 são
+	// contados à parte, com a rota "unmatched".
+	r.NotFoundHandler = middleware.MetricsMiddleware()(http.HandlerFunc(mh.NotFound))
+	r.MethodNotAllowedHandler = middleware.MetricsMiddleware()(http.HandlerFunc(mh.MethodNotAllowed))
 
 	return r
 }
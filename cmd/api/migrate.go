@@ -0,0 +1,97 @@
+package main
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/migrations"
+	"boot-whatsapp-golang/pkg/logger"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `Uso: whatsapp-bot migrate <comando>
+
+Comandos:
+  up         aplica todas as migrações pendentes
+  down [n]   reverte as últimas n migrações (padrão 1)
+  status     lista as migrações e se já foram aplicadas
+`
+
+// runMigrate implementa o subcomando "migrate" e devolve o código de saída.
+func runMigrate(args []string, log *logger.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	dbCfg := config.LoadDatabase()
+	db, err := sql.Open(dbCfg.Driver, dbCfg.DSN)
+	if err != nil {
+		log.Errorf("Falha ao conectar ao banco de dados: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Errorf("Erro ao fechar conexão com banco de dados: %v", err)
+		}
+	}()
+
+	migrator, err := migrations.New(db, dbCfg.Driver, log)
+	if err != nil {
+		log.Errorf("Falha ao carregar migrações: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			log.Errorf("%v", err)
+			return 1
+		}
+		log.Infof("%d migração(ões) aplicada(s)", count)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "quantidade inválida: %s\n", args[1])
+				return 2
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Errorf("%v", err)
+			return 1
+		}
+		log.Infof("%d migração(ões) revertida(s)", count)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Errorf("%v", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSÃO\tNOME\tAPLICADA EM")
+		for _, st := range status {
+			appliedAt := "pendente"
+			if st.AppliedAt != nil {
+				appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%03d\t%s\t%s\n", st.Version, st.Name, appliedAt)
+		}
+		_ = tw.Flush()
+
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,6 +15,15 @@ type Config struct {
 	WhatsApp WhatsAppConfig
 	Auth     AuthConfig
 	Database DatabaseConfig
+	Webhook  WebhookConfig
+	Events   EventStreamConfig
+	Queue    QueueConfig
+	Limits   RateLimitConfig
+	Preview  LinkPreviewConfig
+	Fetch    FetchConfig
+	Media    MediaCacheConfig
+	Campaign CampaignConfig
+	Metrics  MetricsConfig
 }
 
 type ServerConfig struct {
@@ -30,16 +40,117 @@ type WhatsAppConfig struct {
 	DefaultCountry string
 	QRCodeGenerate bool
 	ReconnectDelay time.Duration
+	// ContactCacheTTL é por quanto tempo o resultado de uma consulta de
+	// número no WhatsApp é reaproveitado pela sessão (0 desativa o cache).
+	ContactCacheTTL time.Duration
 }
 
 type AuthConfig struct {
-	APIToken   string
-	SessionKey string
+	// APIToken e SessionKey só são usados no modo legado
+	// (AUTH_ALLOW_LEGACY_TOKEN), durante a migração para chaves por tenant.
+	APIToken         string
+	SessionKey       string
+	AllowLegacyToken bool
+	AdminToken       string
+}
+
+type WebhookConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+}
+
+type EventStreamConfig struct {
+	BufferSize     int
+	Heartbeat      time.Duration
+	AllowedOrigins []string
+	// TokenSecret assina os tokens de stream da query string. Vazio gera
+	// uma chave por processo: tokens emitidos antes de um restart deixam
+	// de valer.
+	TokenSecret string
+	TokenTTL    time.Duration
+}
+
+type QueueConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	PollInterval   time.Duration
+	DrainTimeout   time.Duration
+	// ScheduleRetryWindow é por quanto tempo, a partir do horário agendado,
+	// um envio com send_at aguarda a sessão reconectar antes de falhar.
+	ScheduleRetryWindow time.Duration
+}
+
+// RateLimitConfig define os token buckets de envio e de consulta de
+// números (estes contados em números consultados). Limites <= 0 desativam o
+// bucket correspondente.
+type RateLimitConfig struct {
+	SessionPerMinute      int
+	SessionBurst          int
+	NewRecipientPerMinute int
+	NewRecipientBurst     int
+	TenantPerMinute       int
+	TenantBurst           int
+
+	ContactCheckSessionPerMinute int
+	ContactCheckSessionBurst     int
+	ContactCheckTenantPerMinute  int
+	ContactCheckTenantBurst      int
+}
+
+// LinkPreviewConfig controla a prévia automática de links nas mensagens de
+// texto. MaxBytes limita tanto o HTML lido quanto a imagem da prévia.
+type LinkPreviewConfig struct {
+	Enabled   bool
+	Timeout   time.Duration
+	MaxBytes  int64
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+// FetchConfig restringe as URLs que o servidor busca em nome dos clientes
+// (media_url e prévias de links). Hosts aceitam "*.dominio".
+type FetchConfig struct {
+	Timeout        time.Duration
+	AllowedSchemes []string
+	AllowedHosts   []string
+	DeniedHosts    []string
+	AllowPrivate   bool
+	MaxRedirects   int
+}
+
+// MediaCacheConfig controla o reaproveitamento de uploads de mídia. TTL não
+// deve passar da retenção de mídia do WhatsApp; quando a URL do upload traz
+// o próprio vencimento, vale o que vier primeiro.
+type MediaCacheConfig struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+// CampaignConfig limita o tamanho das campanhas e define o ritmo padrão
+// das que não informam per_minute. Tentativas e backoff seguem QUEUE_*.
+type CampaignConfig struct {
+	MaxRecipients    int
+	DefaultPerMinute int
+}
+
+// MetricsConfig controla o endpoint /metrics. Com Token preenchido, o
+// scrape exige Authorization: Bearer <token>, independente das chaves dos
+// tenants.
+type MetricsConfig struct {
+	Enabled bool
+	Token   string
 }
 
 type DatabaseConfig struct {
 	Driver string
 	DSN    string
+	// AutoMigrate aplica as migrações embutidas na inicialização.
+	AutoMigrate bool
 }
 
 func Load() (*Config, error) {
@@ -59,27 +170,104 @@ func Load() (*Config, error) {
 			DefaultCountry: getEnv("WHATSAPP_DEFAULT_COUNTRY", "55"),
 			QRCodeGenerate: getBoolEnv("WHATSAPP_QR_GENERATE", true),
 			ReconnectDelay: getDurationEnv("WHATSAPP_RECONNECT_DELAY", 5*time.Second),
+
+			ContactCacheTTL: getDurationEnv("WHATSAPP_CONTACT_CACHE_TTL", 24*time.Hour),
 		},
 		Auth: AuthConfig{
-			APIToken:   getEnv("API_TOKEN", ""),
-			SessionKey: getEnv("SESSION_KEY", ""),
+			APIToken:         getEnv("API_TOKEN", ""),
+			SessionKey:       getEnv("SESSION_KEY", ""),
+			AllowLegacyToken: getBoolEnv("AUTH_ALLOW_LEGACY_TOKEN", false),
+			AdminToken:       getEnv("ADMIN_TOKEN", ""),
+		},
+		Database: LoadDatabase(),
+		Webhook: WebhookConfig{
+			MaxAttempts:    getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+			InitialBackoff: getDurationEnv("WEBHOOK_INITIAL_BACKOFF", 10*time.Second),
+			MaxBackoff:     getDurationEnv("WEBHOOK_MAX_BACKOFF", 1*time.Hour),
+			Timeout:        getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			PollInterval:   getDurationEnv("WEBHOOK_POLL_INTERVAL", 2*time.Second),
+			BatchSize:      getIntEnv("WEBHOOK_BATCH_SIZE", 50),
+		},
+		Events: EventStreamConfig{
+			BufferSize:     getIntEnv("EVENT_STREAM_BUFFER_SIZE", 256),
+			Heartbeat:      getDurationEnv("EVENT_STREAM_HEARTBEAT", 15*time.Second),
+			AllowedOrigins: getListEnv("EVENT_STREAM_ALLOWED_ORIGINS"),
+			TokenSecret:    getEnv("EVENT_STREAM_TOKEN_SECRET", ""),
+			TokenTTL:       getDurationEnv("EVENT_STREAM_TOKEN_TTL", 5*time.Minute),
+		},
+		Queue: QueueConfig{
+			MaxAttempts:    getIntEnv("QUEUE_MAX_ATTEMPTS", 5),
+			InitialBackoff: getDurationEnv("QUEUE_INITIAL_BACKOFF", 5*time.Second),
+			MaxBackoff:     getDurationEnv("QUEUE_MAX_BACKOFF", 5*time.Minute),
+			PollInterval:   getDurationEnv("QUEUE_POLL_INTERVAL", 5*time.Second),
+			DrainTimeout:   getDurationEnv("QUEUE_DRAIN_TIMEOUT", 10*time.Second),
+
+			ScheduleRetryWindow: getDurationEnv("SCHEDULE_DISCONNECTED_RETRY", 15*time.Minute),
+		},
+		Limits: RateLimitConfig{
+			SessionPerMinute:      getIntEnv("RATE_LIMIT_SESSION_PER_MINUTE", 30),
+			SessionBurst:          getIntEnv("RATE_LIMIT_SESSION_BURST", 10),
+			NewRecipientPerMinute: getIntEnv("RATE_LIMIT_NEW_RECIPIENT_PER_MINUTE", 5),
+			NewRecipientBurst:     getIntEnv("RATE_LIMIT_NEW_RECIPIENT_BURST", 3),
+			TenantPerMinute:       getIntEnv("RATE_LIMIT_TENANT_PER_MINUTE", 120),
+			TenantBurst:           getIntEnv("RATE_LIMIT_TENANT_BURST", 30),
+
+			ContactCheckSessionPerMinute: getIntEnv("RATE_LIMIT_CONTACT_CHECK_PER_MINUTE", 60),
+			ContactCheckSessionBurst:     getIntEnv("RATE_LIMIT_CONTACT_CHECK_BURST", 100),
+			ContactCheckTenantPerMinute:  getIntEnv("RATE_LIMIT_TENANT_CONTACT_CHECK_PER_MINUTE", 200),
+			ContactCheckTenantBurst:      getIntEnv("RATE_LIMIT_TENANT_CONTACT_CHECK_BURST", 300),
 		},
-		Database: DatabaseConfig{
-			Driver: getEnv("DB_DRIVER", "sqlite3"),
-			DSN:    getEnv("DB_DSN", "file:whatsapp.db?_foreign_keys=on"),
+		Preview: LinkPreviewConfig{
+			Enabled:   getBoolEnv("LINK_PREVIEW_ENABLED", true),
+			Timeout:   getDurationEnv("LINK_PREVIEW_TIMEOUT", 5*time.Second),
+			MaxBytes:  getInt64Env("LINK_PREVIEW_MAX_BYTES", 1<<20), // 1MB
+			CacheTTL:  getDurationEnv("LINK_PREVIEW_CACHE_TTL", 1*time.Hour),
+			CacheSize: getIntEnv("LINK_PREVIEW_CACHE_SIZE", 1000),
+		},
+		Fetch: FetchConfig{
+			Timeout:        getDurationEnv("FETCH_TIMEOUT", 2*time.Minute),
+			AllowedSchemes: getListEnvDefault("FETCH_ALLOWED_SCHEMES", []string{"https", "http"}),
+			AllowedHosts:   getListEnv("FETCH_ALLOWED_HOSTS"),
+			DeniedHosts:    getListEnv("FETCH_DENIED_HOSTS"),
+			AllowPrivate:   getBoolEnv("FETCH_ALLOW_PRIVATE_NETWORKS", false),
+			MaxRedirects:   getIntEnv("FETCH_MAX_REDIRECTS", 3),
+		},
+		Media: MediaCacheConfig{
+			Enabled: getBoolEnv("MEDIA_CACHE_ENABLED", true),
+			TTL:     getDurationEnv("MEDIA_CACHE_TTL", 30*24*time.Hour),
+		},
+		Campaign: CampaignConfig{
+			MaxRecipients:    getIntEnv("CAMPAIGN_MAX_RECIPIENTS", 10000),
+			DefaultPerMinute: getIntEnv("CAMPAIGN_DEFAULT_PER_MINUTE", 20),
+		},
+		Metrics: MetricsConfig{
+			Enabled: getBoolEnv("METRICS_ENABLED", true),
+			Token:   getEnv("METRICS_TOKEN", ""),
 		},
 	}
 
-	if cfg.Auth.APIToken == "" {
-		return nil, fmt.Errorf("API_TOKEN is required")
+	if cfg.Auth.AllowLegacyToken && cfg.Auth.APIToken == "" {
+		return nil, fmt.Errorf("API_TOKEN is required when AUTH_ALLOW_LEGACY_TOKEN is enabled")
 	}
-	if cfg.Auth.SessionKey == "" {
-		return nil, fmt.Errorf("SESSION_KEY is required")
+	if !cfg.Auth.AllowLegacyToken && cfg.Auth.AdminToken == "" {
+		return nil, fmt.Errorf("ADMIN_TOKEN is required to manage tenant API keys")
 	}
 
 	return cfg, nil
 }
 
+// LoadDatabase lê apenas a configuração do banco, usada pelo subcomando
+// migrate sem exigir as credenciais da API.
+func LoadDatabase() DatabaseConfig {
+	_ = godotenv.Load()
+
+	return DatabaseConfig{
+		Driver:      getEnv("DB_DRIVER", "sqlite3"),
+		DSN:         getEnv("DB_DSN", "file:whatsapp.db?_foreign_keys=on"),
+		AutoMigrate: getBoolEnv("DB_AUTO_MIGRATE", true),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -87,6 +275,37 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getListEnv(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getListEnvDefault(key string, defaultValue []string) []string {
+	if items := getListEnv(key); len(items) > 0 {
+		return items
+	}
+	return defaultValue
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
 func getInt64Env(key string, defaultValue int64) int64 {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
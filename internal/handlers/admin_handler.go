@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	baseHandler
+	tenants *services.TenantService
+}
+
+func NewAdminHandler(tenants *services.TenantService, log *logger.Logger) *AdminHandler {
+	return &AdminHandler{baseHandler: baseHandler{logger: log}, tenants: tenants}
+}
+
+func (h *AdminHandler) tenantError(w http.ResponseWriter, tenantID string, err error) {
+	switch {
+	case errors.Is(err, services.ErrTenantNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Tenant não encontrado", "TENANT_NOT_FOUND", map[string]string{"tenant_id": tenantID})
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Chave de API não encontrada ou já revogada", "API_KEY_NOT_FOUND", nil)
+	default:
+		h.logger.Errorf("Falha na administração do tenant %s: %v", tenantID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Erro interno do servidor", "INTERNAL_ERROR", map[string]string{"error": err.Error()})
+	}
+}
+
+func (h *AdminHandler) keyID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(h.pathVar(r, "keyId"))
+	if err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "ID de chave inválido", "VALIDATION_ERROR", map[string]string{"keyId": "deve ser um UUID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *AdminHandler) CreateTenant(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTenantRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	tenant, err := h.tenants.CreateTenant(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrTenantAlreadyExists) {
+			h.errorJSON(w, http.StatusConflict, "Tenant já existe", "TENANT_EXISTS", map[string]string{"id": req.ID})
+			return
+		}
+		h.tenantError(w, req.ID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusCreated, "Tenant criado com sucesso", tenant)
+}
+
+func (h *AdminHandler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.tenants.ListTenants()
+	if err != nil {
+		h.tenantError(w, "", err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Tenants listados com sucesso", map[string]interface{}{
+		"total":   len(tenants),
+		"tenants": tenants,
+	})
+}
+
+func (h *AdminHandler) UpdateTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID := h.pathVar(r, "tenantId")
+
+	var req models.UpdateTenantRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	tenant, err := h.tenants.UpdateTenantStatus(tenantID, req.Status)
+	if err != nil {
+		h.tenantError(w, tenantID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Tenant atualizado com sucesso", tenant)
+}
+
+func (h *AdminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID := h.pathVar(r, "tenantId")
+
+	var req models.CreateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := validator.ValidateJSON(r, &req); err != nil {
+			h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+			return
+		}
+		if !h.validateRequest(w, &req) {
+			return
+		}
+	}
+
+	created, err := h.tenants.CreateAPIKey(tenantID, req.Name)
+	if err != nil {
+		h.tenantError(w, tenantID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusCreated, "Chave de API criada. Guarde o valor de key: ele não será exibido novamente.", created)
+}
+
+func (h *AdminHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID := h.pathVar(r, "tenantId")
+
+	keys, err := h.tenants.ListAPIKeys(tenantID)
+	if err != nil {
+		h.tenantError(w, tenantID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Chaves de API listadas com sucesso", map[string]interface{}{
+		"tenant_id": tenantID,
+		"total":     len(keys),
+		"keys":      keys,
+	})
+}
+
+func (h *AdminHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID := h.pathVar(r, "tenantId")
+	keyID, ok := h.keyID(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.tenants.RotateAPIKey(tenantID, keyID)
+	if err != nil {
+		h.tenantError(w, tenantID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Chave de API rotacionada. Guarde o valor de key: ele não será exibido novamente.", created)
+}
+
+func (h *AdminHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	tenantID := h.pathVar(r, "tenantId")
+	keyID, ok := h.keyID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.tenants.RevokeAPIKey(tenantID, keyID); err != nil {
+		h.tenantError(w, tenantID, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Chave de API revogada com sucesso", map[string]string{
+		"tenant_id": tenantID,
+		"key_id":    keyID.String(),
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/middleware"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type baseHandler struct {
+	logger *logger.Logger
+}
+
+func (h *baseHandler) writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func (h *baseHandler) errorJSON(
+	w http.ResponseWriter,
+	status int,
+	message, code string,
+	details map[string]string,
+) {
+	h.writeJSON(w, status, models.NewErrorResponse(message, code, details))
+}
+
+func (h *baseHandler) successJSON(w http.ResponseWriter, status int, message string, data any) {
+	h.writeJSON(w, status, models.NewSuccessResponse(message, data))
+}
+
+func (h *baseHandler) requireTenantID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == "" {
+		h.logger.Error("TenantID não encontrado no contexto")
+		h.errorJSON(w, http.StatusUnauthorized, "Não autorizado", "UNAUTHORIZED", nil)
+		return "", false
+	}
+	return tenantID, true
+}
+
+// respondSessionError responde 404 para chaves inexistentes e 403 para chaves
+// de outro tenant. Devolve false quando o erro não é de resolução de sessão.
+func (h *baseHandler) respondSessionError(w http.ResponseWriter, sessionKey string, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Sessão não encontrada", "SESSION_NOT_FOUND", map[string]string{"session_key": sessionKey})
+	case errors.Is(err, services.ErrSessionForbidden):
+		h.errorJSON(w, http.StatusForbidden, "Sessão não pertence a este tenant", "SESSION_FORBIDDEN", map[string]string{"session_key": sessionKey})
+	default:
+		return false
+	}
+	return true
+}
+
+// validateRequest aplica as tags validate do corpo já decodificado e responde
+// 400 com o motivo de cada campo inválido. Devolve false quando respondeu.
+func (h *baseHandler) validateRequest(w http.ResponseWriter, req any) bool {
+	errs := validator.Struct(req)
+	if errs == nil {
+		return true
+	}
+	h.logger.Warnf("Campos inválidos na requisição: %v", errs)
+	h.errorJSON(w, http.StatusBadRequest, "Campos inválidos", "VALIDATION_ERROR", errs)
+	return false
+}
+
+func (h *baseHandler) pathVar(r *http.Request, key string) string {
+	return mux.Vars(r)[key]
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberColumns são os nomes aceitos para a coluna do número no CSV; as
+// demais colunas viram variáveis da mensagem.
+var numberColumns = map[string]bool{
+	"number":   true,
+	"numero":   true,
+	"número":   true,
+	"telefone": true,
+	"phone":    true,
+	"celular":  true,
+}
+
+type CampaignHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+	config  *config.Config
+}
+
+func NewCampaignHandler(service *services.MultiTenantWhatsAppService, cfg *config.Config, log *logger.Logger) *CampaignHandler {
+	return &CampaignHandler{baseHandler: baseHandler{logger: log}, service: service, config: cfg}
+}
+
+func (h *CampaignHandler) campaignError(w http.ResponseWriter, campaignID string, err error, message, code string) {
+	var invalid *services.CampaignValidationError
+	var state *services.CampaignStateError
+	switch {
+	case errors.As(err, &invalid):
+		h.errorJSON(w, http.StatusBadRequest, "Campanha inválida", "VALIDATION_ERROR", invalid.Details)
+	case errors.As(err, &state):
+		h.errorJSON(w, http.StatusConflict, "Operação não permitida no status atual da campanha", "CAMPAIGN_INVALID_STATE", map[string]string{"status": state.Status})
+	case errors.Is(err, services.ErrCampaignNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Campanha não encontrada", "CAMPAIGN_NOT_FOUND", map[string]string{"campaign_id": campaignID})
+	default:
+		h.logger.Errorf("%s (campanha %s): %v", message, campaignID, err)
+		h.errorJSON(w, http.StatusInternalServerError, message, code, map[string]string{"error": err.Error()})
+	}
+}
+
+func (h *CampaignHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxUploadSize)
+	var req models.CampaignRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	campaign, err := h.service.CreateCampaign(tenantID, &req)
+	if err != nil {
+		if h.respondSessionError(w, req.SessionKey, err) {
+			return
+		}
+		h.campaignError(w, "", err, "Falha ao criar campanha", "CAMPAIGN_CREATE_FAILED")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/campaigns/"+campaign.ID.String())
+	h.successJSON(w, http.StatusCreated, "Campanha criada com sucesso", campaign)
+}
+
+func (h *CampaignHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	campaigns, err := h.service.ListCampaigns(tenantID)
+	if err != nil {
+		h.campaignError(w, "", err, "Falha ao listar campanhas", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Campanhas listadas com sucesso", map[string]interface{}{
+		"total":     len(campaigns),
+		"campaigns": campaigns,
+	})
+}
+
+func (h *CampaignHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID := h.pathVar(r, "campaignId")
+
+	campaign, err := h.service.GetCampaign(tenantID, campaignID)
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao obter campanha", "CAMPAIGN_FETCH_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Campanha obtida com sucesso", campaign)
+}
+
+// AddRecipients trata POST /campaigns/{campaignId}/recipients. Aceita um
+// CSV (text/csv, com cabeçalho e separador vírgula ou ponto e vírgula) ou
+// um array JSON de {"number", "variables"}.
+func (h *CampaignHandler) AddRecipients(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID := h.pathVar(r, "campaignId")
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxUploadSize)
+	var recipients []models.CampaignRecipientInput
+	if isCSV(r) {
+		var err error
+		if recipients, err = parseRecipientsCSV(r.Body); err != nil {
+			h.errorJSON(w, http.StatusBadRequest, "CSV inválido", "INVALID_CSV", map[string]string{"error": err.Error()})
+			return
+		}
+	} else if err := validator.ValidateJSON(r, &recipients); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+
+	added, err := h.service.AddCampaignRecipients(tenantID, campaignID, recipients)
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao adicionar destinatários", "RECIPIENTS_ADD_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Destinatários adicionados com sucesso", added)
+}
+
+func (h *CampaignHandler) StartCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.StartCampaign, "Campanha iniciada")
+}
+
+func (h *CampaignHandler) PauseCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.PauseCampaign, "Campanha pausada")
+}
+
+func (h *CampaignHandler) ResumeCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.ResumeCampaign, "Campanha retomada")
+}
+
+func (h *CampaignHandler) CancelCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.CancelCampaign, "Campanha cancelada")
+}
+
+func (h *CampaignHandler) transition(w http.ResponseWriter, r *http.Request, action func(tenantID, campaignID string) (*models.Campaign, error), message string) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID := h.pathVar(r, "campaignId")
+
+	campaign, err := action(tenantID, campaignID)
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao atualizar campanha", "CAMPAIGN_UPDATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, message, campaign)
+}
+
+// ListRecipients trata GET /campaigns/{campaignId}/recipients, com filtro
+// por status de relatório e paginação.
+func (h *CampaignHandler) ListRecipients(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID := h.pathVar(r, "campaignId")
+
+	q := r.URL.Query()
+	filter := models.CampaignRecipientFilter{Status: q.Get("status"), Limit: 100}
+	details := map[string]string{}
+
+	switch filter.Status {
+	case "", models.CampaignRecipientQueued, models.CampaignRecipientProcessing, models.CampaignRecipientSent,
+		models.CampaignRecipientDelivered, models.CampaignRecipientRead, models.CampaignRecipientFailed, models.CampaignRecipientCanceled:
+	default:
+		details["status"] = "use queued, processing, sent, delivered, read, failed ou canceled"
+	}
+	if raw := q.Get("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n <= 0 || n > 1000 {
+			details["limit"] = "entre 1 e 1000"
+		} else {
+			filter.Limit = n
+		}
+	}
+	if raw := q.Get("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			details["offset"] = "deve ser maior ou igual a zero"
+		} else {
+			filter.Offset = n
+		}
+	}
+	if len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Filtros inválidos", "VALIDATION_ERROR", details)
+		return
+	}
+
+	recipients, err := h.service.ListCampaignRecipients(tenantID, campaignID, filter)
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao listar destinatários", "LIST_FAILED")
+		return
+	}
+	total, err := h.service.CountCampaignRecipients(tenantID, campaignID, filter.Status)
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao listar destinatários", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Destinatários listados com sucesso", map[string]interface{}{
+		"total":      total,
+		"count":      len(recipients),
+		"limit":      filter.Limit,
+		"offset":     filter.Offset,
+		"recipients": recipients,
+	})
+}
+
+// ExportRecipients trata GET /campaigns/{campaignId}/recipients/export:
+// o relatório completo em CSV, com uma coluna por variável.
+func (h *CampaignHandler) ExportRecipients(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	campaignID := h.pathVar(r, "campaignId")
+
+	recipients, err := h.service.ListCampaignRecipients(tenantID, campaignID, models.CampaignRecipientFilter{})
+	if err != nil {
+		h.campaignError(w, campaignID, err, "Falha ao exportar destinatários", "EXPORT_FAILED")
+		return
+	}
+
+	varSet := map[string]bool{}
+	for _, rec := range recipients {
+		for name := range rec.Variables {
+			varSet[name] = true
+		}
+	}
+	vars := make([]string, 0, len(varSet))
+	for name := range varSet {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="campanha-%s.csv"`, campaignID))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	header := []string{"position", "number", "status", "attempts", "message_id", "sent_at", "delivered_at", "read_at", "error"}
+	for _, name := range vars {
+		header = append(header, csvCell(name))
+	}
+	_ = cw.Write(header)
+	for _, rec := range recipients {
+		row := []string{
+			strconv.Itoa(rec.Position),
+			rec.Number,
+			rec.Status,
+			strconv.Itoa(rec.Attempts),
+			derefString(rec.MessageID),
+			formatTime(rec.SentAt),
+			formatTime(rec.DeliveredAt),
+			formatTime(rec.ReadAt),
+			csvCell(derefString(rec.LastError)),
+		}
+		for _, name := range vars {
+			row = append(row, csvCell(rec.Variables[name]))
+		}
+		if err := cw.Write(row); err != nil {
+			h.logger.Warnf("Exportação da campanha %s interrompida: %v", campaignID, err)
+			return
+		}
+	}
+	cw.Flush()
+}
+
+// csvCell neutraliza valores que planilhas interpretariam como fórmula
+// (CSV injection): variáveis e erros vêm de fora e o relatório costuma ser
+// aberto no Excel ou no Google Sheets. O apóstrofo inicial faz a célula ser
+// lida como texto. Os números já estão normalizados em E.164 e não passam
+// por aqui.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func isCSV(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "text/csv" || mediaType == "application/csv")
+}
+
+// parseRecipientsCSV lê a lista de destinatários. O separador (vírgula ou
+// ponto e vírgula, comum em planilhas em português) é deduzido do
+// cabeçalho; nomes de coluna viram nomes de variável.
+func parseRecipientsCSV(body io.Reader) ([]models.CampaignRecipientInput, error) {
+	br := bufio.NewReader(body)
+	first, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	first = strings.TrimPrefix(first, "\ufeff")
+	if strings.TrimSpace(first) == "" {
+		return nil, fmt.Errorf("CSV vazio: a primeira linha deve ser o cabeçalho")
+	}
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(first), br))
+	if strings.Count(first, ";") > strings.Count(first, ",") {
+		reader.Comma = ';'
+	}
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cabeçalho inválido: %w", err)
+	}
+	numberCol := -1
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		if numberColumns[header[i]] && numberCol == -1 {
+			numberCol = i
+		}
+	}
+	if numberCol == -1 {
+		return nil, fmt.Errorf("cabeçalho sem coluna de número (use number, numero, telefone, phone ou celular)")
+	}
+
+	recipients := make([]models.CampaignRecipientInput, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rec := models.CampaignRecipientInput{
+			Number:    strings.TrimSpace(record[numberCol]),
+			Variables: make(map[string]string, len(record)-1),
+			Line:      line,
+		}
+		for i, value := range record {
+			if i != numberCol && header[i] != "" {
+				rec.Variables[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		recipients = append(recipients, rec)
+	}
+	return recipients, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"boot-whatsapp-golang/internal/models"
+)
+
+func TestParseRecipientsCSV(t *testing.T) {
+	type vars = map[string]string
+	tests := []struct {
+		name string
+		csv  string
+		want []models.CampaignRecipientInput
+	}{
+		{"vírgula", "number,nome\n5511987654321,Ana\n", []models.CampaignRecipientInput{
+			{Number: "5511987654321", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		{"ponto e vírgula com vírgula decimal", "telefone;nome;valor\n5511987654321;Ana;10,50\n", []models.CampaignRecipientInput{
+			{Number: "5511987654321", Variables: vars{"nome": "Ana", "valor": "10,50"}, Line: 2},
+		}},
+		{"ponto e vírgula nos dados não muda o separador", "number,nome,obs\n1,\"Silva; Ana\",\"a; b; c\"\n", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{"nome": "Silva; Ana", "obs": "a; b; c"}, Line: 2},
+		}},
+		{"BOM do Excel", "\ufeffNumero,Nome\n5511987654321,Ana\n", []models.CampaignRecipientInput{
+			{Number: "5511987654321", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		{"cabeçalho com maiúsculas, acento e espaços", " Nome ; Número \nAna; 5511987654321 \n", []models.CampaignRecipientInput{
+			{Number: "5511987654321", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		{"coluna de número no meio", "nome,celular,cidade\nAna,5511987654321,SP\n", []models.CampaignRecipientInput{
+			{Number: "5511987654321", Variables: vars{"nome": "Ana", "cidade": "SP"}, Line: 2},
+		}},
+		{"primeira coluna de número vence", "phone,telefone\n1,2\n", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{"telefone": "2"}, Line: 2},
+		}},
+		{"coluna sem nome é ignorada", "number,,nome\n1,x,Ana\n", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		{"linhas em branco são puladas", "number,nome\n\n1,Ana\n\n\n2,Bia", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{"nome": "Ana"}, Line: 3},
+			{Number: "2", Variables: vars{"nome": "Bia"}, Line: 6},
+		}},
+		{"fim de linha CRLF", "number,nome\r\n1,Ana\r\n", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		// Número vazio ou inválido segue adiante; a validação por linha fica
+		// com o serviço, que aponta a linha no erro.
+		{"número vazio", "number,nome\n,Ana\n", []models.CampaignRecipientInput{
+			{Number: "", Variables: vars{"nome": "Ana"}, Line: 2},
+		}},
+		{"só a coluna de número", "number\n1\n", []models.CampaignRecipientInput{
+			{Number: "1", Variables: vars{}, Line: 2},
+		}},
+		{"só o cabeçalho", "number,nome", []models.CampaignRecipientInput{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecipientsCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatalf("parseRecipientsCSV: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRecipientsCSV = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecipientsCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"vazio", "", "CSV vazio"},
+		{"só BOM", "\ufeff", "CSV vazio"},
+		{"primeira linha em branco", "  \nnumber\n1\n", "CSV vazio"},
+		{"sem coluna de número", "nome,email\nAna,ana@example.com\n", "sem coluna de número"},
+		{"linha com colunas a menos", "number,nome\n1,Ana\n2\n", "wrong number of fields"},
+		{"linha com colunas a mais", "number;nome\n1;Ana;x\n", "wrong number of fields"},
+		{"aspas soltas", "number,nome\n1,An\"a\n", "bare \""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecipientsCSV(strings.NewReader(tt.csv))
+			if err == nil {
+				t.Fatalf("parseRecipientsCSV = %+v, esperado erro", got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("erro = %q, esperado %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Ana", "Ana"},
+		{"10,50", "10,50"},
+		{"a=b", "a=b"},
+		{"'já protegido", "'já protegido"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+5511987654321", "'+5511987654321"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.in); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, esperado %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+)
+
+// CheckContacts trata POST /contacts/check: normaliza os números e devolve,
+// para os que têm WhatsApp, o JID canônico.
+func (h *MultiTenantHandler) CheckContacts(w http.ResponseWriter, r *http.Request) {
+	sessionKey := r.Header.Get("X-WhatsApp-Session-Key")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "Header X-WhatsApp-Session-Key é obrigatório", "MISSING_SESSION_KEY", nil)
+		return
+	}
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ContactCheckRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	result, err := h.whatsappService.CheckContacts(sessionKey, tenantID, req.Numbers)
+	if h.respondSessionError(w, sessionKey, err) {
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotConnected) {
+		h.errorJSON(w, http.StatusBadRequest, "Sessão não está conectada ao WhatsApp", "SESSION_NOT_CONNECTED", map[string]string{"session_key": sessionKey})
+		return
+	}
+	if h.respondLimited(w, sessionKey, err, "Limite de consultas de números excedido") {
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("[%s] Falha ao verificar números: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadGateway, "Falha ao verificar números no WhatsApp", "CONTACT_CHECK_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Infof("[%s] %d de %d números com WhatsApp", sessionKey, result.Registered, result.Total)
+	h.successJSON(w, http.StatusOK, "Números verificados com sucesso", result)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+type EventHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+	config  config.EventStreamConfig
+}
+
+func NewEventHandler(service *services.MultiTenantWhatsAppService, cfg *config.Config, log *logger.Logger) *EventHandler {
+	return &EventHandler{baseHandler: baseHandler{logger: log}, service: service, config: cfg.Events}
+}
+
+// IssueToken emite o token curto que autentica o stream pela query string
+// (?token=), para dashboards no navegador.
+func (h *EventHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	resp, err := h.service.IssueStreamToken(sessionKey, tenantID)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		h.logger.Errorf("Falha ao emitir token de stream para %s: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao emitir token de stream", "STREAM_TOKEN_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusCreated, "Token de stream emitido", resp)
+}
+
+func (h *EventHandler) lastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+func (h *EventHandler) subscribe(w http.ResponseWriter, r *http.Request) ([]*models.StreamEvent, <-chan *models.StreamEvent, func(), bool) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	lastID, err := h.lastEventID(r)
+	if err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Last-Event-ID inválido", "VALIDATION_ERROR", map[string]string{
+			"last_event_id": "deve ser numérico",
+		})
+		return nil, nil, nil, false
+	}
+
+	replay, ch, cancel, err := h.service.SubscribeEvents(sessionKey, tenantID, lastID)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return nil, nil, nil, false
+		}
+		h.logger.Errorf("Falha ao assinar eventos de %s: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao assinar eventos", "STREAM_FAILED", map[string]string{"error": err.Error()})
+		return nil, nil, nil, false
+	}
+
+	h.logger.Infof("Stream de eventos aberto: %s [Tenant: %s] (last_event_id=%d, replay=%d)", sessionKey, tenantID, lastID, len(replay))
+	return replay, ch, cancel, true
+}
+
+func (h *EventHandler) StreamSSE(w http.ResponseWriter, r *http.Request) {
+	replay, ch, cancel, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+	// O stream é de longa duração: remove o WriteTimeout global do servidor.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warnf("Não foi possível remover deadline de escrita do SSE: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	for _, se := range replay {
+		if err := writeSSE(w, se); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.Warnf("Streaming não suportado pelo ResponseWriter: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.config.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case se, open := <-ch:
+			if !open {
+				return
+			}
+			if err := writeSSE(w, se); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprintf(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, se *models.StreamEvent) error {
+	data, err := json.Marshal(se)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", se.StreamID, se.Type, data)
+	return err
+}
+
+func (h *EventHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	replay, ch, cancel, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	opts := &websocket.AcceptOptions{OriginPatterns: h.config.AllowedOrigins}
+	conn, err := websocket.Accept(w, r, opts)
+	if err != nil {
+		h.logger.Warnf("Falha no upgrade para WebSocket: %v", err)
+		return
+	}
+	defer func() {
+		_ = conn.CloseNow()
+	}()
+
+	// O cliente não envia nada; CloseRead cuida de pings/close e cancela o
+	// contexto quando a conexão cai.
+	ctx := conn.CloseRead(context.Background())
+
+	write := func(se *models.StreamEvent) error {
+		data, err := json.Marshal(se)
+		if err != nil {
+			return err
+		}
+		wctx, wcancel := context.WithTimeout(ctx, 10*time.Second)
+		defer wcancel()
+		return conn.Write(wctx, websocket.MessageText, data)
+	}
+
+	for _, se := range replay {
+		if err := write(se); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.config.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case se, open := <-ch:
+			if !open {
+				_ = conn.Close(websocket.StatusTryAgainLater, "stream atrasado, reconecte com last_event_id")
+				return
+			}
+			if err := write(se); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			pctx, pcancel := context.WithTimeout(ctx, 10*time.Second)
+			err := conn.Ping(pctx)
+			pcancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type GroupHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+}
+
+func NewGroupHandler(service *services.MultiTenantWhatsAppService, log *logger.Logger) *GroupHandler {
+	return &GroupHandler{baseHandler: baseHandler{logger: log}, service: service}
+}
+
+var groupErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{services.ErrSessionNotConnected, http.StatusBadRequest, "SESSION_NOT_CONNECTED", "Sessão não está conectada ao WhatsApp"},
+	{services.ErrInvalidGroupJID, http.StatusBadRequest, "INVALID_GROUP_JID", "JID de grupo inválido"},
+	{services.ErrGroupNotFound, http.StatusNotFound, "GROUP_NOT_FOUND", "Grupo não encontrado"},
+	{services.ErrNotInGroup, http.StatusForbidden, "NOT_IN_GROUP", "A sessão não participa deste grupo"},
+	{services.ErrGroupForbidden, http.StatusForbidden, "GROUP_PERMISSION_DENIED", "A sessão não tem permissão para esta ação (requer admin do grupo)"},
+	{services.ErrInviteLinkInvalid, http.StatusBadRequest, "INVITE_LINK_INVALID", "Link de convite inválido"},
+	{services.ErrInviteLinkRevoked, http.StatusGone, "INVITE_LINK_REVOKED", "Link de convite revogado"},
+	{services.ErrInvalidGroupPicture, http.StatusBadRequest, "INVALID_GROUP_PICTURE", "A foto do grupo deve ser uma imagem JPEG"},
+	{services.ErrMediaTooLarge, http.StatusRequestEntityTooLarge, "MEDIA_TOO_LARGE", "Mídia excede o tamanho máximo"},
+	{services.ErrMediaURLBlocked, http.StatusBadRequest, "MEDIA_URL_BLOCKED", "media_url não permitida pela política de download"},
+	{services.ErrMediaTypeMismatch, http.StatusBadRequest, "MEDIA_TYPE_MISMATCH", "Conteúdo da mídia não corresponde ao tipo informado"},
+	{services.ErrGroupRequestRejected, http.StatusUnprocessableEntity, "GROUP_REQUEST_REJECTED", "Requisição recusada pelo WhatsApp"},
+	{services.ErrGroupRateLimited, http.StatusTooManyRequests, "RATE_LIMITED", "Limite de requisições de grupo do WhatsApp excedido"},
+}
+
+func (h *GroupHandler) groupError(w http.ResponseWriter, sessionKey string, err error, message, code string) {
+	if h.respondSessionError(w, sessionKey, err) {
+		return
+	}
+	for _, ge := range groupErrors {
+		if errors.Is(err, ge.err) {
+			h.logger.Warnf("[%s] %s: %v", sessionKey, message, err)
+			h.errorJSON(w, ge.status, ge.message, ge.code, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	h.logger.Errorf("[%s] %s: %v", sessionKey, message, err)
+	h.errorJSON(w, http.StatusInternalServerError, message, code, map[string]string{"error": err.Error()})
+}
+
+func (h *GroupHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	groups, err := h.service.ListGroups(sessionKey, tenantID)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao listar grupos", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Grupos listados com sucesso", map[string]interface{}{
+		"total":  len(groups),
+		"groups": groups,
+	})
+}
+
+func (h *GroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	group, err := h.service.GetGroup(sessionKey, tenantID, h.pathVar(r, "groupJid"))
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao obter grupo", "GROUP_FETCH_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Grupo obtido com sucesso", group)
+}
+
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.CreateGroupRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	group, err := h.service.CreateGroup(sessionKey, tenantID, &req)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao criar grupo", "GROUP_CREATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusCreated, "Grupo criado com sucesso", group)
+}
+
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.UpdateGroupRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+	// Só a descrição pode ser apagada; o grupo sempre tem nome.
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		h.errorJSON(w, http.StatusBadRequest, "Campos inválidos", "VALIDATION_ERROR", map[string]string{"name": "obrigatório"})
+		return
+	}
+
+	group, err := h.service.UpdateGroup(sessionKey, tenantID, h.pathVar(r, "groupJid"), &req)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao atualizar grupo", "GROUP_UPDATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Grupo atualizado com sucesso", group)
+}
+
+func (h *GroupHandler) UpdateParticipants(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.UpdateGroupParticipantsRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	participants, err := h.service.UpdateGroupParticipants(sessionKey, tenantID, h.pathVar(r, "groupJid"), &req)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao atualizar participantes", "PARTICIPANTS_UPDATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Participantes atualizados", map[string]interface{}{
+		"action":       req.Action,
+		"participants": participants,
+	})
+}
+
+func (h *GroupHandler) SetPicture(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.GroupPictureRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	pictureID, err := h.service.SetGroupPicture(sessionKey, tenantID, h.pathVar(r, "groupJid"), &req)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao alterar foto do grupo", "GROUP_PICTURE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Foto do grupo alterada", map[string]string{"picture_id": pictureID})
+}
+
+func (h *GroupHandler) RemovePicture(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	if _, err := h.service.SetGroupPicture(sessionKey, tenantID, h.pathVar(r, "groupJid"), nil); err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao remover foto do grupo", "GROUP_PICTURE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Foto do grupo removida", nil)
+}
+
+func (h *GroupHandler) GetInviteLink(w http.ResponseWriter, r *http.Request) {
+	h.inviteLink(w, r, false)
+}
+
+func (h *GroupHandler) RevokeInviteLink(w http.ResponseWriter, r *http.Request) {
+	h.inviteLink(w, r, true)
+}
+
+func (h *GroupHandler) inviteLink(w http.ResponseWriter, r *http.Request, reset bool) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	link, err := h.service.GetGroupInviteLink(sessionKey, tenantID, h.pathVar(r, "groupJid"), reset)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao obter link de convite", "INVITE_LINK_FAILED")
+		return
+	}
+
+	message := "Link de convite obtido com sucesso"
+	if reset {
+		message = "Link de convite revogado; novo link gerado"
+	}
+	h.successJSON(w, http.StatusOK, message, link)
+}
+
+func (h *GroupHandler) JoinGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.JoinGroupRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	groupJID, err := h.service.JoinGroup(sessionKey, tenantID, req.InviteLink)
+	if err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao entrar no grupo", "GROUP_JOIN_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Entrada no grupo solicitada", map[string]string{"group_jid": groupJID})
+}
+
+func (h *GroupHandler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	groupJID := h.pathVar(r, "groupJid")
+	if err := h.service.LeaveGroup(sessionKey, tenantID, groupJID); err != nil {
+		h.groupError(w, sessionKey, err, "Falha ao sair do grupo", "GROUP_LEAVE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Sessão saiu do grupo", map[string]string{"group_jid": groupJID})
+}
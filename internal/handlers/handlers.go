@@ -35,16 +35,13 @@ func (h *Handler) SendTextMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Number == "" || req.Text == "" {
-		h.logger.Warn("Campos obrigatórios ausentes na requisição de mensagem de texto")
+	if errs := validator.Struct(&req); errs != nil {
+		h.logger.Warnf("Campos inválidos na requisição de mensagem de texto: %v", errs)
 		w.WriteHeader(http.StatusBadRequest)
 		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Campos obrigatórios ausentes",
+			"Campos inválidos",
 			"VALIDATION_ERROR",
-			map[string]string{
-				"number": "obrigatório",
-				"text":   "obrigatório",
-			},
+			errs,
 		))
 		if err != nil {
 			return
@@ -115,44 +112,13 @@ func (h *Handler) SendMediaMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Number == "" {
-		h.logger.Warn("Número ausente na requisição de mensagem de mídia")
+	if errs := validator.Struct(&req); errs != nil {
+		h.logger.Warnf("Campos inválidos na requisição de mensagem de mídia: %v", errs)
 		w.WriteHeader(http.StatusBadRequest)
 		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Campo obrigatório ausente: número",
+			"Campos inválidos",
 			"VALIDATION_ERROR",
-			map[string]string{"number": "obrigatório"},
-		))
-		if err != nil {
-			return
-		}
-		return
-	}
-
-	if req.MediaURL == "" && req.MediaBase64 == "" {
-		h.logger.Warn("Fonte de mídia ausente na requisição de mensagem de mídia")
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"É necessário fornecer media_url ou media_base64",
-			"VALIDATION_ERROR",
-			map[string]string{
-				"media_url":    "obrigatório_sem media_base64",
-				"media_base64": "obrigatório_sem media_url",
-			},
-		))
-		if err != nil {
-			return
-		}
-		return
-	}
-
-	if req.MediaBase64 != "" && req.MimeType == "" {
-		h.logger.Warn("mime_type ausente para mídia base64")
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"mime_type é obrigatório ao usar media_base64",
-			"VALIDATION_ERROR",
-			map[string]string{"mime_type": "obrigatório com media_base64"},
+			errs,
 		))
 		if err != nil {
 			return
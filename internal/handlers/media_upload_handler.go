@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/media"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxFormFieldSize limita cada campo de texto do multipart; o maior
+	// deles é thumbnail_base64.
+	maxFormFieldSize = 1 << 20
+	// uploadTimeout substitui SERVER_READ_TIMEOUT/SERVER_WRITE_TIMEOUT no
+	// upload multipart, que lê arquivos grandes e ainda espera o envio.
+	uploadTimeout = 10 * time.Minute
+)
+
+func isMultipart(r *http.Request) bool {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return ct == "multipart/form-data"
+}
+
+// sendMediaMultipart trata POST /messages/media com multipart/form-data. A
+// parte "file" vai direto para um arquivo temporário, sem passar por
+// base64 nem ficar inteira em memória; os demais campos espelham o JSON.
+func (h *MultiTenantHandler) sendMediaMultipart(w http.ResponseWriter, r *http.Request, sessionKey, tenantID string) {
+	req, file, partType, ok := h.readMultipartMedia(w, r, sessionKey)
+	if !ok {
+		return
+	}
+	// A partir daqui o arquivo pertence ao serviço, exceto nas recusas
+	// abaixo.
+	reject := func(message string, details map[string]string) {
+		file.Close()
+		h.errorJSON(w, http.StatusBadRequest, message, "VALIDATION_ERROR", details)
+	}
+
+	switch {
+	case file == nil:
+		reject("Campo obrigatório ausente: file", map[string]string{"file": "obrigatório"})
+		return
+	case req.Async:
+		reject("Upload multipart não aceita envio assíncrono", map[string]string{"async": "use media_url para enviar pela fila"})
+		return
+	case req.SendAt != nil:
+		reject("Upload multipart não aceita envio agendado", map[string]string{"send_at": "faça o pré-upload em POST /media e agende com media_id"})
+		return
+	}
+
+	req.MimeType = uploadContentType(req.MimeType, partType, file)
+	if !h.validateRequest(w, req) || !h.validateRecipient(w, sessionKey, req.Number, req.Mentions) {
+		file.Close()
+		return
+	}
+
+	messageSent, err := h.whatsappService.SendMediaFile(sessionKey, tenantID, req, file)
+	if h.respondSessionError(w, sessionKey, err) || h.respondRateLimited(w, sessionKey, err) || h.respondRecipientError(w, sessionKey, err) || h.respondMediaError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("[%s] Falha ao enviar mensagem de mídia para %s: %v", sessionKey, req.Number, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao enviar mensagem de mídia", "SEND_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+	h.logger.Infof("[%s] Mensagem de mídia (upload) enviada com sucesso para %s", sessionKey, req.Number)
+	h.successJSON(w, http.StatusOK, "Mensagem com mídia enviada com sucesso", messageSent)
+}
+
+// readMultipartMedia lê o corpo multipart com prazo e limite próprios de
+// upload e responde os erros de leitura. Com ok, quem chama é dono do
+// arquivo (que pode ser nil se a parte "file" não veio).
+func (h *MultiTenantHandler) readMultipartMedia(w http.ResponseWriter, r *http.Request, sessionKey string) (*models.MediaRequest, *media.File, string, bool) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(uploadTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(uploadTimeout))
+
+	limit := h.config.Server.MaxUploadSize
+	r.Body = http.MaxBytesReader(w, r.Body, limit+8*maxFormFieldSize)
+
+	req, file, partType, err := h.readMediaForm(r, limit)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, media.ErrTooLarge), errors.As(err, &tooLarge):
+			h.errorJSON(w, http.StatusRequestEntityTooLarge, "Arquivo excede o tamanho máximo", "MEDIA_TOO_LARGE",
+				map[string]string{"max_bytes": strconv.FormatInt(limit, 10)})
+		default:
+			h.logger.Warnf("[%s] Multipart inválido na requisição de mídia: %v", sessionKey, err)
+			h.errorJSON(w, http.StatusBadRequest, "Corpo multipart inválido", "INVALID_MULTIPART", map[string]string{"error": err.Error()})
+		}
+		return nil, nil, "", false
+	}
+	return req, file, partType, true
+}
+
+// uploadContentType escolhe o tipo do arquivo enviado: mime_type, depois o
+// cabeçalho da parte e, na falta de ambos, o conteúdo.
+func uploadContentType(mimeType, partType string, file *media.File) string {
+	if mimeType == "" {
+		mimeType = partType
+	}
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = file.DetectContentType()
+	}
+	return mimeType
+}
+
+// readMediaForm percorre as partes na ordem em que chegam. Campos podem vir
+// antes ou depois do arquivo.
+func (h *MultiTenantHandler) readMediaForm(r *http.Request, limit int64) (*models.MediaRequest, *media.File, string, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	req := &models.MediaRequest{}
+	var file *media.File
+	var partType string
+	fail := func(err error) (*models.MediaRequest, *media.File, string, error) {
+		file.Close()
+		return nil, nil, "", err
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		name := part.FormName()
+		if name == "file" {
+			if file != nil {
+				return fail(fmt.Errorf("envie apenas um arquivo por mensagem"))
+			}
+			if file, err = media.Spool(part, limit); err != nil {
+				return fail(err)
+			}
+			req.FromFile = true
+			if req.FileName == "" {
+				req.FileName = part.FileName()
+			}
+			partType, _, _ = mime.ParseMediaType(part.Header.Get("Content-Type"))
+			continue
+		}
+
+		raw, err := io.ReadAll(io.LimitReader(part, maxFormFieldSize+1))
+		if err != nil {
+			return fail(err)
+		}
+		if len(raw) > maxFormFieldSize {
+			return fail(fmt.Errorf("campo %s excede %d bytes", name, maxFormFieldSize))
+		}
+		value := strings.TrimSpace(string(raw))
+
+		switch name {
+		case "number":
+			req.Number = value
+		case "caption":
+			req.Caption = value
+		case "filename":
+			req.FileName = value
+		case "mime_type":
+			req.MimeType = value
+		case "mode":
+			req.Mode = value
+		case "quoted_message_id":
+			req.QuotedMessageID = value
+		case "thumbnail_base64":
+			req.ThumbnailBase64 = value
+		case "mentions":
+			// Aceita o campo repetido ou uma lista separada por vírgulas.
+			for _, m := range strings.Split(value, ",") {
+				if m = strings.TrimSpace(m); m != "" {
+					req.Mentions = append(req.Mentions, m)
+				}
+			}
+		case "async":
+			req.Async, _ = strconv.ParseBool(value)
+		case "send_at":
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return fail(fmt.Errorf("send_at inválido, use RFC3339 com fuso: %w", err))
+			}
+			req.SendAt = &t
+		default:
+			return fail(fmt.Errorf("campo desconhecido: %s", name))
+		}
+	}
+	return req, file, partType, nil
+}
+
+// UploadMedia trata POST /media: envia a mídia aos servidores do WhatsApp
+// pela sessão informada e devolve o media_id para envios posteriores, em
+// qualquer sessão do tenant. Aceita JSON (media_url ou media_base64) ou
+// multipart com a parte "file".
+func (h *MultiTenantHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	sessionKey := r.Header.Get("X-WhatsApp-Session-Key")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "Header X-WhatsApp-Session-Key é obrigatório", "MISSING_SESSION_KEY", nil)
+		return
+	}
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.MediaUploadRequest
+	var file *media.File
+
+	if isMultipart(r) {
+		form, f, partType, ok := h.readMultipartMedia(w, r, sessionKey)
+		if !ok {
+			return
+		}
+		file = f
+		details := map[string]string{}
+		switch {
+		case file == nil:
+			details["file"] = "obrigatório"
+		case form.Number != "" || form.Caption != "" || len(form.Mentions) > 0 || form.QuotedMessageID != "" || form.Async || form.SendAt != nil:
+			details["file"] = "o pré-upload aceita só file, filename, mime_type, mode e thumbnail_base64"
+		}
+		if len(details) > 0 {
+			file.Close()
+			h.errorJSON(w, http.StatusBadRequest, "Upload de mídia inválido", "VALIDATION_ERROR", details)
+			return
+		}
+		req = models.MediaUploadRequest{
+			MimeType:        uploadContentType(form.MimeType, partType, file),
+			FileName:        form.FileName,
+			Mode:            form.Mode,
+			ThumbnailBase64: form.ThumbnailBase64,
+			FromFile:        true,
+		}
+	} else if err := validator.ValidateJSON(r, &req); err != nil {
+		h.logger.Warnf("[%s] JSON inválido no upload de mídia: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !h.validateRequest(w, &req) {
+		file.Close()
+		return
+	}
+
+	upload, err := h.whatsappService.UploadMedia(sessionKey, tenantID, &req, file)
+	if h.respondSessionError(w, sessionKey, err) || h.respondMediaError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("[%s] Falha no upload de mídia: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha no upload de mídia", "UPLOAD_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+	h.logger.Infof("[%s] Mídia %s disponível até %s", sessionKey, upload.ID, upload.ExpiresAt.Format(time.RFC3339))
+	h.successJSON(w, http.StatusCreated, "Mídia enviada ao WhatsApp", upload)
+}
+
+// GetMedia trata GET /media/{mediaId}.
+func (h *MultiTenantHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	upload, err := h.whatsappService.GetMedia(tenantID, h.pathVar(r, "mediaId"))
+	if errors.Is(err, services.ErrMediaNotFound) {
+		h.errorJSON(w, http.StatusNotFound, "media_id não encontrado", "MEDIA_NOT_FOUND", nil)
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("Falha ao consultar mídia: %v", err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao consultar mídia", "INTERNAL_ERROR", nil)
+		return
+	}
+	h.successJSON(w, http.StatusOK, "Mídia encontrada", upload)
+}
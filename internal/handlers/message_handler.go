@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MessageHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+}
+
+func NewMessageHandler(service *services.MultiTenantWhatsAppService, log *logger.Logger) *MessageHandler {
+	return &MessageHandler{baseHandler: baseHandler{logger: log}, service: service}
+}
+
+func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	messageID := h.pathVar(r, "messageId")
+
+	msg, err := h.service.GetMessage(tenantID, messageID)
+	if err != nil {
+		if errors.Is(err, services.ErrMessageNotFound) {
+			h.errorJSON(w, http.StatusNotFound, "Mensagem não encontrada", "MESSAGE_NOT_FOUND", map[string]string{"message_id": messageID})
+			return
+		}
+		h.logger.Errorf("Falha ao buscar mensagem %s: %v", messageID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao buscar mensagem", "MESSAGE_FETCH_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Mensagem obtida com sucesso", msg)
+}
+
+func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.MessageFilter{
+		TenantID:     tenantID,
+		RecipientJID: q.Get("recipient"),
+		Status:       q.Get("status"),
+		Type:         q.Get("type"),
+		Direction:    q.Get("direction"),
+		Limit:        50,
+	}
+	details := map[string]string{}
+
+	switch filter.Status {
+	case "", models.MessageStatusSent, models.MessageStatusDelivered, models.MessageStatusRead, models.MessageStatusPlayed:
+	default:
+		details["status"] = "use sent, delivered, read ou played"
+	}
+	switch filter.Direction {
+	case "", models.MessageDirectionOutbound, models.MessageDirectionInbound:
+	default:
+		details["direction"] = "use outbound ou inbound"
+	}
+	if raw := q.Get("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n <= 0 || n > 500 {
+			details["limit"] = "entre 1 e 500"
+		} else {
+			filter.Limit = n
+		}
+	}
+	if raw := q.Get("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			details["offset"] = "deve ser maior ou igual a zero"
+		} else {
+			filter.Offset = n
+		}
+	}
+	for key, dst := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := q.Get(key); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				details[key] = "use o formato RFC3339"
+				continue
+			}
+			*dst = &t
+		}
+	}
+	if len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Filtros inválidos", "VALIDATION_ERROR", details)
+		return
+	}
+
+	sessionKey := q.Get("session_key")
+	messages, err := h.service.ListMessages(sessionKey, filter)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		h.logger.Errorf("Falha ao listar mensagens do tenant %s: %v", tenantID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao listar mensagens", "LIST_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Mensagens listadas com sucesso", map[string]interface{}{
+		"total":    len(messages),
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+		"messages": messages,
+	})
+}
+
+func (h *MessageHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	jobID, err := uuid.Parse(h.pathVar(r, "jobId"))
+	if err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "ID de job inválido", "VALIDATION_ERROR", map[string]string{"jobId": "deve ser um UUID"})
+		return
+	}
+
+	job, err := h.service.GetOutboundJob(tenantID, jobID)
+	if err != nil {
+		if errors.Is(err, services.ErrOutboundJobNotFound) {
+			h.errorJSON(w, http.StatusNotFound, "Job de envio não encontrado", "JOB_NOT_FOUND", map[string]string{"job_id": jobID.String()})
+			return
+		}
+		h.logger.Errorf("Falha ao buscar job de envio %s: %v", jobID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao buscar job de envio", "JOB_FETCH_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Job de envio obtido com sucesso", job)
+}
+
+var messageActionErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{services.ErrMessageNotFound, http.StatusNotFound, "MESSAGE_NOT_FOUND", "Mensagem não encontrada para esta sessão"},
+	{services.ErrMessageRevoked, http.StatusGone, "MESSAGE_REVOKED", "A mensagem foi apagada"},
+	{services.ErrMessageNotEditable, http.StatusUnprocessableEntity, "MESSAGE_NOT_EDITABLE", "Apenas mensagens de texto enviadas pela sessão podem ser editadas"},
+	{services.ErrEditWindowExpired, http.StatusUnprocessableEntity, "EDIT_WINDOW_EXPIRED", "Prazo de edição da mensagem expirou"},
+	{services.ErrMessageNotRevocable, http.StatusUnprocessableEntity, "MESSAGE_NOT_REVOCABLE", "Mensagens recebidas só podem ser apagadas em grupos"},
+}
+
+func (h *MessageHandler) actionError(w http.ResponseWriter, sessionKey string, err error, message string) {
+	if h.respondSessionError(w, sessionKey, err) {
+		return
+	}
+	for _, me := range messageActionErrors {
+		if errors.Is(err, me.err) {
+			h.errorJSON(w, me.status, me.message, me.code, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	for _, ge := range groupErrors {
+		if errors.Is(err, ge.err) {
+			h.logger.Warnf("[%s] %s: %v", sessionKey, message, err)
+			h.errorJSON(w, ge.status, ge.message, ge.code, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	h.logger.Errorf("[%s] %s: %v", sessionKey, message, err)
+	h.errorJSON(w, http.StatusInternalServerError, message, "SEND_FAILED", map[string]string{"error": err.Error()})
+}
+
+// actionTarget extrai tenant, sessão (header X-WhatsApp-Session-Key, como nos
+// envios) e o ID da mensagem alvo.
+func (h *MessageHandler) actionTarget(w http.ResponseWriter, r *http.Request) (string, string, string, bool) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return "", "", "", false
+	}
+	sessionKey := r.Header.Get("X-WhatsApp-Session-Key")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "Header X-WhatsApp-Session-Key é obrigatório", "MISSING_SESSION_KEY", nil)
+		return "", "", "", false
+	}
+	return tenantID, sessionKey, h.pathVar(r, "messageId"), true
+}
+
+func (h *MessageHandler) ReactToMessage(w http.ResponseWriter, r *http.Request) {
+	tenantID, sessionKey, messageID, ok := h.actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	result, err := h.service.ReactToMessage(sessionKey, tenantID, messageID, req.Emoji)
+	if err != nil {
+		h.actionError(w, sessionKey, err, "Falha ao enviar reação")
+		return
+	}
+
+	message := "Reação enviada com sucesso"
+	if req.Emoji == "" {
+		message = "Reação removida com sucesso"
+	}
+	h.successJSON(w, http.StatusOK, message, result)
+}
+
+func (h *MessageHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	tenantID, sessionKey, messageID, ok := h.actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ReactToMessage(sessionKey, tenantID, messageID, "")
+	if err != nil {
+		h.actionError(w, sessionKey, err, "Falha ao remover reação")
+		return
+	}
+	h.successJSON(w, http.StatusOK, "Reação removida com sucesso", result)
+}
+
+func (h *MessageHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	tenantID, sessionKey, messageID, ok := h.actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.EditMessageRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	result, err := h.service.EditMessage(sessionKey, tenantID, messageID, req.Text)
+	if err != nil {
+		h.actionError(w, sessionKey, err, "Falha ao editar mensagem")
+		return
+	}
+	h.successJSON(w, http.StatusOK, "Mensagem editada com sucesso", result)
+}
+
+func (h *MessageHandler) RevokeMessage(w http.ResponseWriter, r *http.Request) {
+	tenantID, sessionKey, messageID, ok := h.actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.RevokeMessage(sessionKey, tenantID, messageID)
+	if err != nil {
+		h.actionError(w, sessionKey, err, "Falha ao apagar mensagem")
+		return
+	}
+	h.successJSON(w, http.StatusOK, "Mensagem apagada para todos", result)
+}
+
+func (h *MessageHandler) GetPollResults(w http.ResponseWriter, r *http.Request) {
+	tenantID, sessionKey, messageID, ok := h.actionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.GetPollResults(sessionKey, tenantID, messageID)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		if errors.Is(err, services.ErrPollNotFound) {
+			h.errorJSON(w, http.StatusNotFound, "Enquete não encontrada para esta sessão", "POLL_NOT_FOUND", map[string]string{"message_id": messageID})
+			return
+		}
+		h.logger.Errorf("[%s] Falha ao apurar enquete %s: %v", sessionKey, messageID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao apurar enquete", "POLL_FETCH_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Resultado da enquete obtido com sucesso", results)
+}
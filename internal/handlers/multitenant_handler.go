@@ -7,27 +7,195 @@ import (
 	"boot-whatsapp-golang/pkg/logger"
 	"boot-whatsapp-golang/pkg/validator"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type MultiTenantHandler struct {
+	baseHandler
 	whatsappService *services.MultiTenantWhatsAppService
 	config          *config.Config
-	logger          *logger.Logger
 	startTime       time.Time
 }
 
 func NewMultiTenantHandler(whatsappService *services.MultiTenantWhatsAppService, cfg *config.Config, log *logger.Logger) *MultiTenantHandler {
 	return &MultiTenantHandler{
+		baseHandler:     baseHandler{logger: log},
 		whatsappService: whatsappService,
 		config:          cfg,
-		logger:          log,
 		startTime:       time.Now(),
 	}
 }
 
+// wantsAsync indica se o cliente optou pelo envio via fila, seja pelo campo
+// "async" do corpo ou pelo header padrão "Prefer: respond-async".
+func wantsAsync(r *http.Request, async bool) bool {
+	if async {
+		return true
+	}
+	for _, pref := range strings.Split(r.Header.Get("Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), "respond-async") {
+			return true
+		}
+	}
+	return false
+}
+
+// sendAtTolerance absorve a diferença de relógio entre cliente e servidor:
+// send_at até esse tanto no passado é enviado imediatamente pela fila.
+const sendAtTolerance = time.Minute
+
+// validateSendAt recusa agendamentos que já passaram.
+func validateSendAt(sendAt *time.Time) map[string]string {
+	details := map[string]string{}
+	if sendAt != nil && time.Until(*sendAt) < -sendAtTolerance {
+		details["send_at"] = "deve estar no futuro (RFC3339 com fuso)"
+	}
+	return details
+}
+
+// respondRateLimited converte um *services.RateLimitError em 429 com
+// Retry-After (segundos, arredondado para cima).
+func (h *MultiTenantHandler) respondRateLimited(w http.ResponseWriter, sessionKey string, err error) bool {
+	return h.respondLimited(w, sessionKey, err, "Limite de envio excedido")
+}
+
+func (h *MultiTenantHandler) respondLimited(w http.ResponseWriter, sessionKey string, err error, message string) bool {
+	var limited *services.RateLimitError
+	if !errors.As(err, &limited) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(limited.RetryAfter.Seconds()))
+	h.logger.Warnf("[%s] %s (%s), retry em %ds", sessionKey, message, limited.Scope, retryAfter)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	h.errorJSON(w, http.StatusTooManyRequests, message, "RATE_LIMITED", map[string]string{
+		"scope":       limited.Scope,
+		"retry_after": strconv.Itoa(retryAfter),
+	})
+	return true
+}
+
+// validateRecipient valida o destinatário conforme o tipo (número, JID,
+// ID de grupo ou link de convite) e as menções, que precisam ser usuários.
+// Números malformados mantêm o código INVALID_PHONE.
+func (h *MultiTenantHandler) validateRecipient(w http.ResponseWriter, sessionKey, number string, mentions []string) bool {
+	kind, err := validator.ValidateRecipient(number)
+	if err != nil {
+		h.logger.Warnf("[%s] Destinatário inválido %q: %v", sessionKey, number, err)
+		if err := validator.ValidatePhoneNumber(number); err != nil && !strings.ContainsAny(number, "@/") {
+			h.errorJSON(w, http.StatusBadRequest, "Formato de número de telefone inválido", "INVALID_PHONE", map[string]string{"error": err.Error()})
+			return false
+		}
+		h.errorJSON(w, http.StatusBadRequest, "Destinatário inválido", "INVALID_RECIPIENT", map[string]string{"number": err.Error()})
+		return false
+	}
+	if kind == validator.RecipientPhone {
+		if _, err := validator.NormalizePhone(number, h.config.WhatsApp.DefaultCountry); err != nil {
+			h.logger.Warnf("[%s] Número inválido %q: %v", sessionKey, number, err)
+			h.errorJSON(w, http.StatusBadRequest, "Formato de número de telefone inválido", "INVALID_PHONE", map[string]string{"error": err.Error()})
+			return false
+		}
+	}
+	for _, m := range mentions {
+		if err := validator.ValidateMention(m); err != nil {
+			h.logger.Warnf("[%s] Menção inválida %q: %v", sessionKey, m, err)
+			h.errorJSON(w, http.StatusBadRequest, "Menção inválida", "INVALID_RECIPIENT", map[string]string{"mentions": m + ": " + err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// respondRecipientError trata destinatários que só se revelam inválidos no
+// envio: links de convite revogados, grupos dos quais a sessão não participa
+// ou mensagens citadas que a sessão não conhece.
+func (h *MultiTenantHandler) respondRecipientError(w http.ResponseWriter, sessionKey string, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrInvalidRecipient):
+		h.logger.Warnf("[%s] Destinatário inválido: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "Destinatário inválido", "INVALID_RECIPIENT", map[string]string{"error": err.Error()})
+		return true
+	case errors.Is(err, services.ErrNotOnWhatsApp):
+		h.logger.Warnf("[%s] %v", sessionKey, err)
+		h.errorJSON(w, http.StatusUnprocessableEntity, "Número não tem WhatsApp", "RECIPIENT_NOT_ON_WHATSAPP", map[string]string{"error": err.Error()})
+		return true
+	case errors.Is(err, services.ErrQuotedMessageNotFound):
+		h.logger.Warnf("[%s] %v", sessionKey, err)
+		h.errorJSON(w, http.StatusNotFound, "Mensagem citada não encontrada", "QUOTED_MESSAGE_NOT_FOUND", map[string]string{"error": err.Error()})
+		return true
+	}
+	for _, ge := range groupErrors {
+		if errors.Is(err, ge.err) {
+			h.logger.Warnf("[%s] Envio recusado: %v", sessionKey, err)
+			h.errorJSON(w, ge.status, ge.message, ge.code, map[string]string{"error": err.Error()})
+			return true
+		}
+	}
+	return false
+}
+
+var mediaErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{services.ErrMediaTooLarge, http.StatusRequestEntityTooLarge, "MEDIA_TOO_LARGE", "Mídia excede o tamanho máximo"},
+	{services.ErrMediaURLBlocked, http.StatusBadRequest, "MEDIA_URL_BLOCKED", "media_url não permitida pela política de download"},
+	{services.ErrMediaTypeMismatch, http.StatusBadRequest, "MEDIA_TYPE_MISMATCH", "Conteúdo da mídia não corresponde ao tipo informado"},
+	{services.ErrMediaNotFound, http.StatusNotFound, "MEDIA_NOT_FOUND", "media_id não encontrado"},
+	{services.ErrMediaExpired, http.StatusGone, "MEDIA_EXPIRED", "media_id expirado; faça o upload novamente"},
+	{services.ErrMediaIDNewsletter, http.StatusBadRequest, "MEDIA_ID_NOT_SUPPORTED", "Canais não aceitam media_id"},
+	{services.ErrUnsupportedAudioFormat, http.StatusBadRequest, "UNSUPPORTED_AUDIO_FORMAT", "Formato de áudio não suportado para mensagem de voz"},
+	{services.ErrUnsupportedStickerFormat, http.StatusBadRequest, "UNSUPPORTED_STICKER_FORMAT", "Formato de imagem não suportado para figurinha"},
+	{services.ErrStickerDimensions, http.StatusBadRequest, "STICKER_INVALID_DIMENSIONS", "Dimensões de figurinha inválidas"},
+	{services.ErrStickerTooLarge, http.StatusBadRequest, "STICKER_TOO_LARGE", "Figurinha muito grande"},
+	{services.ErrInvalidThumbnail, http.StatusBadRequest, "INVALID_THUMBNAIL", "Miniatura inválida"},
+}
+
+// respondMediaError traduz a rejeição da mídia enviada (tamanho, formato
+// ou dimensões) em erro do cliente com código próprio.
+func (h *MultiTenantHandler) respondMediaError(w http.ResponseWriter, sessionKey string, err error) bool {
+	for _, me := range mediaErrors {
+		if errors.Is(err, me.err) {
+			h.logger.Warnf("[%s] Mídia recusada: %v", sessionKey, err)
+			h.errorJSON(w, me.status, me.message, me.code, map[string]string{"error": err.Error()})
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MultiTenantHandler) respondQueued(w http.ResponseWriter, sessionKey string, accepted *models.OutboundJobAccepted, err error) {
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrQueueStopped):
+			h.errorJSON(w, http.StatusServiceUnavailable, "Serviço em desligamento, tente novamente", "QUEUE_UNAVAILABLE", nil)
+		default:
+			h.logger.Errorf("[%s] Falha ao enfileirar envio: %v", sessionKey, err)
+			h.errorJSON(w, http.StatusInternalServerError, "Falha ao enfileirar mensagem", "ENQUEUE_FAILED", map[string]string{"error": err.Error()})
+		}
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/messages/jobs/"+accepted.JobID.String())
+	if accepted.ScheduledAt != nil {
+		h.logger.Infof("[%s] Envio %s agendado para %s", sessionKey, accepted.JobID, accepted.ScheduledAt.Format(time.RFC3339))
+		h.successJSON(w, http.StatusAccepted, "Mensagem agendada para envio", accepted)
+		return
+	}
+	h.successJSON(w, http.StatusAccepted, "Mensagem enfileirada para envio", accepted)
+}
+
 func (h *MultiTenantHandler) SendTextMessage(w http.ResponseWriter, r *http.Request) {
 	sessionKey := r.Header.Get("X-WhatsApp-Session-Key")
 	if sessionKey == "" {
@@ -44,6 +212,11 @@ func (h *MultiTenantHandler) SendTextMessage(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
 	var req models.MessageRequest
 
 	if err := validator.ValidateJSON(r, &req); err != nil {
@@ -60,38 +233,36 @@ func (h *MultiTenantHandler) SendTextMessage(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if req.Number == "" || req.Text == "" {
-		h.logger.Warnf("[%s] Campos obrigatórios ausentes na requisição de mensagem de texto", sessionKey)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Campos obrigatórios ausentes",
-			"VALIDATION_ERROR",
-			map[string]string{
-				"number": "obrigatório",
-				"text":   "obrigatório",
-			},
-		))
-		if err != nil {
-			return
-		}
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
-	if err := validator.ValidatePhoneNumber(req.Number); err != nil {
-		h.logger.Warnf("[%s] Número de telefone inválido: %v", sessionKey, err)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Formato de número de telefone inválido",
-			"INVALID_PHONE",
-			map[string]string{"error": err.Error()},
-		))
-		if err != nil {
-			return
-		}
+	if !h.validateRecipient(w, sessionKey, req.Number, req.Mentions) {
+		return
+	}
+
+	if details := validatePreview(&req); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Prévia de link inválida", "VALIDATION_ERROR", details)
+		return
+	}
+
+	if details := validateSendAt(req.SendAt); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Agendamento inválido", "VALIDATION_ERROR", details)
+		return
+	}
+
+	// Envios agendados sempre passam pela fila.
+	if req.SendAt != nil || wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueueTextMessage(sessionKey, tenantID, &req)
+		h.respondQueued(w, sessionKey, accepted, err)
 		return
 	}
 
-	if err := h.whatsappService.SendTextMessage(sessionKey, req.Number, req.Text); err != nil {
+	messageSent, err := h.whatsappService.SendTextMessage(sessionKey, tenantID, &req)
+	if h.respondSessionError(w, sessionKey, err) || h.respondRateLimited(w, sessionKey, err) || h.respondRecipientError(w, sessionKey, err) || h.respondMediaError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
 		h.logger.Errorf("[%s] Falha ao enviar mensagem de texto para %s: %v", sessionKey, req.Number, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
@@ -105,14 +276,8 @@ func (h *MultiTenantHandler) SendTextMessage(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	messageSent := models.MessageSent{
-		Recipient: req.Number,
-		Type:      "text",
-		SentAt:    time.Now(),
-	}
-
 	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(models.NewSuccessResponse(
+	err = json.NewEncoder(w).Encode(models.NewSuccessResponse(
 		"Mensagem enviada com sucesso",
 		messageSent,
 	))
@@ -137,6 +302,16 @@ func (h *MultiTenantHandler) SendMediaMessage(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	if isMultipart(r) {
+		h.sendMediaMultipart(w, r, sessionKey, tenantID)
+		return
+	}
+
 	var req models.MediaRequest
 
 	if err := validator.ValidateJSON(r, &req); err != nil {
@@ -153,66 +328,37 @@ func (h *MultiTenantHandler) SendMediaMessage(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if req.Number == "" {
-		h.logger.Warnf("[%s] Número ausente na requisição de mensagem de mídia", sessionKey)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Campo obrigatório ausente: número",
-			"VALIDATION_ERROR",
-			map[string]string{"number": "obrigatório"},
-		))
-		if err != nil {
-			return
-		}
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
-	if req.MediaURL == "" && req.MediaBase64 == "" {
-		h.logger.Warnf("[%s] Fonte de mídia ausente na requisição de mensagem de mídia", sessionKey)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"É necessário fornecer media_url ou media_base64",
-			"VALIDATION_ERROR",
-			map[string]string{
-				"media_url":    "obrigatório_sem media_base64",
-				"media_base64": "obrigatório_sem media_url",
-			},
-		))
-		if err != nil {
-			return
-		}
+	if !h.validateRecipient(w, sessionKey, req.Number, req.Mentions) {
 		return
 	}
 
-	if req.MediaBase64 != "" && req.MimeType == "" {
-		h.logger.Warnf("[%s] mime_type ausente para mídia base64", sessionKey)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"mime_type é obrigatório ao usar media_base64",
-			"VALIDATION_ERROR",
-			map[string]string{"mime_type": "obrigatório com media_base64"},
-		))
-		if err != nil {
-			return
-		}
+	if details := validateSendAt(req.SendAt); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Agendamento inválido", "VALIDATION_ERROR", details)
 		return
 	}
 
-	if err := validator.ValidatePhoneNumber(req.Number); err != nil {
-		h.logger.Warnf("[%s] Número de telefone inválido: %v", sessionKey, err)
-		w.WriteHeader(http.StatusBadRequest)
-		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-			"Formato de número de telefone inválido",
-			"INVALID_PHONE",
-			map[string]string{"error": err.Error()},
-		))
-		if err != nil {
+	// Envios agendados sempre passam pela fila.
+	if req.SendAt != nil || wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueueMediaMessage(sessionKey, tenantID, &req)
+		if errors.Is(err, services.ErrQueuedMediaBase64) {
+			h.errorJSON(w, http.StatusBadRequest, "Campos inválidos", "VALIDATION_ERROR", map[string]string{
+				"media_base64": "faça o pré-upload em POST /media e envie pela fila com media_id",
+			})
 			return
 		}
+		h.respondQueued(w, sessionKey, accepted, err)
 		return
 	}
 
-	if err := h.whatsappService.SendMediaMessage(sessionKey, req.Number, req.Caption, req.MediaURL, req.MediaBase64, req.MimeType); err != nil {
+	messageSent, err := h.whatsappService.SendMediaMessage(sessionKey, tenantID, &req)
+	if h.respondSessionError(w, sessionKey, err) || h.respondRateLimited(w, sessionKey, err) || h.respondRecipientError(w, sessionKey, err) || h.respondMediaError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
 		h.logger.Errorf("[%s] Falha ao enviar mensagem de mídia para %s: %v", sessionKey, req.Number, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		err := json.NewEncoder(w).Encode(models.NewErrorResponse(
@@ -226,14 +372,8 @@ func (h *MultiTenantHandler) SendMediaMessage(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	messageSent := models.MessageSent{
-		Recipient: req.Number,
-		Type:      "media",
-		SentAt:    time.Now(),
-	}
-
 	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(models.NewSuccessResponse(
+	err = json.NewEncoder(w).Encode(models.NewSuccessResponse(
 		"Mensagem de mídia enviada com sucesso",
 		messageSent,
 	))
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/validator"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var contactPhoneRegex = regexp.MustCompile(`^\+?[0-9 ()-]{8,20}$`)
+
+// decodeSendRequest faz a parte comum dos envios tipados: header da sessão,
+// tenant, corpo JSON, tags validate e destinatário.
+func (h *MultiTenantHandler) decodeSendRequest(w http.ResponseWriter, r *http.Request, req any, number func() string) (string, string, bool) {
+	sessionKey := r.Header.Get("X-WhatsApp-Session-Key")
+	if sessionKey == "" {
+		h.logger.Warn("whatsappSessionKey ausente no header")
+		h.errorJSON(w, http.StatusBadRequest, "Header X-WhatsApp-Session-Key é obrigatório", "MISSING_SESSION_KEY", nil)
+		return "", "", false
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return "", "", false
+	}
+
+	if err := validator.ValidateJSON(r, req); err != nil {
+		h.logger.Warnf("[%s] JSON inválido na requisição de envio: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return "", "", false
+	}
+
+	if !h.validateRequest(w, req) {
+		return "", "", false
+	}
+	if !h.validateRecipient(w, sessionKey, number(), nil) {
+		return "", "", false
+	}
+	return sessionKey, tenantID, true
+}
+
+// respondSent finaliza os envios síncronos com o mesmo mapeamento de erros
+// de /messages/text e /messages/media.
+func (h *MultiTenantHandler) respondSent(w http.ResponseWriter, sessionKey, recipient string, sent *models.MessageSent, err error, message string) {
+	if h.respondSessionError(w, sessionKey, err) || h.respondRateLimited(w, sessionKey, err) || h.respondRecipientError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("[%s] Falha ao enviar mensagem para %s: %v", sessionKey, recipient, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao enviar mensagem", "SEND_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+	h.successJSON(w, http.StatusOK, message, sent)
+}
+
+func (h *MultiTenantHandler) SendLocationMessage(w http.ResponseWriter, r *http.Request) {
+	var req models.LocationRequest
+	sessionKey, tenantID, ok := h.decodeSendRequest(w, r, &req, func() string { return req.Number })
+	if !ok {
+		return
+	}
+
+	if wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueueLocationMessage(sessionKey, tenantID, &req)
+		h.respondQueued(w, sessionKey, accepted, err)
+		return
+	}
+
+	sent, err := h.whatsappService.SendLocationMessage(sessionKey, tenantID, &req)
+	h.respondSent(w, sessionKey, req.Number, sent, err, "Localização enviada com sucesso")
+}
+
+func (h *MultiTenantHandler) SendContactsMessage(w http.ResponseWriter, r *http.Request) {
+	var req models.ContactsRequest
+	sessionKey, tenantID, ok := h.decodeSendRequest(w, r, &req, func() string { return req.Number })
+	if !ok {
+		return
+	}
+
+	if details := validateContacts(req.Contacts); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Contatos inválidos", "VALIDATION_ERROR", details)
+		return
+	}
+
+	if wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueueContactsMessage(sessionKey, tenantID, &req)
+		h.respondQueued(w, sessionKey, accepted, err)
+		return
+	}
+
+	sent, err := h.whatsappService.SendContactsMessage(sessionKey, tenantID, &req)
+	h.respondSent(w, sessionKey, req.Number, sent, err, "Contato(s) enviado(s) com sucesso")
+}
+
+// validateContacts confere o que as tags não expressam: o formato livre dos
+// telefones do vCard e o tipo, que aceita minúsculas.
+func validateContacts(contacts []models.ContactCard) map[string]string {
+	details := map[string]string{}
+	for i, c := range contacts {
+		for j, p := range c.Phones {
+			field := fmt.Sprintf("contacts[%d].phones[%d]", i, j)
+			if !contactPhoneRegex.MatchString(p.Number) {
+				details[field+".number"] = "formato de telefone inválido"
+			}
+			switch strings.ToUpper(p.Type) {
+			case "", "CELL", "WORK", "HOME", "MAIN":
+			default:
+				details[field+".type"] = "use CELL, WORK, HOME ou MAIN"
+			}
+		}
+	}
+	return details
+}
+
+func (h *MultiTenantHandler) SendPollMessage(w http.ResponseWriter, r *http.Request) {
+	var req models.PollRequest
+	sessionKey, tenantID, ok := h.decodeSendRequest(w, r, &req, func() string { return req.Number })
+	if !ok {
+		return
+	}
+
+	if details := validatePoll(&req); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Enquete inválida", "VALIDATION_ERROR", details)
+		return
+	}
+
+	if wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueuePollMessage(sessionKey, tenantID, &req)
+		h.respondQueued(w, sessionKey, accepted, err)
+		return
+	}
+
+	sent, err := h.whatsappService.SendPollMessage(sessionKey, tenantID, &req)
+	h.respondSent(w, sessionKey, req.Number, sent, err, "Enquete enviada com sucesso")
+}
+
+// validatePoll confere as regras entre campos da enquete; tamanhos e
+// obrigatoriedade ficam nas tags de models.PollRequest.
+func validatePoll(req *models.PollRequest) map[string]string {
+	details := map[string]string{}
+	// Os votos identificam a opção pelo SHA-256 do texto, então opções
+	// repetidas seriam indistinguíveis na apuração.
+	seen := make(map[string]bool, len(req.Options))
+	for i, o := range req.Options {
+		if seen[o] {
+			details[fmt.Sprintf("options[%d]", i)] = "opção repetida"
+		}
+		seen[o] = true
+	}
+
+	if req.SelectableCount != nil && (*req.SelectableCount < 0 || *req.SelectableCount > len(req.Options)) {
+		details["selectable_count"] = "entre 0 (qualquer quantidade) e o número de opções"
+	}
+	return details
+}
+
+func validatePreview(req *models.MessageRequest) map[string]string {
+	details := map[string]string{}
+	p := req.Preview
+	if p == nil {
+		return details
+	}
+	if req.LinkPreview != nil && !*req.LinkPreview {
+		details["preview"] = "não combina com link_preview false"
+	}
+	if p.URL == "" && validator.FirstURL(req.Text) == "" {
+		details["preview.url"] = "obrigatório quando o texto não contém link"
+	}
+	return details
+}
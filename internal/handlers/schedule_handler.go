@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// respondScheduleError trata os erros comuns ao cancelamento e à remarcação
+// de envios agendados.
+func (h *SessionHandler) respondScheduleError(w http.ResponseWriter, sessionKey string, jobID uuid.UUID, job *models.OutboundJob, err error) {
+	if h.respondSessionError(w, sessionKey, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrOutboundJobNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Envio agendado não encontrado", "SCHEDULE_NOT_FOUND", map[string]string{"job_id": jobID.String()})
+	case errors.Is(err, services.ErrScheduleNotPending):
+		h.errorJSON(w, http.StatusConflict, "Envio agendado não está mais pendente", "SCHEDULE_NOT_PENDING", map[string]string{
+			"job_id": jobID.String(),
+			"status": job.Status,
+		})
+	default:
+		h.logger.Errorf("[%s] Falha ao alterar envio agendado %s: %v", sessionKey, jobID, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao alterar envio agendado", "SCHEDULE_UPDATE_FAILED", map[string]string{"error": err.Error()})
+	}
+}
+
+func (h *SessionHandler) scheduledJobID(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	sessionKey := h.pathVar(r, "sessionKey")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "sessionKey é obrigatório", "VALIDATION_ERROR", nil)
+		return "", uuid.Nil, false
+	}
+	jobID, err := uuid.Parse(h.pathVar(r, "jobId"))
+	if err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "ID de job inválido", "VALIDATION_ERROR", map[string]string{"jobId": "deve ser um UUID"})
+		return "", uuid.Nil, false
+	}
+	return sessionKey, jobID, true
+}
+
+func (h *SessionHandler) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	sessionKey := h.pathVar(r, "sessionKey")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "sessionKey é obrigatório", "VALIDATION_ERROR", nil)
+		return
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.ScheduledFilter{Status: q.Get("status"), Limit: 50}
+	details := map[string]string{}
+
+	switch filter.Status {
+	case "", models.OutboundJobQueued, models.OutboundJobProcessing, models.OutboundJobSent, models.OutboundJobFailed, models.OutboundJobCanceled:
+	default:
+		details["status"] = "use queued, processing, sent, failed ou canceled"
+	}
+	if raw := q.Get("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n <= 0 || n > 500 {
+			details["limit"] = "entre 1 e 500"
+		} else {
+			filter.Limit = n
+		}
+	}
+	if raw := q.Get("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			details["offset"] = "deve ser maior ou igual a zero"
+		} else {
+			filter.Offset = n
+		}
+	}
+	for key, dst := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := q.Get(key); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				details[key] = "use o formato RFC3339"
+				continue
+			}
+			*dst = &t
+		}
+	}
+	if len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Filtros inválidos", "VALIDATION_ERROR", details)
+		return
+	}
+
+	jobs, err := h.service.ListScheduled(sessionKey, tenantID, filter)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		h.logger.Errorf("Falha ao listar envios agendados da sessão %s: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao listar envios agendados", "LIST_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Envios agendados listados com sucesso", map[string]interface{}{
+		"total":     len(jobs),
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+		"scheduled": jobs,
+	})
+}
+
+func (h *SessionHandler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	sessionKey, jobID, ok := h.scheduledJobID(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.CancelScheduled(sessionKey, tenantID, jobID)
+	if err != nil {
+		h.respondScheduleError(w, sessionKey, jobID, job, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Envio agendado cancelado com sucesso", job)
+}
+
+func (h *SessionHandler) RescheduleMessage(w http.ResponseWriter, r *http.Request) {
+	sessionKey, jobID, ok := h.scheduledJobID(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RescheduleRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+	if details := validateSendAt(req.SendAt); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Agendamento inválido", "VALIDATION_ERROR", details)
+		return
+	}
+
+	job, err := h.service.RescheduleMessage(sessionKey, tenantID, jobID, *req.SendAt)
+	if err != nil {
+		h.respondScheduleError(w, sessionKey, jobID, job, err)
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Envio agendado remarcado com sucesso", job)
+}
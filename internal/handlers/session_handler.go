@@ -1,57 +1,21 @@
 package handlers
 
 import (
-	"boot-whatsapp-golang/internal/middleware"
 	"boot-whatsapp-golang/internal/models"
 	"boot-whatsapp-golang/internal/services"
 	"boot-whatsapp-golang/pkg/logger"
 	"boot-whatsapp-golang/pkg/validator"
-	"encoding/json"
+	"errors"
 	"net/http"
-
-	"github.com/gorilla/mux"
 )
 
 type SessionHandler struct {
+	baseHandler
 	service *services.MultiTenantWhatsAppService
-	logger  *logger.Logger
 }
 
 func NewSessionHandler(service *services.MultiTenantWhatsAppService, log *logger.Logger) *SessionHandler {
-	return &SessionHandler{service: service, logger: log}
-}
-
-func (h *SessionHandler) writeJSON(w http.ResponseWriter, status int, payload any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(payload)
-}
-
-func (h *SessionHandler) errorJSON(
-	w http.ResponseWriter,
-	status int,
-	message, code string,
-	details map[string]string,
-) {
-	h.writeJSON(w, status, models.NewErrorResponse(message, code, details))
-}
-
-func (h *SessionHandler) successJSON(w http.ResponseWriter, status int, message string, data any) {
-	h.writeJSON(w, status, models.NewSuccessResponse(message, data))
-}
-
-func (h *SessionHandler) requireTenantID(w http.ResponseWriter, r *http.Request) (string, bool) {
-	tenantID := middleware.GetTenantID(r)
-	if tenantID == "" {
-		h.logger.Error("TenantID não encontrado no contexto")
-		h.errorJSON(w, http.StatusUnauthorized, "Não autorizado", "UNAUTHORIZED", nil)
-		return "", false
-	}
-	return tenantID, true
-}
-
-func (h *SessionHandler) pathVar(r *http.Request, key string) string {
-	return mux.Vars(r)[key]
+	return &SessionHandler{baseHandler: baseHandler{logger: log}, service: service}
 }
 
 func (h *SessionHandler) RegisterSession(w http.ResponseWriter, r *http.Request) {
@@ -73,25 +37,18 @@ func (h *SessionHandler) RegisterSession(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.WhatsAppSessionKey == "" || req.NomePessoa == "" || req.EmailPessoa == "" {
-		h.logger.Warn("Campos obrigatórios ausentes na requisição de registro")
-		h.errorJSON(
-			w,
-			http.StatusBadRequest,
-			"Campos obrigatórios ausentes",
-			"VALIDATION_ERROR",
-			map[string]string{
-				"whatsappSessionKey": "obrigatório",
-				"nomePessoa":         "obrigatório",
-				"emailPessoa":        "obrigatório",
-			},
-		)
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
 	h.logger.Infof("Registrando nova sessão: %s (%s) [Tenant: %s]", req.WhatsAppSessionKey, req.EmailPessoa, tenantID)
 
 	response, err := h.service.RegisterSession(&req, tenantID)
+	if errors.Is(err, services.ErrInvalidWebhookURL) {
+		h.logger.Warnf("Webhook recusado no registro de %s: %v", req.WhatsAppSessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "URL de webhook não permitida", "INVALID_WEBHOOK_URL", map[string]string{"webhookUrl": err.Error()})
+		return
+	}
 	if err != nil {
 		h.logger.Errorf("Falha ao registrar sessão: %v", err)
 		h.errorJSON(
@@ -104,11 +61,67 @@ func (h *SessionHandler) RegisterSession(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	message := "Sessão registrada com sucesso. Escaneie o QR code para conectar."
+	if response.PairingCode != "" {
+		message = "Sessão registrada com sucesso. Informe o código de pareamento no WhatsApp para conectar."
+	}
+
+	h.successJSON(w, http.StatusCreated, message, response)
+}
+
+func (h *SessionHandler) GetPairingCode(w http.ResponseWriter, r *http.Request) {
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "sessionKey é obrigatório", "VALIDATION_ERROR", nil)
+		return
+	}
+
+	code, expiresAt, err := h.service.GetPairingCode(sessionKey, tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrSessionAlreadyConnected) {
+			h.successJSON(
+				w,
+				http.StatusOK,
+				"Sessão ativa",
+				map[string]interface{}{
+					"message":               "Sessão já está conectada ao WhatsApp",
+					"session_key":           sessionKey,
+					"status":                "connected",
+					"pairing_code_required": false,
+				},
+			)
+			return
+		}
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+
+		h.logger.Warnf("Falha ao obter código de pareamento para %s: %v", sessionKey, err)
+		h.errorJSON(
+			w,
+			http.StatusNotFound,
+			"Falha ao obter código de pareamento",
+			"PAIRING_CODE_NOT_FOUND",
+			map[string]string{"error": err.Error()},
+		)
+		return
+	}
+
 	h.successJSON(
 		w,
-		http.StatusCreated,
-		"Sessão registrada com sucesso. Escaneie o QR code para conectar.",
-		response,
+		http.StatusOK,
+		"Código de pareamento obtido com sucesso",
+		map[string]interface{}{
+			"pairing_code": code,
+			"expires_at":   expiresAt,
+			"session_key":  sessionKey,
+		},
 	)
 }
 
@@ -141,6 +154,9 @@ func (h *SessionHandler) GetQRCode(w http.ResponseWriter, r *http.Request) {
 			)
 			return
 		}
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
 
 		h.logger.Warnf("Falha ao obter QR code para %s: %v", sessionKey, err)
 		h.errorJSON(
@@ -238,6 +254,9 @@ func (h *SessionHandler) DisconnectSession(w http.ResponseWriter, r *http.Reques
 	h.logger.Infof("Desconectando sessão: %s [Tenant: %s]", sessionKey, tenantID)
 
 	if err := h.service.DisconnectSession(sessionKey, tenantID); err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
 		h.logger.Errorf("Falha ao desconectar sessão %s: %v", sessionKey, err)
 		h.errorJSON(
 			w,
@@ -275,6 +294,9 @@ func (h *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 	h.logger.Infof("Deletando sessão: %s [Tenant: %s]", sessionKey, tenantID)
 
 	if err := h.service.DeleteSession(sessionKey, tenantID); err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
 		h.logger.Errorf("Falha ao deletar sessão %s: %v", sessionKey, err)
 		h.errorJSON(
 			w,
@@ -295,3 +317,53 @@ func (h *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 		},
 	)
 }
+
+func (h *SessionHandler) GetQueueDepth(w http.ResponseWriter, r *http.Request) {
+	sessionKey := h.pathVar(r, "sessionKey")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "sessionKey é obrigatório", "VALIDATION_ERROR", nil)
+		return
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	depth, err := h.service.GetQueueDepth(sessionKey, tenantID)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		h.logger.Errorf("Falha ao consultar fila da sessão %s: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao consultar fila de envio", "QUEUE_FETCH_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Fila de envio obtida com sucesso", depth)
+}
+
+func (h *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	sessionKey := h.pathVar(r, "sessionKey")
+	if sessionKey == "" {
+		h.errorJSON(w, http.StatusBadRequest, "sessionKey é obrigatório", "VALIDATION_ERROR", nil)
+		return
+	}
+
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	details, err := h.service.GetSessionDetails(sessionKey, tenantID)
+	if err != nil {
+		if h.respondSessionError(w, sessionKey, err) {
+			return
+		}
+		h.logger.Errorf("Falha ao obter sessão %s: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao obter sessão", "SESSION_FETCH_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Sessão obtida com sucesso", details)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/msgtemplate"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type TemplateHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+}
+
+func NewTemplateHandler(service *services.MultiTenantWhatsAppService, log *logger.Logger) *TemplateHandler {
+	return &TemplateHandler{baseHandler: baseHandler{logger: log}, service: service}
+}
+
+// respondTemplateError trata os erros de template comuns à gestão e ao envio
+// por template. ref identifica o template pedido nos detalhes do erro.
+func (h *baseHandler) respondTemplateError(w http.ResponseWriter, ref map[string]string, err error) bool {
+	var invalid *services.TemplateValidationError
+	var missing *msgtemplate.MissingError
+	switch {
+	case errors.As(err, &invalid):
+		h.errorJSON(w, http.StatusBadRequest, "Template inválido", "VALIDATION_ERROR", invalid.Details)
+	case errors.As(err, &missing):
+		h.errorJSON(w, http.StatusBadRequest, "Variáveis do template sem valor", "TEMPLATE_VARIABLES_MISSING", map[string]string{
+			"missing": strings.Join(missing.Names, ", "),
+		})
+	case errors.Is(err, services.ErrTemplateVersionNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Versão do template não encontrada", "TEMPLATE_VERSION_NOT_FOUND", ref)
+	case errors.Is(err, services.ErrTemplateNotFound):
+		h.errorJSON(w, http.StatusNotFound, "Template não encontrado", "TEMPLATE_NOT_FOUND", ref)
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *TemplateHandler) templateError(w http.ResponseWriter, templateID string, err error, message, code string) {
+	if h.respondTemplateError(w, map[string]string{"template_id": templateID}, err) {
+		return
+	}
+	h.logger.Errorf("%s (template %s): %v", message, templateID, err)
+	h.errorJSON(w, http.StatusInternalServerError, message, code, map[string]string{"error": err.Error()})
+}
+
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TemplateRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	template, err := h.service.CreateTemplate(tenantID, &req)
+	if errors.Is(err, services.ErrTemplateExists) {
+		h.errorJSON(w, http.StatusConflict, "Já existe um template com esse nome e idioma", "TEMPLATE_EXISTS", map[string]string{
+			"template_id": template.ID.String(),
+		})
+		return
+	}
+	if err != nil {
+		h.templateError(w, "", err, "Falha ao criar template", "TEMPLATE_CREATE_FAILED")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/templates/"+template.ID.String())
+	h.successJSON(w, http.StatusCreated, "Template criado com sucesso", template)
+}
+
+func (h *TemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.TemplateFilter{
+		TenantID: tenantID,
+		Name:     q.Get("name"),
+		Language: q.Get("language"),
+		Limit:    100,
+	}
+	details := map[string]string{}
+	if raw := q.Get("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n <= 0 || n > 500 {
+			details["limit"] = "entre 1 e 500"
+		} else {
+			filter.Limit = n
+		}
+	}
+	if raw := q.Get("offset"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			details["offset"] = "deve ser maior ou igual a zero"
+		} else {
+			filter.Offset = n
+		}
+	}
+	if len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Filtros inválidos", "VALIDATION_ERROR", details)
+		return
+	}
+
+	templates, err := h.service.ListTemplates(filter)
+	if err != nil {
+		h.templateError(w, "", err, "Falha ao listar templates", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Templates listados com sucesso", map[string]interface{}{
+		"total":     len(templates),
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+		"templates": templates,
+	})
+}
+
+// GetTemplate devolve a versão atual, ou a indicada em ?version=.
+func (h *TemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	templateID := h.pathVar(r, "templateId")
+
+	version := 0
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			h.errorJSON(w, http.StatusBadRequest, "Versão inválida", "VALIDATION_ERROR", map[string]string{"version": "deve ser um inteiro positivo"})
+			return
+		}
+		version = n
+	}
+
+	template, err := h.service.GetTemplate(tenantID, templateID, version)
+	if err != nil {
+		h.templateError(w, templateID, err, "Falha ao obter template", "TEMPLATE_FETCH_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Template obtido com sucesso", template)
+}
+
+func (h *TemplateHandler) ListTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	templateID := h.pathVar(r, "templateId")
+
+	versions, err := h.service.TemplateVersions(tenantID, templateID)
+	if err != nil {
+		h.templateError(w, templateID, err, "Falha ao listar versões do template", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Versões do template listadas com sucesso", map[string]interface{}{
+		"total":    len(versions),
+		"versions": versions,
+	})
+}
+
+// UpdateTemplate grava uma nova versão; as anteriores continuam disponíveis
+// para consulta e para mensagens que as usaram.
+func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	templateID := h.pathVar(r, "templateId")
+
+	var req models.TemplateRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(tenantID, templateID, &req)
+	if err != nil {
+		h.templateError(w, templateID, err, "Falha ao atualizar template", "TEMPLATE_UPDATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Template atualizado com sucesso", template)
+}
+
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	templateID := h.pathVar(r, "templateId")
+
+	if err := h.service.DeleteTemplate(tenantID, templateID); err != nil {
+		h.templateError(w, templateID, err, "Falha ao remover template", "TEMPLATE_DELETE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Template removido com sucesso", nil)
+}
+
+// PreviewTemplate renderiza o template com as variáveis, sem enviar.
+func (h *TemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	templateID := h.pathVar(r, "templateId")
+
+	var req models.TemplateRenderRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	rendered, err := h.service.RenderTemplate(tenantID, templateID, &req)
+	if err != nil {
+		h.templateError(w, templateID, err, "Falha ao renderizar template", "TEMPLATE_RENDER_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Template renderizado com sucesso", rendered)
+}
+
+// SendTemplateMessage trata POST /messages/template: renderiza o template
+// com as variáveis e envia como texto ou mídia, síncrono, pela fila ou
+// agendado, como /messages/text e /messages/media.
+func (h *MultiTenantHandler) SendTemplateMessage(w http.ResponseWriter, r *http.Request) {
+	var req models.TemplateSendRequest
+	sessionKey, tenantID, ok := h.decodeSendRequest(w, r, &req, func() string { return req.Number })
+	if !ok {
+		return
+	}
+
+	if !h.validateRecipient(w, sessionKey, req.Number, req.Mentions) {
+		return
+	}
+	if details := validateSendAt(req.SendAt); len(details) > 0 {
+		h.errorJSON(w, http.StatusBadRequest, "Agendamento inválido", "VALIDATION_ERROR", details)
+		return
+	}
+
+	ref := map[string]string{"template_id": req.TemplateID}
+	if req.TemplateID == "" {
+		ref = map[string]string{"name": req.Name, "language": req.Language}
+	}
+
+	if req.SendAt != nil || wantsAsync(r, req.Async) {
+		accepted, err := h.whatsappService.EnqueueTemplateMessage(sessionKey, tenantID, &req)
+		if h.respondTemplateError(w, ref, err) {
+			return
+		}
+		h.respondQueued(w, sessionKey, accepted, err)
+		return
+	}
+
+	sent, err := h.whatsappService.SendTemplateMessage(sessionKey, tenantID, &req)
+	if h.respondTemplateError(w, ref, err) || h.respondSessionError(w, sessionKey, err) || h.respondRateLimited(w, sessionKey, err) || h.respondRecipientError(w, sessionKey, err) || h.respondMediaError(w, sessionKey, err) {
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("[%s] Falha ao enviar template para %s: %v", sessionKey, req.Number, err)
+		h.errorJSON(w, http.StatusInternalServerError, "Falha ao enviar mensagem de template", "SEND_FAILED", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Infof("[%s] Template %s v%d enviado para %s", sessionKey, sent.TemplateID, sent.Version, req.Number)
+	h.successJSON(w, http.StatusOK, "Mensagem de template enviada com sucesso", sent)
+}
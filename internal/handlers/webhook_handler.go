@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	baseHandler
+	service *services.MultiTenantWhatsAppService
+}
+
+func NewWebhookHandler(service *services.MultiTenantWhatsAppService, log *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{baseHandler: baseHandler{logger: log}, service: service}
+}
+
+func (h *WebhookHandler) sessionError(w http.ResponseWriter, sessionKey string, err error, message, code string) {
+	if h.respondSessionError(w, sessionKey, err) {
+		return
+	}
+	h.logger.Errorf("%s (%s): %v", message, sessionKey, err)
+	h.errorJSON(w, http.StatusInternalServerError, message, code, map[string]string{"error": err.Error()})
+}
+
+func (h *WebhookHandler) SetWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	var req models.WebhookConfigRequest
+	if err := validator.ValidateJSON(r, &req); err != nil {
+		h.logger.Warnf("[%s] JSON inválido na configuração de webhook: %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "Corpo da requisição inválido", "INVALID_JSON", map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	resp, err := h.service.SetWebhook(sessionKey, tenantID, &req)
+	if errors.Is(err, services.ErrInvalidWebhookURL) {
+		h.logger.Warnf("[%s] %v", sessionKey, err)
+		h.errorJSON(w, http.StatusBadRequest, "URL de webhook não permitida", "INVALID_WEBHOOK_URL", map[string]string{"url": err.Error()})
+		return
+	}
+	if err != nil {
+		h.sessionError(w, sessionKey, err, "Falha ao configurar webhook", "WEBHOOK_UPDATE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Webhook configurado com sucesso", resp)
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	resp, err := h.service.GetWebhook(sessionKey, tenantID)
+	if err != nil {
+		h.sessionError(w, sessionKey, err, "Falha ao obter webhook", "WEBHOOK_FETCH_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Webhook obtido com sucesso", resp)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	if err := h.service.DeleteWebhook(sessionKey, tenantID); err != nil {
+		h.sessionError(w, sessionKey, err, "Falha ao remover webhook", "WEBHOOK_DELETE_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Webhook removido com sucesso", map[string]string{"session_key": sessionKey})
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryDead:
+	default:
+		h.errorJSON(w, http.StatusBadRequest, "Status inválido", "VALIDATION_ERROR", map[string]string{
+			"status": "use pending, delivered ou dead",
+		})
+		return
+	}
+
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			h.errorJSON(w, http.StatusBadRequest, "Limite inválido", "VALIDATION_ERROR", map[string]string{"limit": "entre 1 e 500"})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.service.ListWebhookDeliveries(sessionKey, tenantID, status, limit)
+	if err != nil {
+		h.sessionError(w, sessionKey, err, "Falha ao listar entregas de webhook", "LIST_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusOK, "Entregas de webhook listadas com sucesso", map[string]interface{}{
+		"total":      len(deliveries),
+		"deliveries": deliveries,
+	})
+}
+
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.requireTenantID(w, r)
+	if !ok {
+		return
+	}
+	sessionKey := h.pathVar(r, "sessionKey")
+
+	deliveryID, err := uuid.Parse(h.pathVar(r, "deliveryId"))
+	if err != nil {
+		h.errorJSON(w, http.StatusBadRequest, "deliveryId inválido", "VALIDATION_ERROR", map[string]string{"deliveryId": "deve ser um UUID"})
+		return
+	}
+
+	err = h.service.RetryWebhookDelivery(sessionKey, tenantID, deliveryID)
+	if errors.Is(err, services.ErrWebhookDeliveryNotFound) {
+		h.errorJSON(w, http.StatusNotFound, "Entrega de webhook não encontrada", "WEBHOOK_DELIVERY_NOT_FOUND", map[string]string{"deliveryId": deliveryID.String()})
+		return
+	}
+	if err != nil {
+		h.sessionError(w, sessionKey, err, "Falha ao reenfileirar entrega", "WEBHOOK_RETRY_FAILED")
+		return
+	}
+
+	h.successJSON(w, http.StatusAccepted, "Entrega reenfileirada", map[string]string{
+		"delivery_id": deliveryID.String(),
+		"status":      models.WebhookDeliveryPending,
+	})
+}
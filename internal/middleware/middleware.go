@@ -3,58 +3,149 @@ package middleware
 import (
 	"boot-whatsapp-golang/internal/config"
 	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/services"
 	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/metrics"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 
 type contextKey string
 
 const TenantIDKey contextKey = "tenant_id"
 
-func AuthMiddleware(cfg *config.Config, log *logger.Logger) func(http.Handler) http.Handler {
+func writeError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.NewErrorResponse(message, code, nil))
+}
+
+func tokensEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// AuthMiddleware resolve o tenant pela chave de API enviada no header
+// apitoken. Com AUTH_ALLOW_LEGACY_TOKEN, o API_TOKEN global ainda é aceito
+// junto do SESSIONKEY, desde que este corresponda a um tenant cadastrado.
+func AuthMiddleware(cfg *config.Config, tenants *services.TenantService, log *logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			apiToken := r.Header.Get("apitoken")
-			sessionKey := r.Header.Get("SESSIONKEY")
-
-			if apiToken != cfg.Auth.APIToken {
-				log.Warnf("Tentativa de acesso não autorizado de %s - Token inválido",
-					r.RemoteAddr)
-
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-					"Credenciais de autenticação inválidas",
-					"AUTH_INVALID",
-					nil,
-				))
+			if apiToken == "" {
+				log.Warnf("Tentativa de acesso sem chave de API de %s", r.RemoteAddr)
+				writeError(w, http.StatusUnauthorized, "Credenciais de autenticação inválidas", "AUTH_INVALID")
+				return
+			}
+
+			if cfg.Auth.AllowLegacyToken && tokensEqual(apiToken, cfg.Auth.APIToken) {
+				tenantID := r.Header.Get("SESSIONKEY")
+				if tenantID == "" {
+					log.Warnf("Tentativa de acesso sem SESSION_KEY de %s", r.RemoteAddr)
+					writeError(w, http.StatusUnauthorized, "SESSION_KEY é obrigatório", "SESSION_KEY_REQUIRED")
+					return
+				}
+
+				tenant, err := tenants.GetTenant(tenantID)
+				if errors.Is(err, services.ErrTenantNotFound) {
+					log.Warnf("Token legado usado com tenant desconhecido de %s", r.RemoteAddr)
+					writeError(w, http.StatusUnauthorized, "Tenant não cadastrado", "TENANT_NOT_FOUND")
+					return
+				}
 				if err != nil {
+					log.Errorf("Falha ao verificar tenant %s: %v", tenantID, err)
+					writeError(w, http.StatusInternalServerError, "Erro interno do servidor", "INTERNAL_ERROR")
+					return
+				}
+				if tenant.Status != models.TenantStatusActive {
+					writeError(w, http.StatusForbidden, "Tenant desativado", "TENANT_DISABLED")
 					return
 				}
+
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), TenantIDKey, tenantID)))
 				return
 			}
 
-			if sessionKey == "" {
-				log.Warnf("Tentativa de acesso sem SESSION_KEY de %s", r.RemoteAddr)
-
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				err := json.NewEncoder(w).Encode(models.NewErrorResponse(
-					"SESSION_KEY é obrigatório",
-					"SESSION_KEY_REQUIRED",
-					nil,
-				))
-				if err != nil {
+			tenantID, err := tenants.ResolveTenant(apiToken)
+			if err != nil {
+				if errors.Is(err, services.ErrInvalidAPIKey) {
+					log.Warnf("Tentativa de acesso não autorizado de %s - Chave inválida", r.RemoteAddr)
+					writeError(w, http.StatusUnauthorized, "Credenciais de autenticação inválidas", "AUTH_INVALID")
 					return
 				}
+				log.Errorf("Falha ao validar chave de API: %v", err)
+				writeError(w, http.StatusInternalServerError, "Erro interno do servidor", "INTERNAL_ERROR")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), TenantIDKey, tenantID)))
+		})
+	}
+}
+
+// AdminMiddleware protege a administração de tenants com o ADMIN_TOKEN,
+// enviado no header X-Admin-Token.
+func AdminMiddleware(cfg *config.Config, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Auth.AdminToken == "" {
+				writeError(w, http.StatusForbidden, "Administração desabilitada: configure ADMIN_TOKEN", "ADMIN_DISABLED")
+				return
+			}
+
+			if !tokensEqual(r.Header.Get("X-Admin-Token"), cfg.Auth.AdminToken) {
+				log.Warnf("Tentativa de acesso administrativo não autorizado de %s", r.RemoteAddr)
+				writeError(w, http.StatusUnauthorized, "Credenciais de administração inválidas", "AUTH_INVALID")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), TenantIDKey, sessionKey)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StreamAuthMiddleware protege as rotas de stream de eventos. Além do
+// apitoken, aceita ?token= emitido em .../events/token, porque EventSource e
+// WebSocket do navegador não enviam headers. O token vale só para a sessão
+// em que foi emitido.
+func StreamAuthMiddleware(cfg *config.Config, tenants *services.TenantService, tokens *services.StreamTokens, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		headerAuth := AuthMiddleware(cfg, tenants, log)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				headerAuth.ServeHTTP(w, r)
+				return
+			}
+
+			tenantID, sessionKey, err := tokens.Verify(token)
+			if err != nil || sessionKey != mux.Vars(r)["sessionKey"] {
+				log.Warnf("Token de stream inválido de %s", r.RemoteAddr)
+				writeError(w, http.StatusUnauthorized, "Token de stream inválido ou expirado", "AUTH_INVALID")
+				return
+			}
+
+			// O token não é revogável; o tenant desativado depois da emissão
+			// ainda é barrado aqui.
+			tenant, err := tenants.GetTenant(tenantID)
+			if err != nil && !errors.Is(err, services.ErrTenantNotFound) {
+				log.Errorf("Falha ao verificar tenant %s: %v", tenantID, err)
+				writeError(w, http.StatusInternalServerError, "Erro interno do servidor", "INTERNAL_ERROR")
+				return
+			}
+			if err != nil || tenant.Status != models.TenantStatusActive {
+				writeError(w, http.StatusUnauthorized, "Token de stream inválido ou expirado", "AUTH_INVALID")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), TenantIDKey, tenantID)))
 		})
 	}
 }
@@ -115,12 +206,18 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap permite que http.ResponseController e upgrades de WebSocket
+// alcancem o ResponseWriter original (Flush, Hijack, deadlines).
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func CORSMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, apitoken, SESSIONKEY")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, apitoken, SESSIONKEY, X-Admin-Token, X-WhatsApp-Session-Key, Prefer, Last-Event-ID")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -140,3 +237,64 @@ func ContentTypeMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+var (
+	httpRequests = metrics.Default.NewCounterVec("http_requests_total",
+		"Requisições HTTP atendidas, por método, template de rota e status.",
+		"method", "route", "status")
+	httpDuration = metrics.Default.NewHistogramVec("http_request_duration_seconds",
+		"Latência das requisições HTTP, por método, template de rota e status.",
+		nil, "method", "route", "status")
+)
+
+// UnmatchedRoute é o label de rota das requisições que não casaram com
+// nenhuma rota (404/405), para que caminhos arbitrários não virem séries.
+const UnmatchedRoute = "unmatched"
+
+// MetricsMiddleware conta e cronometra as requisições pelo template da rota
+// do mux (/api/v1/whatsapp/sessions/{sessionKey}), nunca pelo caminho bruto.
+// Streams longos (SSE, WebSocket) entram na latência pelo tempo conectado.
+func MetricsMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			route := UnmatchedRoute
+			if current := mux.CurrentRoute(r); current != nil {
+				if tpl, err := current.GetPathTemplate(); err == nil {
+					route = tpl
+				}
+			}
+			status := strconv.Itoa(rw.statusCode)
+			httpRequests.Inc(r.Method, route, status)
+			httpDuration.Observe(time.Since(start).Seconds(), r.Method, route, status)
+		})
+	}
+}
+
+// MetricsAuthMiddleware protege o /metrics com o METRICS_TOKEN, enviado como
+// Authorization: Bearer. Sem token configurado, o endpoint fica aberto e
+// deve ser isolado na rede.
+func MetricsAuthMiddleware(cfg *config.Config, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Metrics.Token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || !tokensEqual(token, cfg.Metrics.Token) {
+				log.Warnf("Tentativa de acesso não autorizado às métricas de %s", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				writeError(w, http.StatusUnauthorized, "Credenciais de métricas inválidas", "AUTH_INVALID")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusRunning   = "running"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCanceled  = "canceled"
+)
+
+const (
+	CampaignKindText  = "text"
+	CampaignKindMedia = "media"
+)
+
+// Status de cada destinatário. delivered e read não são gravados na
+// campanha: vêm dos recibos da mensagem enviada (tabela messages).
+const (
+	CampaignRecipientQueued     = "queued"
+	CampaignRecipientProcessing = "processing"
+	CampaignRecipientSent       = "sent"
+	CampaignRecipientDelivered  = "delivered"
+	CampaignRecipientRead       = "read"
+	CampaignRecipientFailed     = "failed"
+	CampaignRecipientCanceled   = "canceled"
+)
+
+// CampaignMessage é a mensagem enviada a cada destinatário. text e caption
+// aceitam variáveis {{nome}}, preenchidas com as colunas do destinatário.
+// Campanhas de mídia usam media_id (recomendado) ou media_url; a mídia de
+// media_url é enviada ao WhatsApp uma única vez, no primeiro envio.
+type CampaignMessage struct {
+	Text        string `json:"text,omitempty" validate:"excluded_with=MediaURL MediaID,max=65536"`
+	LinkPreview *bool  `json:"link_preview,omitempty" validate:"excluded_with=MediaURL MediaID"`
+	Caption     string `json:"caption,omitempty"`
+	MediaURL    string `json:"media_url,omitempty" validate:"excluded_with=MediaID,omitempty,url"`
+	MediaID     string `json:"media_id,omitempty"`
+	MimeType    string `json:"mime_type,omitempty" validate:"excluded_with=MediaID,omitempty,mime"`
+	FileName    string `json:"filename,omitempty"`
+	Mode        string `json:"mode,omitempty" validate:"excluded_with=MediaID,omitempty,oneof=voice sticker"`
+}
+
+// CampaignRequest cria uma campanha em rascunho. Sem starts_at, o envio
+// começa assim que a campanha é iniciada; window_start/window_end restringem
+// os envios a uma faixa diária (HH:MM no fuso timezone, padrão UTC) e
+// per_minute define o ritmo, abaixo dos limites da sessão.
+type CampaignRequest struct {
+	Name        string                   `json:"name" validate:"required,max=255"`
+	SessionKey  string                   `json:"session_key" validate:"required"`
+	Message     CampaignMessage          `json:"message"`
+	StartsAt    *time.Time               `json:"starts_at,omitempty"`
+	EndsAt      *time.Time               `json:"ends_at,omitempty"`
+	WindowStart string                   `json:"window_start,omitempty"`
+	WindowEnd   string                   `json:"window_end,omitempty"`
+	Timezone    string                   `json:"timezone,omitempty"`
+	PerMinute   int                      `json:"per_minute,omitempty" validate:"min=0,max=600"` // 0 usa o padrão
+	Recipients  []CampaignRecipientInput `json:"recipients,omitempty"`
+}
+
+// CampaignRecipientInput é uma linha da lista de destinatários. Line é a
+// linha de origem (no CSV, contando o cabeçalho), usada nos erros.
+type CampaignRecipientInput struct {
+	Number    string            `json:"number"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Line      int               `json:"-"`
+}
+
+type Campaign struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	TenantID    string          `json:"-" db:"tenant_id"`
+	SessionID   uuid.UUID       `json:"-" db:"session_id"`
+	SessionKey  string          `json:"session_key" db:"session_key"`
+	Name        string          `json:"name" db:"name"`
+	Kind        string          `json:"kind" db:"kind"`
+	Message     CampaignMessage `json:"message" db:"message"`
+	Status      string          `json:"status" db:"status"`
+	StartsAt    *time.Time      `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt      *time.Time      `json:"ends_at,omitempty" db:"ends_at"`
+	WindowStart string          `json:"window_start,omitempty" db:"window_start"`
+	WindowEnd   string          `json:"window_end,omitempty" db:"window_end"`
+	Timezone    string          `json:"timezone,omitempty" db:"timezone"`
+	PerMinute   int             `json:"per_minute" db:"per_minute"`
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	Stats       *CampaignStats  `json:"stats,omitempty"`
+}
+
+// CampaignStats conta os destinatários pelo status de relatório; Read inclui
+// áudios reproduzidos.
+type CampaignStats struct {
+	Total      int `json:"total"`
+	Queued     int `json:"queued"`
+	Processing int `json:"processing"`
+	Sent       int `json:"sent"`
+	Delivered  int `json:"delivered"`
+	Read       int `json:"read"`
+	Failed     int `json:"failed"`
+	Canceled   int `json:"canceled"`
+}
+
+type CampaignRecipient struct {
+	CampaignID    uuid.UUID         `json:"-" db:"campaign_id"`
+	Position      int               `json:"position" db:"position"`
+	Number        string            `json:"number" db:"number"`
+	Variables     map[string]string `json:"variables,omitempty" db:"variables"`
+	Status        string            `json:"status" db:"status"`
+	Attempts      int               `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time         `json:"-" db:"next_attempt_at"`
+	MessageID     *string           `json:"message_id,omitempty" db:"message_id"`
+	LastError     *string           `json:"error,omitempty" db:"last_error"`
+	SentAt        *time.Time        `json:"sent_at,omitempty" db:"sent_at"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty"`
+	ReadAt        *time.Time        `json:"read_at,omitempty"`
+	UpdatedAt     time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+type CampaignRecipientFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// CampaignRecipientsAdded resume um upload de lista. Duplicates conta os
+// números repetidos na lista ou já presentes na campanha, que são ignorados.
+type CampaignRecipientsAdded struct {
+	Added      int `json:"added"`
+	Duplicates int `json:"duplicates"`
+	Total      int `json:"total"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// ContactCheckRequest verifica em lote se os números têm WhatsApp, até 100
+// por requisição.
+type ContactCheckRequest struct {
+	Numbers []string `json:"numbers" validate:"required,max=100"`
+}
+
+// ContactCheckResult traz, para números registrados, o JID canônico e o
+// número na grafia registrada (E.164). Números que não puderam ser
+// normalizados trazem o motivo em error.
+type ContactCheckResult struct {
+	Input      string `json:"input"`
+	Number     string `json:"number,omitempty"`
+	JID        string `json:"jid,omitempty"`
+	OnWhatsApp bool   `json:"on_whatsapp"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ContactCheckResponse struct {
+	Total      int                  `json:"total"`
+	Registered int                  `json:"registered"`
+	Results    []ContactCheckResult `json:"results"`
+}
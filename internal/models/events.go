@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+const (
+	EventTypeMessage      = "message"
+	EventTypeReceipt      = "receipt"
+	EventTypeConnection   = "connection"
+	EventTypePresence     = "presence"
+	EventTypeChatPresence = "chat_presence"
+	EventTypeQRCode       = "qr_code"
+	EventTypePollVote     = "poll_vote"
+	// EventTypeGap só existe no stream: avisa que eventos entre o
+	// Last-Event-ID e o primeiro evento reenviado se perderam.
+	EventTypeGap = "gap"
+)
+
+// SessionEvent é o envelope normalizado de tudo que acontece em uma sessão
+// WhatsApp e que é repassado para integrações externas.
+type SessionEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	SessionKey string      `json:"session_key"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Data       interface{} `json:"data"`
+}
+
+type InboundMessageEvent struct {
+	MessageID string    `json:"message_id"`
+	Chat      string    `json:"chat"`
+	Sender    string    `json:"sender"`
+	SenderAlt string    `json:"sender_alt,omitempty"`
+	PushName  string    `json:"push_name,omitempty"`
+	FromMe    bool      `json:"from_me"`
+	IsGroup   bool      `json:"is_group"`
+	Type      string    `json:"type"`
+	Text      string    `json:"text,omitempty"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	FileName  string    `json:"file_name,omitempty"`
+	QuotedID  string    `json:"quoted_id,omitempty"`
+	IsEdit    bool      `json:"is_edit,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PollVoteEvent traz o voto já descriptografado. Um voto sem opções indica
+// que o participante retirou o voto. Quando a enquete não é conhecida pela
+// API, as opções seguem apenas como hashes SHA-256 (hex).
+type PollVoteEvent struct {
+	PollMessageID   string    `json:"poll_message_id"`
+	Chat            string    `json:"chat"`
+	Voter           string    `json:"voter"`
+	SelectedOptions []string  `json:"selected_options"`
+	OptionHashes    []string  `json:"option_hashes,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+type ReceiptEvent struct {
+	MessageIDs []string  `json:"message_ids"`
+	Chat       string    `json:"chat"`
+	Sender     string    `json:"sender"`
+	IsGroup    bool      `json:"is_group"`
+	Type       string    `json:"type"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type ConnectionEvent struct {
+	Status      string `json:"status"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// StreamEvent é um SessionEvent numerado sequencialmente dentro da sessão,
+// usado pelo stream em tempo real para permitir retomada via Last-Event-ID.
+type StreamEvent struct {
+	StreamID uint64 `json:"stream_id"`
+	*SessionEvent
+}
+
+// StreamGapEvent abre o replay quando o Last-Event-ID é mais antigo que o
+// buffer da sessão (ou de antes de um restart). O cliente deve recarregar o
+// estado pela API em vez de confiar apenas nos eventos seguintes.
+type StreamGapEvent struct {
+	LastEventID uint64 `json:"last_event_id"`
+	ResumedFrom uint64 `json:"resumed_from"`
+}
+
+// StreamTokenResponse é o token curto que autentica o stream pela query
+// string, para clientes (EventSource, WebSocket do navegador) que não
+// enviam headers.
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PresenceEvent struct {
+	JID         string     `json:"jid"`
+	Unavailable bool       `json:"unavailable"`
+	LastSeen    *time.Time `json:"last_seen,omitempty"`
+}
+
+type ChatPresenceEvent struct {
+	Chat   string `json:"chat"`
+	Sender string `json:"sender"`
+	State  string `json:"state"`
+	Media  string `json:"media,omitempty"`
+}
+
+type QRCodeEvent struct {
+	QRCodeBase64 string    `json:"qr_code_base64"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
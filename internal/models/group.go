@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+const (
+	GroupParticipantAdd     = "add"
+	GroupParticipantRemove  = "remove"
+	GroupParticipantPromote = "promote"
+	GroupParticipantDemote  = "demote"
+)
+
+type GroupParticipant struct {
+	JID          string `json:"jid"`
+	PhoneNumber  string `json:"phone_number,omitempty"`
+	IsAdmin      bool   `json:"is_admin"`
+	IsSuperAdmin bool   `json:"is_super_admin"`
+	// Error traz o código devolvido pelo WhatsApp quando a alteração falhou
+	// só para este participante (ex.: 403 privacidade, 409 já é membro).
+	Error int `json:"error,omitempty"`
+}
+
+type Group struct {
+	JID              string             `json:"jid"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description,omitempty"`
+	OwnerJID         string             `json:"owner_jid,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+	IsAnnounce       bool               `json:"is_announce"`
+	IsLocked         bool               `json:"is_locked"`
+	ParticipantCount int                `json:"participant_count"`
+	Participants     []GroupParticipant `json:"participants,omitempty"`
+}
+
+type CreateGroupRequest struct {
+	Name         string   `json:"name" validate:"required,max=25"` // limite do WhatsApp, que recusa nomes maiores com 406
+	Participants []string `json:"participants" validate:"required,dive,e164"`
+}
+
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty" validate:"required_without=Description,max=25"`
+	Description *string `json:"description,omitempty" validate:"required_without=Name"`
+}
+
+type UpdateGroupParticipantsRequest struct {
+	Action       string   `json:"action" validate:"required,oneof=add remove promote demote"`
+	Participants []string `json:"participants" validate:"required,dive,e164"`
+}
+
+type GroupPictureRequest struct {
+	MediaURL    string `json:"media_url,omitempty" validate:"required_without=MediaBase64"`
+	MediaBase64 string `json:"media_base64,omitempty" validate:"required_without=MediaURL"`
+	MimeType    string `json:"mime_type,omitempty" validate:"required_with=MediaBase64,mime"`
+}
+
+type JoinGroupRequest struct {
+	InviteLink string `json:"invite_link" validate:"required"`
+}
+
+type GroupInviteLink struct {
+	GroupJID   string `json:"group_jid"`
+	InviteLink string `json:"invite_link"`
+}
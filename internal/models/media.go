@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaUploadRequest pré-envia uma mídia para reutilizá-la por media_id.
+// Os campos têm o mesmo sentido que em MediaRequest.
+type MediaUploadRequest struct {
+	MediaURL        string `json:"media_url" validate:"required_without_all=MediaBase64 FromFile,omitempty,url"`
+	MediaBase64     string `json:"media_base64" validate:"required_without_all=MediaURL FromFile"`
+	MimeType        string `json:"mime_type" validate:"required_with=MediaBase64,mime"`
+	FileName        string `json:"filename,omitempty"`
+	Mode            string `json:"mode,omitempty" validate:"omitempty,oneof=voice sticker"`
+	ThumbnailBase64 string `json:"thumbnail_base64,omitempty" validate:"excluded_with=Mode"`
+	FromFile        bool   `json:"-"`
+}
+
+// MediaUpload é uma mídia já enviada aos servidores do WhatsApp. O upload é
+// endereçado pelo SHA-256 do conteúdo original e pelo tipo de mídia, e vale
+// até ExpiresAt.
+type MediaUpload struct {
+	ID            uuid.UUID `json:"media_id" db:"id"`
+	TenantID      string    `json:"-" db:"tenant_id"`
+	SHA256        string    `json:"sha256" db:"sha256"`
+	MediaType     string    `json:"media_type" db:"media_type"` // image, video, audio ou document
+	URL           string    `json:"-" db:"url"`
+	DirectPath    string    `json:"-" db:"direct_path"`
+	MediaKey      []byte    `json:"-" db:"media_key"`
+	FileEncSHA256 []byte    `json:"-" db:"file_enc_sha256"`
+	FileLength    uint64    `json:"file_length" db:"file_length"`
+	Mode          string    `json:"mode,omitempty" db:"mode"`
+	MimeType      string    `json:"mime_type" db:"mime_type"`
+	FileName      string    `json:"filename,omitempty" db:"file_name"`
+	Metadata      string    `json:"-" db:"metadata"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at" db:"expires_at"`
+}
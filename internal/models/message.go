@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MessageDirectionOutbound = "outbound"
+	MessageDirectionInbound  = "inbound"
+
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+	MessageStatusPlayed    = "played"
+)
+
+type Message struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	MessageID       string     `json:"message_id" db:"message_id"`
+	SessionID       uuid.UUID  `json:"session_id" db:"session_id"`
+	TenantID        string     `json:"tenant_id" db:"tenant_id"`
+	Direction       string     `json:"direction" db:"direction"`
+	RecipientJID    string     `json:"recipient_jid" db:"recipient_jid"`
+	SenderJID       string     `json:"sender_jid,omitempty" db:"sender_jid"`
+	Type            string     `json:"type" db:"type"`
+	PayloadSummary  string     `json:"payload_summary" db:"payload_summary"`
+	Status          string     `json:"status" db:"status"`
+	ServerTimestamp time.Time  `json:"server_timestamp" db:"server_timestamp"`
+	DeliveredAt     *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	ReadAt          *time.Time `json:"read_at,omitempty" db:"read_at"`
+	PlayedAt        *time.Time `json:"played_at,omitempty" db:"played_at"`
+	EditedAt        *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	TemplateID      *uuid.UUID `json:"template_id,omitempty" db:"template_id"`
+	TemplateVersion *int       `json:"template_version,omitempty" db:"template_version"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type MessageFilter struct {
+	TenantID     string
+	SessionID    *uuid.UUID
+	RecipientJID string
+	Status       string
+	Type         string
+	Direction    string
+	Since        *time.Time
+	Until        *time.Time
+	Limit        int
+	Offset       int
+}
+
+type EditMessageRequest struct {
+	Text string `json:"text" validate:"required,max=65536"`
+}
+
+type ReactionRequest struct {
+	// Emoji vazio remove a reação enviada anteriormente. O limite comporta
+	// emojis compostos (tom de pele, ZWJ) sem aceitar texto livre.
+	Emoji string `json:"emoji" validate:"max=10"`
+}
+
+// MessageAction é a resposta de reações, edições e revogações, que geram uma
+// mensagem de protocolo própria referenciando a original.
+type MessageAction struct {
+	MessageID       string    `json:"message_id"`
+	TargetMessageID string    `json:"target_message_id"`
+	Action          string    `json:"action"`
+	SentAt          time.Time `json:"sent_at"`
+}
@@ -7,25 +7,34 @@ import (
 )
 
 type WhatsAppSession struct {
-	ID                 uuid.UUID  `json:"id" db:"id"`
-	TenantID           string     `json:"tenant_id" db:"tenant_id"`
-	WhatsAppSessionKey string     `json:"whatsapp_session_key" db:"whatsapp_session_key"`
-	NomePessoa         string     `json:"nome_pessoa" db:"nome_pessoa"`
-	EmailPessoa        string     `json:"email_pessoa" db:"email_pessoa"`
-	PhoneNumber        *string    `json:"phone_number,omitempty" db:"phone_number"`
-	DeviceJID          *string    `json:"device_jid,omitempty" db:"device_jid"`
-	Status             string     `json:"status" db:"status"`
-	QRCode             *string    `json:"qr_code,omitempty" db:"qr_code"`
-	QRCodeExpiresAt    *time.Time `json:"qr_code_expires_at,omitempty" db:"qr_code_expires_at"`
-	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
-	LastConnectedAt    *time.Time `json:"last_connected_at,omitempty" db:"last_connected_at"`
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	TenantID             string     `json:"tenant_id" db:"tenant_id"`
+	WhatsAppSessionKey   string     `json:"whatsapp_session_key" db:"whatsapp_session_key"`
+	NomePessoa           string     `json:"nome_pessoa" db:"nome_pessoa"`
+	EmailPessoa          string     `json:"email_pessoa" db:"email_pessoa"`
+	PhoneNumber          *string    `json:"phone_number,omitempty" db:"phone_number"`
+	DeviceJID            *string    `json:"device_jid,omitempty" db:"device_jid"`
+	Status               string     `json:"status" db:"status"`
+	QRCode               *string    `json:"qr_code,omitempty" db:"qr_code"`
+	QRCodeExpiresAt      *time.Time `json:"qr_code_expires_at,omitempty" db:"qr_code_expires_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	LastConnectedAt      *time.Time `json:"last_connected_at,omitempty" db:"last_connected_at"`
+	WebhookURL           *string    `json:"webhook_url,omitempty" db:"webhook_url"`
+	WebhookSecret        *string    `json:"-" db:"webhook_secret"`
+	PairingPhone         *string    `json:"pairing_phone,omitempty" db:"pairing_phone"`
+	PairingCode          *string    `json:"pairing_code,omitempty" db:"pairing_code"`
+	PairingCodeExpiresAt *time.Time `json:"pairing_code_expires_at,omitempty" db:"pairing_code_expires_at"`
 }
 
 type RegisterSessionRequest struct {
-	WhatsAppSessionKey string `json:"whatsappSessionKey" validate:"required"`
-	NomePessoa         string `json:"nomePessoa" validate:"required"`
-	EmailPessoa        string `json:"emailPessoa" validate:"required,email"`
+	WhatsAppSessionKey string `json:"whatsappSessionKey" validate:"required,max=255"`
+	NomePessoa         string `json:"nomePessoa" validate:"required,max=255"`
+	EmailPessoa        string `json:"emailPessoa" validate:"required,email,max=255"`
+	WebhookURL         string `json:"webhookUrl" validate:"omitempty,url"`
+	WebhookSecret      string `json:"webhookSecret"`
+	PairingMethod      string `json:"pairingMethod" validate:"omitempty,oneof=qr code"`
+	PhoneNumber        string `json:"phoneNumber" validate:"required_if=PairingMethod code,e164"`
 }
 
 type RegisterSessionResponse struct {
@@ -34,8 +43,15 @@ type RegisterSessionResponse struct {
 	QRCodeBase64       string    `json:"qr_code_base64"`
 	Status             string    `json:"status"`
 	ExpiresAt          time.Time `json:"expires_at"`
+	WebhookSecret      string    `json:"webhook_secret,omitempty"`
+	PairingCode        string    `json:"pairing_code,omitempty"`
 }
 
+const (
+	PairingMethodQR   = "qr"
+	PairingMethodCode = "code"
+)
+
 const (
 	SessionStatusPending      = "pending"
 	SessionStatusConnected    = "connected"
@@ -44,18 +60,49 @@ const (
 )
 
 type MessageRequest struct {
-	Number string `json:"number" validate:"required"`
-	Text   string `json:"text" validate:"required"`
+	Number          string       `json:"number" validate:"required"`
+	Text            string       `json:"text" validate:"required,max=65536"`
+	Mentions        []string     `json:"mentions,omitempty"`
+	QuotedMessageID string       `json:"quoted_message_id,omitempty"`
+	LinkPreview     *bool        `json:"link_preview,omitempty"` // false desativa a prévia automática
+	Preview         *LinkPreview `json:"preview,omitempty"`      // prévia pronta, sem buscar a página
+	Async           bool         `json:"async,omitempty"`
+	SendAt          *time.Time   `json:"send_at,omitempty"` // agenda o envio (RFC3339 com fuso)
+}
+
+// LinkPreview é a prévia de link exibida acima do texto.
+type LinkPreview struct {
+	URL             string `json:"url,omitempty" validate:"omitempty,url"`
+	Title           string `json:"title" validate:"required"`
+	Description     string `json:"description,omitempty"`
+	ThumbnailBase64 string `json:"thumbnail_base64,omitempty"`
 }
 
 type MediaRequest struct {
-	Number      string `json:"number" validate:"required"`
-	Caption     string `json:"caption"`
-	MediaURL    string `json:"media_url" validate:"required_without=MediaBase64"`
-	MediaBase64 string `json:"media_base64" validate:"required_without=MediaURL"`
-	MimeType    string `json:"mime_type"`
+	Number          string     `json:"number" validate:"required"`
+	Caption         string     `json:"caption" validate:"excluded_with=Mode"`
+	MediaURL        string     `json:"media_url" validate:"required_without_all=MediaBase64 MediaID FromFile,excluded_with=MediaID,omitempty,url"`
+	MediaBase64     string     `json:"media_base64" validate:"required_without_all=MediaURL MediaID FromFile,excluded_with=MediaID"`
+	MediaID         string     `json:"media_id,omitempty"` // mídia pré-enviada em POST /media
+	MimeType        string     `json:"mime_type" validate:"required_with=MediaBase64,excluded_with=MediaID,mime"`
+	FileName        string     `json:"filename,omitempty"`
+	Mentions        []string   `json:"mentions,omitempty"`
+	QuotedMessageID string     `json:"quoted_message_id,omitempty"`
+	Mode            string     `json:"mode,omitempty" validate:"excluded_with=MediaID,omitempty,oneof=voice sticker"` // vazio deduz pelo mime_type
+	ThumbnailBase64 string     `json:"thumbnail_base64,omitempty" validate:"excluded_with=MediaID Mode"`
+	Async           bool       `json:"async,omitempty"`
+	SendAt          *time.Time `json:"send_at,omitempty"` // agenda o envio (RFC3339 com fuso)
+	// FromFile marca o envio multipart, em que a mídia vem na parte "file"
+	// no lugar de media_url, media_base64 ou media_id.
+	FromFile bool `json:"-"`
 }
 
+// Modos de envio de mídia.
+const (
+	MediaModeVoice   = "voice"
+	MediaModeSticker = "sticker"
+)
+
 type APIResponse struct {
 	Status    string      `json:"status"`
 	Message   string      `json:"message"`
@@ -81,10 +128,12 @@ type HealthResponse struct {
 }
 
 type MessageSent struct {
-	MessageID string    `json:"message_id,omitempty"`
-	Recipient string    `json:"recipient"`
-	Type      string    `json:"type"`
-	SentAt    time.Time `json:"sent_at"`
+	MessageID    string    `json:"message_id,omitempty"`
+	Recipient    string    `json:"recipient"`
+	RecipientJID string    `json:"recipient_jid,omitempty"`
+	Type         string    `json:"type"`
+	Status       string    `json:"status,omitempty"`
+	SentAt       time.Time `json:"sent_at"`
 }
 
 func NewSuccessResponse(message string, data interface{}) *APIResponse {
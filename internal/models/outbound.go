@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OutboundJobQueued     = "queued"
+	OutboundJobProcessing = "processing"
+	OutboundJobSent       = "sent"
+	OutboundJobFailed     = "failed"
+	OutboundJobCanceled   = "canceled"
+)
+
+const (
+	OutboundKindText     = "text"
+	OutboundKindMedia    = "media"
+	OutboundKindLocation = "location"
+	OutboundKindContacts = "contacts"
+	OutboundKindPoll     = "poll"
+)
+
+type OutboundJob struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	SessionID     uuid.UUID       `json:"session_id" db:"session_id"`
+	TenantID      string          `json:"tenant_id" db:"tenant_id"`
+	SessionKey    string          `json:"session_key" db:"session_key"`
+	Kind          string          `json:"kind" db:"kind"`
+	Recipient     string          `json:"recipient" db:"recipient"`
+	Payload       json.RawMessage `json:"-" db:"payload"`
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"`
+	MessageID     *string         `json:"message_id,omitempty" db:"message_id"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	ScheduledAt   *time.Time      `json:"scheduled_at,omitempty" db:"scheduled_at"`
+}
+
+type OutboundJobAccepted struct {
+	JobID       uuid.UUID  `json:"job_id"`
+	Status      string     `json:"status"`
+	Recipient   string     `json:"recipient"`
+	Type        string     `json:"type"`
+	QueuedAt    time.Time  `json:"queued_at"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// ScheduledFilter filtra os envios agendados de uma sessão. Status vazio
+// lista os ainda pendentes (queued ou processing).
+type ScheduledFilter struct {
+	Status string
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
+
+type RescheduleRequest struct {
+	SendAt *time.Time `json:"send_at" validate:"required"`
+}
+
+type QueueDepth struct {
+	SessionKey     string     `json:"session_key"`
+	Queued         int        `json:"queued"`
+	Processing     int        `json:"processing"`
+	Sent           int        `json:"sent"`
+	Failed         int        `json:"failed"`
+	Canceled       int        `json:"canceled"`
+	Scheduled      int        `json:"scheduled"`
+	OldestQueuedAt *time.Time `json:"oldest_queued_at,omitempty"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LocationRequest struct {
+	Number          string   `json:"number" validate:"required"`
+	Latitude        *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude       *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	Name            string   `json:"name,omitempty"`
+	Address         string   `json:"address,omitempty"`
+	QuotedMessageID string   `json:"quoted_message_id,omitempty"`
+	Async           bool     `json:"async,omitempty"`
+}
+
+type ContactPhone struct {
+	Number string `json:"number" validate:"required"`
+	// Type segue o vCard: CELL (padrão), WORK, HOME, MAIN.
+	Type string `json:"type,omitempty"`
+}
+
+// ContactCard é renderizado como vCard 3.0; números de telefone ganham o
+// parâmetro waid para que o WhatsApp ofereça "Conversar".
+type ContactCard struct {
+	FullName     string         `json:"full_name" validate:"required"`
+	FirstName    string         `json:"first_name,omitempty"`
+	LastName     string         `json:"last_name,omitempty"`
+	Organization string         `json:"organization,omitempty"`
+	Title        string         `json:"title,omitempty"`
+	Phones       []ContactPhone `json:"phones" validate:"required"`
+	Emails       []string       `json:"emails,omitempty" validate:"dive,email"`
+	URL          string         `json:"url,omitempty" validate:"omitempty,url"`
+}
+
+type ContactsRequest struct {
+	Number          string        `json:"number" validate:"required"`
+	Contacts        []ContactCard `json:"contacts" validate:"required,max=50"`
+	QuotedMessageID string        `json:"quoted_message_id,omitempty"`
+	Async           bool          `json:"async,omitempty"`
+}
+
+type PollRequest struct {
+	Number   string   `json:"number" validate:"required"`
+	Question string   `json:"question" validate:"required,max=255"`
+	Options  []string `json:"options" validate:"min=2,max=12,dive,required,max=100"`
+	// SelectableCount limita quantas opções cada participante marca; nil
+	// equivale a 1 e 0 permite qualquer quantidade.
+	SelectableCount *int   `json:"selectable_count,omitempty"`
+	QuotedMessageID string `json:"quoted_message_id,omitempty"`
+	Async           bool   `json:"async,omitempty"`
+}
+
+type Poll struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	SessionID       uuid.UUID `json:"session_id" db:"session_id"`
+	TenantID        string    `json:"tenant_id" db:"tenant_id"`
+	MessageID       string    `json:"message_id" db:"message_id"`
+	ChatJID         string    `json:"chat_jid" db:"chat_jid"`
+	CreatorJID      string    `json:"creator_jid" db:"creator_jid"`
+	Question        string    `json:"question" db:"question"`
+	Options         []string  `json:"options" db:"options"`
+	SelectableCount int       `json:"selectable_count" db:"selectable_count"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type PollVote struct {
+	PollID          uuid.UUID `json:"-" db:"poll_id"`
+	VoterJID        string    `json:"voter_jid" db:"voter_jid"`
+	SelectedOptions []string  `json:"selected_options" db:"selected_options"`
+	VotedAt         time.Time `json:"voted_at" db:"voted_at"`
+}
+
+type PollOptionTally struct {
+	Name   string   `json:"name"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters"`
+}
+
+type PollResults struct {
+	MessageID       string            `json:"message_id"`
+	ChatJID         string            `json:"chat_jid"`
+	Question        string            `json:"question"`
+	SelectableCount int               `json:"selectable_count"`
+	TotalVoters     int               `json:"total_voters"`
+	Options         []PollOptionTally `json:"options"`
+	Votes           []PollVote        `json:"votes"`
+}
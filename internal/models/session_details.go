@@ -0,0 +1,25 @@
+package models
+
+type RateLimitBucket struct {
+	PerMinute int `json:"per_minute"`
+	Burst     int `json:"burst"`
+	Available int `json:"available"`
+	// RetryAfterMs é a espera até o próximo token quando Available é zero.
+	RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
+}
+
+type RateLimitState struct {
+	Session       *RateLimitBucket `json:"session,omitempty"`
+	NewRecipients *RateLimitBucket `json:"new_recipients,omitempty"`
+	Tenant        *RateLimitBucket `json:"tenant,omitempty"`
+	// Buckets de consulta de números (POST /contacts/check), em números.
+	ContactChecks       *RateLimitBucket `json:"contact_checks,omitempty"`
+	TenantContactChecks *RateLimitBucket `json:"tenant_contact_checks,omitempty"`
+}
+
+type SessionDetails struct {
+	*WhatsAppSession
+	Connected bool            `json:"connected"`
+	RateLimit *RateLimitState `json:"rate_limit"`
+	Queue     *QueueDepth     `json:"queue,omitempty"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateMedia é a mídia opcional do template; o corpo renderizado vira a
+// legenda. Aceita media_id (recomendado) ou media_url.
+type TemplateMedia struct {
+	MediaURL string `json:"media_url,omitempty" validate:"required_without=MediaID,excluded_with=MediaID,url"`
+	MediaID  string `json:"media_id,omitempty"`
+	MimeType string `json:"mime_type,omitempty" validate:"excluded_with=MediaID,omitempty,mime"`
+	FileName string `json:"filename,omitempty"`
+	Mode     string `json:"mode,omitempty" validate:"excluded_with=MediaID,omitempty,oneof=voice sticker"`
+}
+
+// TemplateRequest cria um template ou, no PUT, grava uma nova versão dele.
+// O corpo aceita variáveis {{nome}} e a formatação do WhatsApp (*negrito*,
+// _itálico_, ~tachado~, ```monoespaçado```). Nome e idioma identificam o
+// template e não mudam entre versões.
+type TemplateRequest struct {
+	Name     string         `json:"name" validate:"max=100"`
+	Language string         `json:"language"`
+	Body     string         `json:"body" validate:"max=4096"`
+	Media    *TemplateMedia `json:"media,omitempty"`
+}
+
+type MessageTemplate struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	TenantID  string         `json:"-" db:"tenant_id"`
+	Name      string         `json:"name" db:"name"`
+	Language  string         `json:"language" db:"language"`
+	Version   int            `json:"version" db:"current_version"`
+	Body      string         `json:"body" db:"body"`
+	Media     *TemplateMedia `json:"media,omitempty" db:"media"`
+	Variables []string       `json:"variables" db:"variables"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// TemplateVersion é uma versão gravada do template. Versões não são
+// alteradas: mensagens enviadas apontam para a versão usada.
+type TemplateVersion struct {
+	Version   int            `json:"version" db:"version"`
+	Body      string         `json:"body" db:"body"`
+	Media     *TemplateMedia `json:"media,omitempty" db:"media"`
+	Variables []string       `json:"variables" db:"variables"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+type TemplateFilter struct {
+	TenantID string
+	Name     string
+	Language string
+	Limit    int
+	Offset   int
+}
+
+// TemplateRenderRequest preenche as variáveis do template. Sem version,
+// usa a versão atual.
+type TemplateRenderRequest struct {
+	Variables map[string]string `json:"variables,omitempty"`
+	Version   int               `json:"version,omitempty"`
+}
+
+// TemplateSendRequest envia um template, identificado por template_id ou
+// por name + language. Sem language, vale o único idioma cadastrado para o
+// nome; um idioma regional ausente (pt_BR) cai para o idioma base (pt).
+type TemplateSendRequest struct {
+	TemplateID      string            `json:"template_id,omitempty" validate:"required_without=Name"`
+	Name            string            `json:"name,omitempty" validate:"required_without=TemplateID"`
+	Language        string            `json:"language,omitempty"`
+	Version         int               `json:"version,omitempty"`
+	Number          string            `json:"number" validate:"required"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	Mentions        []string          `json:"mentions,omitempty"`
+	QuotedMessageID string            `json:"quoted_message_id,omitempty"`
+	LinkPreview     *bool             `json:"link_preview,omitempty"`
+	Async           bool              `json:"async,omitempty"`
+	SendAt          *time.Time        `json:"send_at,omitempty"`
+}
+
+// TemplateUsage identifica a versão de template usada num envio.
+type TemplateUsage struct {
+	TemplateID uuid.UUID `json:"template_id"`
+	Version    int       `json:"template_version"`
+}
+
+type TemplateRendered struct {
+	TemplateUsage
+	Name     string         `json:"name"`
+	Language string         `json:"language"`
+	Text     string         `json:"text"`
+	Media    *TemplateMedia `json:"media,omitempty"`
+}
+
+type TemplateSent struct {
+	*MessageSent
+	TemplateUsage
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TenantStatusActive   = "active"
+	TenantStatusDisabled = "disabled"
+)
+
+type Tenant struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TenantAPIKey guarda apenas o hash da chave; o valor em claro é exibido uma
+// única vez, na criação ou rotação.
+type TenantAPIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TenantID   string     `json:"tenant_id" db:"tenant_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"key_prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type CreateTenantRequest struct {
+	ID   string `json:"id" validate:"required,max=255"`
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type UpdateTenantRequest struct {
+	Status string `json:"status" validate:"required,oneof=active disabled"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"max=255"`
+}
+
+type APIKeyCreatedResponse struct {
+	*TenantAPIKey
+	Key string `json:"key"`
+}
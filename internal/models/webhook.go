@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	SessionID      uuid.UUID       `json:"session_id" db:"session_id"`
+	TenantID       string          `json:"tenant_id" db:"tenant_id"`
+	EventID        string          `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+type WebhookConfigRequest struct {
+	URL    string `json:"url" validate:"required,url"`
+	Secret string `json:"secret"`
+}
+
+type WebhookConfigResponse struct {
+	SessionKey string `json:"session_key"`
+	URL        string `json:"url"`
+	Secret     string `json:"secret,omitempty"`
+	Enabled    bool   `json:"enabled"`
+}
@@ -0,0 +1,489 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type CampaignRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewCampaignRepository(db *sql.DB, log *logger.Logger) *CampaignRepository {
+	return &CampaignRepository{db: db, logger: log}
+}
+
+var ErrCampaignNotFound = errors.New("campanha não encontrada")
+
+const campaignSelectCols = `
+	id, tenant_id, session_id, session_key, name, kind, message, status, starts_at, ends_at,
+	window_start, window_end, timezone, per_minute, last_error, created_at, updated_at,
+	started_at, completed_at
+`
+
+func scanCampaign(scanner interface{ Scan(dest ...any) error }) (*models.Campaign, error) {
+	c := &models.Campaign{}
+	var message string
+	if err := scanner.Scan(
+		&c.ID,
+		&c.TenantID,
+		&c.SessionID,
+		&c.SessionKey,
+		&c.Name,
+		&c.Kind,
+		&message,
+		&c.Status,
+		&c.StartsAt,
+		&c.EndsAt,
+		&c.WindowStart,
+		&c.WindowEnd,
+		&c.Timezone,
+		&c.PerMinute,
+		&c.LastError,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.StartedAt,
+		&c.CompletedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(message), &c.Message); err != nil {
+		return nil, fmt.Errorf("mensagem da campanha %s corrompida: %w", c.ID, err)
+	}
+	return c, nil
+}
+
+func (r *CampaignRepository) Create(c *models.Campaign) error {
+	message, err := json.Marshal(c.Message)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar mensagem da campanha: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO campaigns (
+			id, tenant_id, session_id, session_key, name, kind, message, status, starts_at, ends_at,
+			window_start, window_end, timezone, per_minute, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		c.ID, c.TenantID, c.SessionID, c.SessionKey, c.Name, c.Kind, string(message), c.Status, c.StartsAt, c.EndsAt,
+		c.WindowStart, c.WindowEnd, c.Timezone, c.PerMinute, c.CreatedAt, c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao criar campanha: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) Get(id uuid.UUID) (*models.Campaign, error) {
+	c, err := scanCampaign(r.db.QueryRow(`SELECT `+campaignSelectCols+` FROM campaigns WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar campanha: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CampaignRepository) GetByIDAndTenant(id uuid.UUID, tenantID string) (*models.Campaign, error) {
+	c, err := scanCampaign(r.db.QueryRow(`SELECT `+campaignSelectCols+` FROM campaigns WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar campanha: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CampaignRepository) ListByTenant(tenantID string) ([]*models.Campaign, error) {
+	return r.list(`SELECT `+campaignSelectCols+` FROM campaigns WHERE tenant_id = $1 ORDER BY created_at DESC`, tenantID)
+}
+
+func (r *CampaignRepository) ListByStatus(status string) ([]*models.Campaign, error) {
+	return r.list(`SELECT `+campaignSelectCols+` FROM campaigns WHERE status = $1 ORDER BY created_at ASC`, status)
+}
+
+func (r *CampaignRepository) list(query string, args ...any) ([]*models.Campaign, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar campanhas: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	campaigns := make([]*models.Campaign, 0)
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear campanha: %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar campanhas: %w", err)
+	}
+	return campaigns, nil
+}
+
+// Transition muda o status da campanha se o atual for um de from e devolve
+// se a mudança ocorreu. started_at guarda o primeiro início; completed_at é
+// preenchido ao concluir ou cancelar.
+func (r *CampaignRepository) Transition(id uuid.UUID, from []string, to string) (bool, error) {
+	now := time.Now().UTC()
+	args := []any{to, now, id}
+	placeholders := make([]string, len(from))
+	for i, status := range from {
+		args = append(args, status)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+
+	set := `status = $1, updated_at = $2`
+	switch to {
+	case models.CampaignStatusRunning:
+		set += `, started_at = COALESCE(started_at, $2), last_error = NULL`
+	case models.CampaignStatusCompleted, models.CampaignStatusCanceled:
+		set += `, completed_at = $2`
+	}
+
+	result, err := r.db.Exec(`UPDATE campaigns SET `+set+` WHERE id = $3 AND status IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return false, fmt.Errorf("falha ao atualizar status da campanha: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao atualizar status da campanha: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Complete conclui a campanha em execução que não tem mais destinatários
+// pendentes. Devolve false se ainda houver envios (ex.: uma lista anexada
+// depois da última consulta).
+func (r *CampaignRepository) Complete(id uuid.UUID) (bool, error) {
+	now := time.Now().UTC()
+	result, err := r.db.Exec(`
+		UPDATE campaigns SET status = $1, updated_at = $2, completed_at = $2
+		WHERE id = $3 AND status = $4 AND NOT EXISTS (
+			SELECT 1 FROM campaign_recipients WHERE campaign_id = $3 AND status IN ($5, $6)
+		)`,
+		models.CampaignStatusCompleted, now, id, models.CampaignStatusRunning,
+		models.CampaignRecipientQueued, models.CampaignRecipientProcessing,
+	)
+	if err != nil {
+		return false, fmt.Errorf("falha ao concluir campanha: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao concluir campanha: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Pause pausa a campanha em execução registrando o motivo.
+func (r *CampaignRepository) Pause(id uuid.UUID, reason string) error {
+	_, err := r.db.Exec(`UPDATE campaigns SET status = $1, last_error = $2, updated_at = $3 WHERE id = $4 AND status = $5`,
+		models.CampaignStatusPaused, reason, time.Now().UTC(), id, models.CampaignStatusRunning)
+	if err != nil {
+		return fmt.Errorf("falha ao pausar campanha: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) SetLastError(id uuid.UUID, lastError string) error {
+	_, err := r.db.Exec(`UPDATE campaigns SET last_error = $1, updated_at = $2 WHERE id = $3`, lastError, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar erro da campanha: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) UpdateMessage(id uuid.UUID, msg models.CampaignMessage) error {
+	message, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar mensagem da campanha: %w", err)
+	}
+	if _, err := r.db.Exec(`UPDATE campaigns SET message = $1, updated_at = $2 WHERE id = $3`, string(message), time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("falha ao atualizar mensagem da campanha: %w", err)
+	}
+	return nil
+}
+
+// Numbers devolve os números já cadastrados na campanha.
+func (r *CampaignRepository) Numbers(campaignID uuid.UUID) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT number FROM campaign_recipients WHERE campaign_id = $1`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar números da campanha: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	numbers := make(map[string]bool)
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("falha ao escanear número: %w", err)
+		}
+		numbers[number] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar números: %w", err)
+	}
+	return numbers, nil
+}
+
+// AddRecipients anexa os destinatários ao fim da lista, numa transação.
+func (r *CampaignRepository) AddRecipients(campaignID uuid.UUID, recipients []models.CampaignRecipientInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var last int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM campaign_recipients WHERE campaign_id = $1`, campaignID).Scan(&last); err != nil {
+		return fmt.Errorf("falha ao consultar destinatários da campanha: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO campaign_recipients (campaign_id, position, number, variables, status, next_attempt_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)`)
+	if err != nil {
+		return fmt.Errorf("falha ao preparar inserção de destinatários: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	now := time.Now().UTC()
+	for i, rec := range recipients {
+		vars, err := json.Marshal(rec.Variables)
+		if err != nil {
+			return fmt.Errorf("falha ao serializar variáveis: %w", err)
+		}
+		if _, err := stmt.Exec(campaignID, last+i+1, rec.Number, string(vars), models.CampaignRecipientQueued, now); err != nil {
+			return fmt.Errorf("falha ao inserir destinatário: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE campaigns SET updated_at = $1 WHERE id = $2`, now, campaignID); err != nil {
+		return fmt.Errorf("falha ao atualizar campanha: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao gravar destinatários: %w", err)
+	}
+	return nil
+}
+
+// recipientReportStatus combina o status do envio com os recibos da
+// mensagem: destinatários enviados avançam para delivered ou read.
+const recipientReportStatus = `CASE
+		WHEN r.status = 'sent' AND m.status IN ('read', 'played') THEN 'read'
+		WHEN r.status = 'sent' AND m.status = 'delivered' THEN 'delivered'
+		ELSE r.status END`
+
+// recipientJoin espera a sessão em $1 e a campanha em $2: no SQLite os
+// parâmetros são numerados pela ordem em que aparecem na consulta.
+const recipientJoin = `FROM campaign_recipients r
+	LEFT JOIN messages m ON m.session_id = $1 AND m.message_id = r.message_id`
+
+// Stats conta os destinatários pelo status de relatório.
+func (r *CampaignRepository) Stats(campaignID, sessionID uuid.UUID) (*models.CampaignStats, error) {
+	rows, err := r.db.Query(`SELECT `+recipientReportStatus+` AS report_status, COUNT(*) `+recipientJoin+`
+		WHERE r.campaign_id = $2 GROUP BY report_status`, sessionID, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao contar destinatários: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	stats := &models.CampaignStats{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("falha ao escanear contagem: %w", err)
+		}
+		stats.Total += count
+		switch status {
+		case models.CampaignRecipientQueued:
+			stats.Queued = count
+		case models.CampaignRecipientProcessing:
+			stats.Processing = count
+		case models.CampaignRecipientSent:
+			stats.Sent = count
+		case models.CampaignRecipientDelivered:
+			stats.Delivered = count
+		case models.CampaignRecipientRead:
+			stats.Read = count
+		case models.CampaignRecipientFailed:
+			stats.Failed = count
+		case models.CampaignRecipientCanceled:
+			stats.Canceled = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar contagem: %w", err)
+	}
+	return stats, nil
+}
+
+// ListRecipients lista os destinatários em ordem de upload com o status de
+// relatório. Limit 0 devolve todos (exportação).
+func (r *CampaignRepository) ListRecipients(campaignID, sessionID uuid.UUID, f models.CampaignRecipientFilter) ([]*models.CampaignRecipient, error) {
+	query := `SELECT r.position, r.number, r.variables, ` + recipientReportStatus + `, r.attempts,
+			r.message_id, r.last_error, r.sent_at, m.delivered_at, m.read_at, m.played_at, r.updated_at
+		` + recipientJoin + `
+		WHERE r.campaign_id = $2`
+	args := []any{sessionID, campaignID}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		query += fmt.Sprintf(` AND `+recipientReportStatus+` = $%d`, len(args))
+	}
+	query += ` ORDER BY r.position ASC`
+	if f.Limit > 0 {
+		args = append(args, f.Limit, f.Offset)
+		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar destinatários: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	recipients := make([]*models.CampaignRecipient, 0)
+	for rows.Next() {
+		rec := &models.CampaignRecipient{CampaignID: campaignID}
+		var vars string
+		var playedAt *time.Time
+		if err := rows.Scan(
+			&rec.Position,
+			&rec.Number,
+			&vars,
+			&rec.Status,
+			&rec.Attempts,
+			&rec.MessageID,
+			&rec.LastError,
+			&rec.SentAt,
+			&rec.DeliveredAt,
+			&rec.ReadAt,
+			&playedAt,
+			&rec.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("falha ao escanear destinatário: %w", err)
+		}
+		if rec.ReadAt == nil {
+			// Áudios reproduzidos contam como lidos no relatório.
+			rec.ReadAt = playedAt
+		}
+		if err := json.Unmarshal([]byte(vars), &rec.Variables); err != nil {
+			return nil, fmt.Errorf("variáveis do destinatário %d corrompidas: %w", rec.Position, err)
+		}
+		recipients = append(recipients, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar destinatários: %w", err)
+	}
+	return recipients, nil
+}
+
+// NextRecipient devolve o próximo destinatário pendente: o de tentativa mais
+// próxima e, entre eles, o primeiro da lista. Assim um destinatário em
+// backoff não segura os demais. Devolve nil quando não há pendentes.
+func (r *CampaignRepository) NextRecipient(campaignID uuid.UUID) (*models.CampaignRecipient, error) {
+	rec := &models.CampaignRecipient{CampaignID: campaignID}
+	var vars string
+	err := r.db.QueryRow(`
+		SELECT position, number, variables, status, attempts, next_attempt_at, updated_at
+		FROM campaign_recipients
+		WHERE campaign_id = $1 AND status IN ($2, $3)
+		ORDER BY next_attempt_at ASC, position ASC
+		LIMIT 1`,
+		campaignID, models.CampaignRecipientQueued, models.CampaignRecipientProcessing,
+	).Scan(&rec.Position, &rec.Number, &vars, &rec.Status, &rec.Attempts, &rec.NextAttemptAt, &rec.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar próximo destinatário: %w", err)
+	}
+	if err := json.Unmarshal([]byte(vars), &rec.Variables); err != nil {
+		return nil, fmt.Errorf("variáveis do destinatário %d corrompidas: %w", rec.Position, err)
+	}
+	return rec, nil
+}
+
+func (r *CampaignRepository) MarkRecipientProcessing(campaignID uuid.UUID, position int) error {
+	_, err := r.db.Exec(`UPDATE campaign_recipients SET status = $1, updated_at = $2 WHERE campaign_id = $3 AND position = $4`,
+		models.CampaignRecipientProcessing, time.Now().UTC(), campaignID, position)
+	if err != nil {
+		return fmt.Errorf("falha ao marcar destinatário em processamento: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) MarkRecipientSent(campaignID uuid.UUID, position, attempts int, messageID string) error {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(`
+		UPDATE campaign_recipients
+		SET status = $1, attempts = $2, message_id = $3, last_error = NULL, sent_at = $4, updated_at = $4
+		WHERE campaign_id = $5 AND position = $6`,
+		models.CampaignRecipientSent, attempts, messageID, now, campaignID, position)
+	if err != nil {
+		return fmt.Errorf("falha ao marcar destinatário como enviado: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) MarkRecipientRetry(campaignID uuid.UUID, position, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE campaign_recipients
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, updated_at = $5
+		WHERE campaign_id = $6 AND position = $7`,
+		models.CampaignRecipientQueued, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), campaignID, position)
+	if err != nil {
+		return fmt.Errorf("falha ao reagendar destinatário: %w", err)
+	}
+	return nil
+}
+
+func (r *CampaignRepository) MarkRecipientFailed(campaignID uuid.UUID, position, attempts int, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE campaign_recipients
+		SET status = $1, attempts = $2, last_error = $3, updated_at = $4
+		WHERE campaign_id = $5 AND position = $6`,
+		models.CampaignRecipientFailed, attempts, lastError, time.Now().UTC(), campaignID, position)
+	if err != nil {
+		return fmt.Errorf("falha ao marcar destinatário como falho: %w", err)
+	}
+	return nil
+}
+
+// CancelQueued cancela os destinatários que ainda aguardam envio. Os que
+// estão em envio terminam normalmente.
+func (r *CampaignRepository) CancelQueued(campaignID uuid.UUID, reason string) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE campaign_recipients SET status = $1, last_error = $2, updated_at = $3
+		WHERE campaign_id = $4 AND status = $5`,
+		models.CampaignRecipientCanceled, reason, time.Now().UTC(), campaignID, models.CampaignRecipientQueued)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao cancelar destinatários: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RequeueProcessing devolve para a fila os destinatários que estavam em
+// envio quando o processo parou.
+func (r *CampaignRepository) RequeueProcessing() (int64, error) {
+	result, err := r.db.Exec(`UPDATE campaign_recipients SET status = $1, updated_at = $2 WHERE status = $3`,
+		models.CampaignRecipientQueued, time.Now().UTC(), models.CampaignRecipientProcessing)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao reenfileirar destinatários em processamento: %w", err)
+	}
+	return result.RowsAffected()
+}
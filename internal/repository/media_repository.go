@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type MediaRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewMediaRepository(db *sql.DB, log *logger.Logger) *MediaRepository {
+	return &MediaRepository{db: db, logger: log}
+}
+
+var ErrMediaUploadNotFound = errors.New("mídia não encontrada")
+
+const mediaUploadSelectCols = `
+	id, tenant_id, sha256, media_type, url, direct_path, media_key, file_enc_sha256,
+	file_length, mode, mime_type, file_name, metadata, created_at, expires_at
+`
+
+func scanMediaUpload(scanner interface{ Scan(dest ...any) error }) (*models.MediaUpload, error) {
+	u := &models.MediaUpload{}
+	if err := scanner.Scan(
+		&u.ID,
+		&u.TenantID,
+		&u.SHA256,
+		&u.MediaType,
+		&u.URL,
+		&u.DirectPath,
+		&u.MediaKey,
+		&u.FileEncSHA256,
+		&u.FileLength,
+		&u.Mode,
+		&u.MimeType,
+		&u.FileName,
+		&u.Metadata,
+		&u.CreatedAt,
+		&u.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Save grava o upload do conteúdo. Se o tenant já tinha o mesmo conteúdo,
+// a linha (e o media_id) é mantida e só o upload é renovado; a descrição
+// da mídia (modo, tipo, nome e metadados) só é trocada com replaceDescription,
+// usado pelo pré-upload. Devolve a linha resultante.
+func (r *MediaRepository) Save(u *models.MediaUpload, replaceDescription bool) (*models.MediaUpload, error) {
+	update := `url = excluded.url, direct_path = excluded.direct_path, media_key = excluded.media_key,
+			file_enc_sha256 = excluded.file_enc_sha256, file_length = excluded.file_length,
+			created_at = excluded.created_at, expires_at = excluded.expires_at`
+	if replaceDescription {
+		update += `, mode = excluded.mode, mime_type = excluded.mime_type,
+			file_name = excluded.file_name, metadata = excluded.metadata`
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO media_uploads (
+			id, tenant_id, sha256, media_type, url, direct_path, media_key, file_enc_sha256,
+			file_length, mode, mime_type, file_name, metadata, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (tenant_id, sha256, media_type) DO UPDATE SET `+update,
+		u.ID, u.TenantID, u.SHA256, u.MediaType, u.URL, u.DirectPath, u.MediaKey, u.FileEncSHA256,
+		u.FileLength, u.Mode, u.MimeType, u.FileName, u.Metadata, u.CreatedAt, u.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao registrar upload de mídia: %w", err)
+	}
+	return r.FindByContent(u.TenantID, u.SHA256, u.MediaType)
+}
+
+// FindByContent busca o upload pelo conteúdo, expirado ou não; quem chama
+// confere ExpiresAt.
+func (r *MediaRepository) FindByContent(tenantID, sha256, mediaType string) (*models.MediaUpload, error) {
+	u, err := scanMediaUpload(r.db.QueryRow(`
+		SELECT `+mediaUploadSelectCols+`
+		FROM media_uploads WHERE tenant_id = $1 AND sha256 = $2 AND media_type = $3`,
+		tenantID, sha256, mediaType,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMediaUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar upload de mídia: %w", err)
+	}
+	return u, nil
+}
+
+func (r *MediaRepository) GetByIDAndTenant(id uuid.UUID, tenantID string) (*models.MediaUpload, error) {
+	u, err := scanMediaUpload(r.db.QueryRow(`
+		SELECT `+mediaUploadSelectCols+`
+		FROM media_uploads WHERE id = $1 AND tenant_id = $2`, id, tenantID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMediaUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar mídia: %w", err)
+	}
+	return u, nil
+}
+
+// DeleteExpired remove os uploads do tenant vencidos antes de before.
+func (r *MediaRepository) DeleteExpired(tenantID string, before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM media_uploads WHERE tenant_id = $1 AND expires_at < $2`, tenantID, before)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao remover uploads expirados: %w", err)
+	}
+	return result.RowsAffected()
+}
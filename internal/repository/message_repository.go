@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type MessageRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewMessageRepository(db *sql.DB, log *logger.Logger) *MessageRepository {
+	return &MessageRepository{db: db, logger: log}
+}
+
+var ErrMessageNotFound = errors.New("mensagem não encontrada")
+
+const messageSelectCols = `
+	id, message_id, session_id, tenant_id, direction, recipient_jid, sender_jid, type, payload_summary,
+	status, server_timestamp, delivered_at, read_at, played_at, edited_at, revoked_at, created_at, updated_at,
+	template_id, template_version
+`
+
+// messageStatusRank permite avançar o status apenas para frente
+// (sent → delivered → read → played), já que recibos podem chegar fora de ordem.
+const messageStatusRank = `
+	CASE status WHEN 'sent' THEN 1 WHEN 'delivered' THEN 2 WHEN 'read' THEN 3 WHEN 'played' THEN 4 ELSE 0 END
+`
+
+var messageStatusRanks = map[string]int{
+	models.MessageStatusSent:      1,
+	models.MessageStatusDelivered: 2,
+	models.MessageStatusRead:      3,
+	models.MessageStatusPlayed:    4,
+}
+
+func nullIfEmpty(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+func scanMessage(scanner interface{ Scan(dest ...any) error }) (*models.Message, error) {
+	m := &models.Message{}
+	var summary, sender sql.NullString
+	if err := scanner.Scan(
+		&m.ID,
+		&m.MessageID,
+		&m.SessionID,
+		&m.TenantID,
+		&m.Direction,
+		&m.RecipientJID,
+		&sender,
+		&m.Type,
+		&summary,
+		&m.Status,
+		&m.ServerTimestamp,
+		&m.DeliveredAt,
+		&m.ReadAt,
+		&m.PlayedAt,
+		&m.EditedAt,
+		&m.RevokedAt,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+		&m.TemplateID,
+		&m.TemplateVersion,
+	); err != nil {
+		return nil, err
+	}
+	m.PayloadSummary = summary.String
+	m.SenderJID = sender.String
+	return m, nil
+}
+
+// Create registra a mensagem. Mensagens já registradas para a sessão (ex.:
+// reentregas do WhatsApp) são ignoradas.
+func (r *MessageRepository) Create(m *models.Message) error {
+	query := `
+		INSERT INTO messages (
+			id, message_id, session_id, tenant_id, direction, recipient_jid, sender_jid, type,
+			payload_summary, status, server_timestamp, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (session_id, message_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(query,
+		m.ID,
+		m.MessageID,
+		m.SessionID,
+		m.TenantID,
+		m.Direction,
+		m.RecipientJID,
+		nullIfEmpty(m.SenderJID),
+		m.Type,
+		m.PayloadSummary,
+		m.Status,
+		m.ServerTimestamp,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar mensagem: %w", err)
+	}
+	return nil
+}
+
+func (r *MessageRepository) GetByMessageID(tenantID, messageID string) (*models.Message, error) {
+	query := `SELECT ` + messageSelectCols + ` FROM messages
+		WHERE tenant_id = $1 AND message_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	m, err := scanMessage(r.db.QueryRow(query, tenantID, messageID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar mensagem: %w", err)
+	}
+	return m, nil
+}
+
+func (r *MessageRepository) GetBySession(sessionID uuid.UUID, messageID string) (*models.Message, error) {
+	query := `SELECT ` + messageSelectCols + ` FROM messages WHERE session_id = $1 AND message_id = $2`
+
+	m, err := scanMessage(r.db.QueryRow(query, sessionID, messageID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar mensagem: %w", err)
+	}
+	return m, nil
+}
+
+func (r *MessageRepository) List(f models.MessageFilter) ([]*models.Message, error) {
+	conds := []string{"tenant_id = $1"}
+	args := []any{f.TenantID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.SessionID != nil {
+		add("session_id = $%d", *f.SessionID)
+	}
+	if f.RecipientJID != "" {
+		add("recipient_jid = $%d", f.RecipientJID)
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.Type != "" {
+		add("type = $%d", f.Type)
+	}
+	if f.Direction != "" {
+		add("direction = $%d", f.Direction)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("created_at <= $%d", *f.Until)
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := `SELECT ` + messageSelectCols + ` FROM messages WHERE ` + strings.Join(conds, " AND ") +
+		fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar mensagens: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	messages := make([]*models.Message, 0)
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear mensagem: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar mensagens: %w", err)
+	}
+
+	return messages, nil
+}
+
+// AdvanceStatus aplica um recibo às mensagens indicadas, ignorando as que já
+// estão em um status igual ou mais avançado.
+func (r *MessageRepository) AdvanceStatus(sessionID uuid.UUID, messageIDs []string, status string, at time.Time) (int64, error) {
+	rank, ok := messageStatusRanks[status]
+	if !ok || len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	set := "status = $1, updated_at = $2"
+	switch status {
+	case models.MessageStatusDelivered:
+		set += ", delivered_at = COALESCE(delivered_at, $2)"
+	case models.MessageStatusRead:
+		set += ", delivered_at = COALESCE(delivered_at, $2), read_at = COALESCE(read_at, $2)"
+	case models.MessageStatusPlayed:
+		set += ", delivered_at = COALESCE(delivered_at, $2), read_at = COALESCE(read_at, $2), played_at = COALESCE(played_at, $2)"
+	}
+
+	args := []any{status, at, sessionID, rank}
+	placeholders := make([]string, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	query := `UPDATE messages SET ` + set + `
+		WHERE session_id = $3 AND (` + messageStatusRank + `) < $4
+		AND message_id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao atualizar status de mensagens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// HasRecipient informa se a sessão já trocou mensagens com o destinatário.
+func (r *MessageRepository) HasRecipient(sessionID uuid.UUID, recipientJID string) (bool, error) {
+	var one int
+	err := r.db.QueryRow(`SELECT 1 FROM messages WHERE session_id = $1 AND recipient_jid = $2 LIMIT 1`, sessionID, recipientJID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("falha ao consultar histórico do destinatário: %w", err)
+	}
+	return true, nil
+}
+
+// MarkEdited substitui o resumo pelo novo conteúdo da mensagem editada.
+func (r *MessageRepository) MarkEdited(sessionID uuid.UUID, messageID, summary string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE messages SET payload_summary = $1, edited_at = $2, updated_at = $2
+		WHERE session_id = $3 AND message_id = $4`, summary, at, sessionID, messageID)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar edição da mensagem: %w", err)
+	}
+	return nil
+}
+
+// SetTemplate registra a versão de template usada na mensagem enviada.
+func (r *MessageRepository) SetTemplate(sessionID uuid.UUID, messageID string, templateID uuid.UUID, version int) error {
+	_, err := r.db.Exec(`UPDATE messages SET template_id = $1, template_version = $2
+		WHERE session_id = $3 AND message_id = $4`, templateID, version, sessionID, messageID)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar template da mensagem: %w", err)
+	}
+	return nil
+}
+
+func (r *MessageRepository) MarkRevoked(sessionID uuid.UUID, messageID string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE messages SET revoked_at = COALESCE(revoked_at, $1), updated_at = $1
+		WHERE session_id = $2 AND message_id = $3`, at, sessionID, messageID)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar revogação da mensagem: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type OutboundRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewOutboundRepository(db *sql.DB, log *logger.Logger) *OutboundRepository {
+	return &OutboundRepository{db: db, logger: log}
+}
+
+var ErrOutboundJobNotFound = errors.New("job de envio não encontrado")
+
+const outboundJobSelectCols = `
+	id, session_id, tenant_id, session_key, kind, recipient, payload, status, attempts,
+	next_attempt_at, last_error, message_id, created_at, updated_at, completed_at, scheduled_at
+`
+
+func scanOutboundJob(scanner interface{ Scan(dest ...any) error }) (*models.OutboundJob, error) {
+	j := &models.OutboundJob{}
+	var payload string
+	if err := scanner.Scan(
+		&j.ID,
+		&j.SessionID,
+		&j.TenantID,
+		&j.SessionKey,
+		&j.Kind,
+		&j.Recipient,
+		&payload,
+		&j.Status,
+		&j.Attempts,
+		&j.NextAttemptAt,
+		&j.LastError,
+		&j.MessageID,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+		&j.CompletedAt,
+		&j.ScheduledAt,
+	); err != nil {
+		return nil, err
+	}
+	j.Payload = []byte(payload)
+	return j, nil
+}
+
+func (r *OutboundRepository) Create(j *models.OutboundJob) error {
+	query := `
+		INSERT INTO outbound_jobs (
+			id, session_id, tenant_id, session_key, kind, recipient, payload, status,
+			attempts, next_attempt_at, created_at, updated_at, scheduled_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.Exec(query,
+		j.ID,
+		j.SessionID,
+		j.TenantID,
+		j.SessionKey,
+		j.Kind,
+		j.Recipient,
+		string(j.Payload),
+		j.Status,
+		j.Attempts,
+		j.NextAttemptAt,
+		j.CreatedAt,
+		j.UpdatedAt,
+		j.ScheduledAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao enfileirar envio: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboundRepository) GetByTenant(id uuid.UUID, tenantID string) (*models.OutboundJob, error) {
+	query := `SELECT ` + outboundJobSelectCols + ` FROM outbound_jobs WHERE id = $1 AND tenant_id = $2`
+
+	j, err := scanOutboundJob(r.db.QueryRow(query, id, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOutboundJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar job de envio: %w", err)
+	}
+	return j, nil
+}
+
+// NextForSession devolve o job mais antigo ainda não concluído da sessão, ou
+// nil se a fila estiver vazia. Jobs agendados entram na ordem pelo horário
+// agendado, não pela criação. O worker respeita essa ordem mesmo quando o job
+// da frente está aguardando retry.
+func (r *OutboundRepository) NextForSession(sessionID uuid.UUID) (*models.OutboundJob, error) {
+	query := `SELECT ` + outboundJobSelectCols + ` FROM outbound_jobs
+		WHERE session_id = $1 AND status IN ($2, $3)
+		ORDER BY COALESCE(scheduled_at, created_at) ASC, created_at ASC
+		LIMIT 1`
+
+	j, err := scanOutboundJob(r.db.QueryRow(query, sessionID, models.OutboundJobQueued, models.OutboundJobProcessing))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar próximo job de envio: %w", err)
+	}
+	return j, nil
+}
+
+// MarkProcessing reserva o job para envio. Devolve false se ele deixou de
+// estar pendente (ex.: agendamento cancelado depois de lido pelo worker).
+func (r *OutboundRepository) MarkProcessing(id uuid.UUID) (bool, error) {
+	query := `UPDATE outbound_jobs SET status = $1, updated_at = $2 WHERE id = $3 AND status IN ($4, $5)`
+
+	result, err := r.db.Exec(query, models.OutboundJobProcessing, time.Now().UTC(), id, models.OutboundJobQueued, models.OutboundJobProcessing)
+	if err != nil {
+		return false, fmt.Errorf("falha ao marcar job de envio em processamento: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao marcar job de envio em processamento: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *OutboundRepository) MarkSent(id uuid.UUID, attempts int, messageID string) error {
+	now := time.Now().UTC()
+	query := `
+		UPDATE outbound_jobs
+		SET status = $1, attempts = $2, message_id = $3, last_error = NULL, updated_at = $4, completed_at = $4
+		WHERE id = $5
+	`
+
+	if _, err := r.db.Exec(query, models.OutboundJobSent, attempts, messageID, now, id); err != nil {
+		return fmt.Errorf("falha ao marcar job de envio como enviado: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboundRepository) MarkRetry(id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE outbound_jobs
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	if _, err := r.db.Exec(query, models.OutboundJobQueued, attempts, nextAttemptAt.UTC(), lastError, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("falha ao reagendar job de envio: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboundRepository) MarkFailed(id uuid.UUID, attempts int, lastError string) error {
+	now := time.Now().UTC()
+	query := `
+		UPDATE outbound_jobs
+		SET status = $1, attempts = $2, last_error = $3, updated_at = $4, completed_at = $4
+		WHERE id = $5
+	`
+
+	if _, err := r.db.Exec(query, models.OutboundJobFailed, attempts, lastError, now, id); err != nil {
+		return fmt.Errorf("falha ao marcar job de envio como falho: %w", err)
+	}
+	return nil
+}
+
+// RequeueProcessing devolve para a fila os jobs que estavam em envio quando o
+// processo parou, para que sejam retomados no próximo start.
+func (r *OutboundRepository) RequeueProcessing() (int64, error) {
+	query := `UPDATE outbound_jobs SET status = $1, updated_at = $2 WHERE status = $3`
+
+	result, err := r.db.Exec(query, models.OutboundJobQueued, time.Now().UTC(), models.OutboundJobProcessing)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao reenfileirar jobs em processamento: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// FailOverdueScheduled encerra os agendamentos da sessão que continuam na
+// fila desde antes do limite, usado quando a sessão segue desconectada além
+// da janela de tolerância.
+func (r *OutboundRepository) FailOverdueScheduled(sessionID uuid.UUID, before time.Time, lastError string) (int64, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE outbound_jobs
+		SET status = $1, last_error = $2, updated_at = $3, completed_at = $3
+		WHERE session_id = $4 AND status = $5 AND scheduled_at IS NOT NULL AND scheduled_at < $6
+	`
+
+	result, err := r.db.Exec(query, models.OutboundJobFailed, lastError, now, sessionID, models.OutboundJobQueued, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("falha ao encerrar agendamentos vencidos: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListScheduled lista os envios agendados da sessão pelo horário agendado.
+func (r *OutboundRepository) ListScheduled(sessionID uuid.UUID, f models.ScheduledFilter) ([]*models.OutboundJob, error) {
+	conds := []string{"session_id = $1", "scheduled_at IS NOT NULL"}
+	args := []any{sessionID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	} else {
+		args = append(args, models.OutboundJobQueued, models.OutboundJobProcessing)
+		conds = append(conds, fmt.Sprintf("status IN ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if f.Since != nil {
+		add("scheduled_at >= $%d", f.Since.UTC())
+	}
+	if f.Until != nil {
+		add("scheduled_at <= $%d", f.Until.UTC())
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := `SELECT ` + outboundJobSelectCols + ` FROM outbound_jobs WHERE ` + strings.Join(conds, " AND ") +
+		fmt.Sprintf(` ORDER BY scheduled_at ASC, created_at ASC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar envios agendados: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	jobs := make([]*models.OutboundJob, 0)
+	for rows.Next() {
+		j, err := scanOutboundJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear envio agendado: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar envios agendados: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// GetScheduled busca um envio agendado da sessão.
+func (r *OutboundRepository) GetScheduled(id, sessionID uuid.UUID) (*models.OutboundJob, error) {
+	query := `SELECT ` + outboundJobSelectCols + ` FROM outbound_jobs
+		WHERE id = $1 AND session_id = $2 AND scheduled_at IS NOT NULL`
+
+	j, err := scanOutboundJob(r.db.QueryRow(query, id, sessionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOutboundJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar envio agendado: %w", err)
+	}
+	return j, nil
+}
+
+// CancelScheduled cancela o agendamento se ele ainda aguarda na fila.
+// Devolve false quando o job já está em envio ou concluído.
+func (r *OutboundRepository) CancelScheduled(id, sessionID uuid.UUID) (bool, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE outbound_jobs
+		SET status = $1, updated_at = $2, completed_at = $2
+		WHERE id = $3 AND session_id = $4 AND status = $5 AND scheduled_at IS NOT NULL
+	`
+
+	result, err := r.db.Exec(query, models.OutboundJobCanceled, now, id, sessionID, models.OutboundJobQueued)
+	if err != nil {
+		return false, fmt.Errorf("falha ao cancelar envio agendado: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao cancelar envio agendado: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Reschedule move o agendamento para outro horário se ele ainda aguarda na
+// fila. Devolve false quando o job já está em envio ou concluído.
+func (r *OutboundRepository) Reschedule(id, sessionID uuid.UUID, sendAt time.Time) (bool, error) {
+	query := `
+		UPDATE outbound_jobs
+		SET scheduled_at = $1, next_attempt_at = $1, last_error = NULL, updated_at = $2
+		WHERE id = $3 AND session_id = $4 AND status = $5 AND scheduled_at IS NOT NULL
+	`
+
+	result, err := r.db.Exec(query, sendAt.UTC(), time.Now().UTC(), id, sessionID, models.OutboundJobQueued)
+	if err != nil {
+		return false, fmt.Errorf("falha ao reagendar envio: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao reagendar envio: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *OutboundRepository) SessionsWithPending() ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT session_id FROM outbound_jobs WHERE status IN ($1, $2)`
+
+	rows, err := r.db.Query(query, models.OutboundJobQueued, models.OutboundJobProcessing)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar sessões com envios pendentes: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("falha ao escanear sessão: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar sessões: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *OutboundRepository) DepthBySession(sessionID uuid.UUID) (*models.QueueDepth, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM outbound_jobs WHERE session_id = $1 GROUP BY status`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao contar fila de envio: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	depth := &models.QueueDepth{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("falha ao escanear contagem da fila: %w", err)
+		}
+		switch status {
+		case models.OutboundJobQueued:
+			depth.Queued = count
+		case models.OutboundJobProcessing:
+			depth.Processing = count
+		case models.OutboundJobSent:
+			depth.Sent = count
+		case models.OutboundJobFailed:
+			depth.Failed = count
+		case models.OutboundJobCanceled:
+			depth.Canceled = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar contagem da fila: %w", err)
+	}
+
+	if depth.Queued+depth.Processing > 0 {
+		scheduled := `SELECT COUNT(*) FROM outbound_jobs
+			WHERE session_id = $1 AND status = $2 AND scheduled_at > $3`
+		if err := r.db.QueryRow(scheduled, sessionID, models.OutboundJobQueued, time.Now().UTC()).Scan(&depth.Scheduled); err != nil {
+			return nil, fmt.Errorf("falha ao contar envios agendados: %w", err)
+		}
+
+		var oldest time.Time
+		query := `SELECT created_at FROM outbound_jobs
+			WHERE session_id = $1 AND status IN ($2, $3)
+			ORDER BY created_at ASC LIMIT 1`
+		err := r.db.QueryRow(query, sessionID, models.OutboundJobQueued, models.OutboundJobProcessing).Scan(&oldest)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("falha ao buscar job mais antigo: %w", err)
+		}
+		if err == nil {
+			depth.OldestQueuedAt = &oldest
+		}
+	}
+
+	return depth, nil
+}
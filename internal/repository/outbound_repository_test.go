@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/migrations"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on"
+	db, err := sql.Open(migrations.DriverSQLite, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrations.New(db, migrations.DriverSQLite, logger.New("test", logger.ERROR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// O horário agendado chega com o fuso do cliente e o servidor roda fora de
+// UTC; o job só pode vencer no instante pedido.
+func TestOutboundScheduleRoundTrip(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC-3", -3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	db := openTestDB(t)
+	log := logger.New("test", logger.ERROR)
+	sessions := NewSessionRepository(db, log)
+	repo := NewOutboundRepository(db, log)
+
+	now := time.Now().UTC()
+	session := &models.WhatsAppSession{
+		ID:                 uuid.New(),
+		TenantID:           "t1",
+		WhatsAppSessionKey: "s1",
+		NomePessoa:         "Ana",
+		EmailPessoa:        "ana@example.com",
+		Status:             models.SessionStatusDisconnected,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := sessions.Create(session); err != nil {
+		t.Fatal(err)
+	}
+
+	sendAt := now.Add(time.Hour).In(time.Local)
+	at := sendAt.UTC()
+	job := &models.OutboundJob{
+		ID:            uuid.New(),
+		SessionID:     session.ID,
+		TenantID:      session.TenantID,
+		SessionKey:    session.WhatsAppSessionKey,
+		Kind:          "text",
+		Recipient:     "5511987654321",
+		Payload:       []byte(`{}`),
+		Status:        models.OutboundJobQueued,
+		NextAttemptAt: at,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ScheduledAt:   &at,
+	}
+	if err := repo.Create(job); err != nil {
+		t.Fatal(err)
+	}
+
+	checkPending := func(t *testing.T, sendAt time.Time) {
+		t.Helper()
+		next, err := repo.NextForSession(session.ID)
+		if err != nil || next == nil {
+			t.Fatalf("NextForSession = %v, %v", next, err)
+		}
+		if !next.NextAttemptAt.Equal(sendAt) || next.ScheduledAt == nil || !next.ScheduledAt.Equal(sendAt) {
+			t.Fatalf("next_attempt_at = %s, scheduled_at = %v, esperado %s", next.NextAttemptAt, next.ScheduledAt, sendAt)
+		}
+		if wait := time.Until(next.NextAttemptAt); wait <= 0 || wait > sendAt.Sub(now) {
+			t.Fatalf("job vence em %s, esperado até %s", wait, sendAt.Sub(now))
+		}
+
+		depth, err := repo.DepthBySession(session.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if depth.Scheduled != 1 {
+			t.Errorf("Scheduled = %d, esperado 1", depth.Scheduled)
+		}
+
+		since := now.In(time.Local)
+		until := sendAt.Add(-time.Minute).In(time.Local)
+		jobs, err := repo.ListScheduled(session.ID, models.ScheduledFilter{Since: &since, Until: &until, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) != 0 {
+			t.Errorf("ListScheduled até %s = %d jobs, esperado 0", until, len(jobs))
+		}
+		until = sendAt.Add(time.Minute).In(time.Local)
+		jobs, err = repo.ListScheduled(session.ID, models.ScheduledFilter{Since: &since, Until: &until, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) != 1 {
+			t.Errorf("ListScheduled até %s = %d jobs, esperado 1", until, len(jobs))
+		}
+
+		failed, err := repo.FailOverdueScheduled(session.ID, time.Now(), "vencido")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if failed != 0 {
+			t.Errorf("FailOverdueScheduled encerrou %d jobs ainda no prazo", failed)
+		}
+	}
+
+	t.Run("criado", func(t *testing.T) { checkPending(t, sendAt) })
+
+	t.Run("remarcado", func(t *testing.T) {
+		sendAt := now.Add(2 * time.Hour).In(time.Local)
+		moved, err := repo.Reschedule(job.ID, session.ID, sendAt)
+		if err != nil || !moved {
+			t.Fatalf("Reschedule = %v, %v", moved, err)
+		}
+		checkPending(t, sendAt)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		next := time.Now().Add(time.Minute)
+		if err := repo.MarkRetry(job.ID, 1, next, "falha"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.NextForSession(session.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait := time.Until(got.NextAttemptAt); wait <= 0 {
+			t.Errorf("retry vence em %s, esperado no futuro", wait)
+		}
+	})
+}
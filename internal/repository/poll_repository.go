@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type PollRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPollRepository(db *sql.DB, log *logger.Logger) *PollRepository {
+	return &PollRepository{db: db, logger: log}
+}
+
+var ErrPollNotFound = errors.New("enquete não encontrada")
+
+// Create registra a enquete. Reentregas da mesma mensagem são ignoradas.
+func (r *PollRepository) Create(p *models.Poll) error {
+	options, err := json.Marshal(p.Options)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar opções da enquete: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO polls (
+			id, session_id, tenant_id, message_id, chat_jid, creator_jid,
+			question, options, selectable_count, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (session_id, message_id) DO NOTHING`,
+		p.ID, p.SessionID, p.TenantID, p.MessageID, p.ChatJID, p.CreatorJID,
+		p.Question, string(options), p.SelectableCount, p.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar enquete: %w", err)
+	}
+	return nil
+}
+
+func (r *PollRepository) GetByMessage(sessionID uuid.UUID, messageID string) (*models.Poll, error) {
+	p := &models.Poll{}
+	var options string
+	err := r.db.QueryRow(`
+		SELECT id, session_id, tenant_id, message_id, chat_jid, creator_jid,
+			question, options, selectable_count, created_at
+		FROM polls WHERE session_id = $1 AND message_id = $2`, sessionID, messageID,
+	).Scan(&p.ID, &p.SessionID, &p.TenantID, &p.MessageID, &p.ChatJID, &p.CreatorJID,
+		&p.Question, &options, &p.SelectableCount, &p.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPollNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar enquete: %w", err)
+	}
+	if err := json.Unmarshal([]byte(options), &p.Options); err != nil {
+		return nil, fmt.Errorf("opções da enquete corrompidas: %w", err)
+	}
+	return p, nil
+}
+
+// UpsertVote grava o voto mais recente do participante. Cada atualização do
+// WhatsApp traz a seleção completa, então ela substitui a anterior; votos
+// fora de ordem (mais antigos que o gravado) são descartados.
+func (r *PollRepository) UpsertVote(v *models.PollVote) error {
+	selected, err := json.Marshal(v.SelectedOptions)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar voto: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO poll_votes (poll_id, voter_jid, selected_options, voted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (poll_id, voter_jid) DO UPDATE
+		SET selected_options = excluded.selected_options, voted_at = excluded.voted_at
+		WHERE excluded.voted_at >= poll_votes.voted_at`,
+		v.PollID, v.VoterJID, string(selected), v.VotedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao registrar voto: %w", err)
+	}
+	return nil
+}
+
+func (r *PollRepository) ListVotes(pollID uuid.UUID) ([]models.PollVote, error) {
+	rows, err := r.db.Query(`
+		SELECT poll_id, voter_jid, selected_options, voted_at
+		FROM poll_votes WHERE poll_id = $1 ORDER BY voted_at`, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar votos: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	votes := make([]models.PollVote, 0)
+	for rows.Next() {
+		var v models.PollVote
+		var selected string
+		if err := rows.Scan(&v.PollID, &v.VoterJID, &selected, &v.VotedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear voto: %w", err)
+		}
+		if err := json.Unmarshal([]byte(selected), &v.SelectedOptions); err != nil {
+			return nil, fmt.Errorf("voto corrompido: %w", err)
+		}
+		votes = append(votes, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar votos: %w", err)
+	}
+	return votes, nil
+}
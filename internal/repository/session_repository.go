@@ -21,9 +21,13 @@ func NewSessionRepository(db *sql.DB, log *logger.Logger) *SessionRepository {
 	return &SessionRepository{db: db, logger: log}
 }
 
+// ErrSessionNotFound indica que a chave não existe para o tenant consultado.
+var ErrSessionNotFound = errors.New("sessão não encontrada para este tenant")
+
 const sessionSelectCols = `
 	id, tenant_id, whatsapp_session_key, nome_pessoa, email_pessoa, phone_number, device_jid,
-	status, qr_code, qr_code_expires_at, created_at, updated_at, last_connected_at
+	status, qr_code, qr_code_expires_at, created_at, updated_at, last_connected_at,
+	webhook_url, webhook_secret, pairing_phone, pairing_code, pairing_code_expires_at
 `
 
 const sessionSelectBase = `
@@ -47,6 +51,11 @@ func scanSession(scanner interface{ Scan(dest ...any) error }) (*models.WhatsApp
 		&s.CreatedAt,
 		&s.UpdatedAt,
 		&s.LastConnectedAt,
+		&s.WebhookURL,
+		&s.WebhookSecret,
+		&s.PairingPhone,
+		&s.PairingCode,
+		&s.PairingCodeExpiresAt,
 	); err != nil {
 		return nil, err
 	}
@@ -118,28 +127,13 @@ func (r *SessionRepository) GetByID(id uuid.UUID) (*models.WhatsAppSession, erro
 	return session, nil
 }
 
-func (r *SessionRepository) GetBySessionKey(sessionKey string) (*models.WhatsAppSession, error) {
-	query := sessionSelectBase + ` WHERE whatsapp_session_key = $1`
-	row := r.db.QueryRow(query, sessionKey)
-
-	session, err := scanSession(row)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, fmt.Errorf("sessão não encontrada")
-	}
-	if err != nil {
-		return nil, fmt.Errorf("falha ao buscar sessão: %w", err)
-	}
-
-	return session, nil
-}
-
 func (r *SessionRepository) GetBySessionKeyAndTenant(sessionKey string, tenantID string) (*models.WhatsAppSession, error) {
 	query := sessionSelectBase + ` WHERE whatsapp_session_key = $1 AND tenant_id = $2`
 	row := r.db.QueryRow(query, sessionKey, tenantID)
 
 	session, err := scanSession(row)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, fmt.Errorf("sessão não encontrada para este tenant")
+		return nil, ErrSessionNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("falha ao buscar sessão: %w", err)
@@ -160,6 +154,18 @@ func (r *SessionRepository) UpdateQRCode(id uuid.UUID, qrCode string, expiresAt
 	return nil
 }
 
+func (r *SessionRepository) UpdatePairingCode(id uuid.UUID, phone string, code string, expiresAt time.Time) error {
+	query := `
+		UPDATE whatsapp_sessions
+		SET pairing_phone = $1, pairing_code = $2, pairing_code_expires_at = $3, updated_at = $4
+		WHERE id = $5
+	`
+	if _, err := r.db.Exec(query, phone, code, expiresAt, time.Now(), id); err != nil {
+		return fmt.Errorf("falha ao atualizar código de pareamento: %w", err)
+	}
+	return nil
+}
+
 func (r *SessionRepository) UpdateStatus(id uuid.UUID, status string, phoneNumber string, deviceJID string) error {
 	now := time.Now()
 	var lastConnectedAt *time.Time
@@ -204,6 +210,21 @@ func (r *SessionRepository) UpdateDeviceJID(id uuid.UUID, deviceJID string) erro
 	return nil
 }
 
+func (r *SessionRepository) UpdateWebhook(id uuid.UUID, url *string, secret *string) error {
+	query := `
+		UPDATE whatsapp_sessions
+		SET webhook_url = $1, webhook_secret = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	if _, err := r.db.Exec(query, url, secret, time.Now(), id); err != nil {
+		return fmt.Errorf("falha ao atualizar webhook: %w", err)
+	}
+
+	r.logger.Infof("Webhook da sessão atualizado: %s", id)
+	return nil
+}
+
 func (r *SessionRepository) MarkLoggedOut(id uuid.UUID) error {
 	query := `
 		UPDATE whatsapp_sessions
@@ -229,6 +250,9 @@ func (r *SessionRepository) ResetSessionForReRegister(id uuid.UUID, nomePessoa s
 		    device_jid = NULL,
 		    qr_code = NULL,
 		    qr_code_expires_at = NULL,
+		    pairing_phone = NULL,
+		    pairing_code = NULL,
+		    pairing_code_expires_at = NULL,
 		    updated_at = $4,
 		    last_connected_at = NULL
 		WHERE id = $5
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type TemplateRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewTemplateRepository(db *sql.DB, log *logger.Logger) *TemplateRepository {
+	return &TemplateRepository{db: db, logger: log}
+}
+
+var (
+	ErrTemplateNotFound        = errors.New("template não encontrado")
+	ErrTemplateVersionNotFound = errors.New("versão do template não encontrada")
+)
+
+// templateSelect traz o template com o conteúdo da versão atual.
+const templateSelect = `SELECT
+	t.id, t.tenant_id, t.name, t.language, t.current_version, v.body, v.media, v.variables,
+	t.created_at, t.updated_at
+	FROM message_templates t
+	JOIN message_template_versions v ON v.template_id = t.id AND v.version = t.current_version`
+
+func scanTemplate(scanner interface{ Scan(dest ...any) error }) (*models.MessageTemplate, error) {
+	t := &models.MessageTemplate{}
+	var media sql.NullString
+	var variables string
+	if err := scanner.Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.Name,
+		&t.Language,
+		&t.Version,
+		&t.Body,
+		&media,
+		&variables,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := decodeTemplateContent(media, variables, &t.Media, &t.Variables); err != nil {
+		return nil, fmt.Errorf("template %s corrompido: %w", t.ID, err)
+	}
+	return t, nil
+}
+
+func decodeTemplateContent(media sql.NullString, variables string, dstMedia **models.TemplateMedia, dstVars *[]string) error {
+	if media.Valid && media.String != "" {
+		*dstMedia = &models.TemplateMedia{}
+		if err := json.Unmarshal([]byte(media.String), *dstMedia); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal([]byte(variables), dstVars)
+}
+
+func encodeTemplateContent(media *models.TemplateMedia, variables []string) (sql.NullString, string, error) {
+	var m sql.NullString
+	if media != nil {
+		raw, err := json.Marshal(media)
+		if err != nil {
+			return m, "", fmt.Errorf("falha ao serializar mídia do template: %w", err)
+		}
+		m = sql.NullString{String: string(raw), Valid: true}
+	}
+	if variables == nil {
+		variables = []string{}
+	}
+	vars, err := json.Marshal(variables)
+	if err != nil {
+		return m, "", fmt.Errorf("falha ao serializar variáveis do template: %w", err)
+	}
+	return m, string(vars), nil
+}
+
+const insertTemplateVersion = `
+	INSERT INTO message_template_versions (template_id, version, body, media, variables, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+// Create grava o template com a versão 1.
+func (r *TemplateRepository) Create(t *models.MessageTemplate) error {
+	media, vars, err := encodeTemplateContent(t.Media, t.Variables)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
+		INSERT INTO message_templates (id, tenant_id, name, language, current_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		t.ID, t.TenantID, t.Name, t.Language, t.Version, t.CreatedAt, t.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao criar template: %w", err)
+	}
+	if _, err := tx.Exec(insertTemplateVersion, t.ID, t.Version, t.Body, media, vars, t.CreatedAt); err != nil {
+		return fmt.Errorf("falha ao gravar versão do template: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao criar template: %w", err)
+	}
+	return nil
+}
+
+// AddVersion grava o conteúdo de t como a próxima versão do template e a
+// torna atual. Versão e updated_at de t são atualizados.
+func (r *TemplateRepository) AddVersion(t *models.MessageTemplate) error {
+	media, vars, err := encodeTemplateContent(t.Media, t.Variables)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var current int
+	err = tx.QueryRow(`SELECT current_version FROM message_templates WHERE id = $1 AND tenant_id = $2`, t.ID, t.TenantID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTemplateNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao buscar template: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(insertTemplateVersion, t.ID, current+1, t.Body, media, vars, now); err != nil {
+		return fmt.Errorf("falha ao gravar versão do template: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE message_templates SET current_version = $1, updated_at = $2 WHERE id = $3`, current+1, now, t.ID); err != nil {
+		return fmt.Errorf("falha ao atualizar template: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao gravar versão do template: %w", err)
+	}
+
+	t.Version = current + 1
+	t.UpdatedAt = now
+	return nil
+}
+
+func (r *TemplateRepository) GetByIDAndTenant(id uuid.UUID, tenantID string) (*models.MessageTemplate, error) {
+	t, err := scanTemplate(r.db.QueryRow(templateSelect+` WHERE t.id = $1 AND t.tenant_id = $2`, id, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar template: %w", err)
+	}
+	return t, nil
+}
+
+// GetVersion busca uma versão específica do template.
+func (r *TemplateRepository) GetVersion(id uuid.UUID, version int) (*models.TemplateVersion, error) {
+	v := &models.TemplateVersion{}
+	var media sql.NullString
+	var variables string
+	err := r.db.QueryRow(`SELECT version, body, media, variables, created_at FROM message_template_versions
+		WHERE template_id = $1 AND version = $2`, id, version).Scan(&v.Version, &v.Body, &media, &variables, &v.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar versão do template: %w", err)
+	}
+	if err := decodeTemplateContent(media, variables, &v.Media, &v.Variables); err != nil {
+		return nil, fmt.Errorf("versão %d do template %s corrompida: %w", version, id, err)
+	}
+	return v, nil
+}
+
+func (r *TemplateRepository) Versions(id uuid.UUID) ([]*models.TemplateVersion, error) {
+	rows, err := r.db.Query(`SELECT version, body, media, variables, created_at FROM message_template_versions
+		WHERE template_id = $1 ORDER BY version DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar versões do template: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	versions := make([]*models.TemplateVersion, 0)
+	for rows.Next() {
+		v := &models.TemplateVersion{}
+		var media sql.NullString
+		var variables string
+		if err := rows.Scan(&v.Version, &v.Body, &media, &variables, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear versão do template: %w", err)
+		}
+		if err := decodeTemplateContent(media, variables, &v.Media, &v.Variables); err != nil {
+			return nil, fmt.Errorf("versão %d do template %s corrompida: %w", v.Version, id, err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar versões do template: %w", err)
+	}
+	return versions, nil
+}
+
+// List lista os templates do tenant por nome e idioma.
+func (r *TemplateRepository) List(f models.TemplateFilter) ([]*models.MessageTemplate, error) {
+	conds := []string{"t.tenant_id = $1"}
+	args := []any{f.TenantID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Name != "" {
+		add("t.name = $%d", f.Name)
+	}
+	if f.Language != "" {
+		add("t.language = $%d", f.Language)
+	}
+
+	query := templateSelect + ` WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY t.name ASC, t.language ASC`
+	if f.Limit > 0 {
+		args = append(args, f.Limit, f.Offset)
+		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar templates: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	templates := make([]*models.MessageTemplate, 0)
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Delete remove o template e suas versões. Mensagens já enviadas mantêm o
+// ID e a versão registrados.
+func (r *TemplateRepository) Delete(id uuid.UUID, tenantID string) error {
+	result, err := r.db.Exec(`DELETE FROM message_templates WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("falha ao remover template: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao remover template: %w", err)
+	}
+	if n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type TenantRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewTenantRepository(db *sql.DB, log *logger.Logger) *TenantRepository {
+	return &TenantRepository{db: db, logger: log}
+}
+
+var (
+	ErrTenantNotFound      = errors.New("tenant não encontrado")
+	ErrTenantAlreadyExists = errors.New("tenant já existe")
+	ErrAPIKeyNotFound      = errors.New("chave de API não encontrada")
+)
+
+const tenantSelectCols = `id, name, status, created_at, updated_at`
+
+const apiKeySelectCols = `
+	k.id, k.tenant_id, k.name, k.key_prefix, k.key_hash, k.created_at, k.last_used_at, k.revoked_at
+`
+
+func scanTenant(scanner interface{ Scan(dest ...any) error }) (*models.Tenant, error) {
+	t := &models.Tenant{}
+	if err := scanner.Scan(&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanAPIKey(scanner interface{ Scan(dest ...any) error }) (*models.TenantAPIKey, error) {
+	k := &models.TenantAPIKey{}
+	if err := scanner.Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&k.CreatedAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *TenantRepository) Create(t *models.Tenant) error {
+	exists, err := r.Exists(t.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrTenantAlreadyExists
+	}
+
+	query := `
+		INSERT INTO tenants (id, name, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := r.db.Exec(query, t.ID, t.Name, t.Status, t.CreatedAt, t.UpdatedAt); err != nil {
+		return fmt.Errorf("falha ao criar tenant: %w", err)
+	}
+
+	r.logger.Infof("Tenant criado com sucesso: %s", t.ID)
+	return nil
+}
+
+func (r *TenantRepository) Exists(id string) (bool, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM tenants WHERE id = $1`, id).Scan(&count); err != nil {
+		return false, fmt.Errorf("falha ao verificar tenant: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *TenantRepository) Get(id string) (*models.Tenant, error) {
+	t, err := scanTenant(r.db.QueryRow(`SELECT `+tenantSelectCols+` FROM tenants WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar tenant: %w", err)
+	}
+	return t, nil
+}
+
+func (r *TenantRepository) List() ([]*models.Tenant, error) {
+	rows, err := r.db.Query(`SELECT ` + tenantSelectCols + ` FROM tenants ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar tenants: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	tenants := make([]*models.Tenant, 0)
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+func (r *TenantRepository) UpdateStatus(id string, status string) error {
+	result, err := r.db.Exec(`UPDATE tenants SET status = $1, updated_at = $2 WHERE id = $3`, status, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("falha ao atualizar tenant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if rows == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+func (r *TenantRepository) CreateAPIKey(k *models.TenantAPIKey) error {
+	query := `
+		INSERT INTO tenant_api_keys (id, tenant_id, name, key_prefix, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := r.db.Exec(query, k.ID, k.TenantID, k.Name, k.Prefix, k.KeyHash, k.CreatedAt); err != nil {
+		return fmt.Errorf("falha ao criar chave de API: %w", err)
+	}
+	return nil
+}
+
+func (r *TenantRepository) GetAPIKey(tenantID string, id uuid.UUID) (*models.TenantAPIKey, error) {
+	query := `SELECT ` + apiKeySelectCols + ` FROM tenant_api_keys k WHERE k.id = $1 AND k.tenant_id = $2`
+
+	k, err := scanAPIKey(r.db.QueryRow(query, id, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar chave de API: %w", err)
+	}
+	return k, nil
+}
+
+func (r *TenantRepository) ListAPIKeys(tenantID string) ([]*models.TenantAPIKey, error) {
+	query := `SELECT ` + apiKeySelectCols + ` FROM tenant_api_keys k WHERE k.tenant_id = $1 ORDER BY k.created_at DESC`
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar chaves de API: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	keys := make([]*models.TenantAPIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear chave de API: %w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar chaves de API: %w", err)
+	}
+
+	return keys, nil
+}
+
+// FindActiveAPIKey resolve a chave pelo hash, considerando apenas chaves não
+// revogadas de tenants ativos.
+func (r *TenantRepository) FindActiveAPIKey(keyHash string) (*models.TenantAPIKey, error) {
+	query := `SELECT ` + apiKeySelectCols + `
+		FROM tenant_api_keys k
+		JOIN tenants t ON t.id = k.tenant_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND t.status = $2`
+
+	k, err := scanAPIKey(r.db.QueryRow(query, keyHash, models.TenantStatusActive))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar chave de API: %w", err)
+	}
+	return k, nil
+}
+
+func (r *TenantRepository) TouchAPIKey(id uuid.UUID, at time.Time) error {
+	if _, err := r.db.Exec(`UPDATE tenant_api_keys SET last_used_at = $1 WHERE id = $2`, at, id); err != nil {
+		return fmt.Errorf("falha ao registrar uso da chave de API: %w", err)
+	}
+	return nil
+}
+
+func (r *TenantRepository) RevokeAPIKey(tenantID string, id uuid.UUID) error {
+	query := `UPDATE tenant_api_keys SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id, tenantID)
+	if err != nil {
+		return fmt.Errorf("falha ao revogar chave de API: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RotateAPIKey revoga a chave antiga e grava a nova na mesma transação, para
+// que o tenant nunca fique sem chave nem com as duas ativas.
+func (r *TenantRepository) RotateAPIKey(tenantID string, oldID uuid.UUID, k *models.TenantAPIKey) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var result sql.Result
+	result, err = tx.Exec(
+		`UPDATE tenant_api_keys SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`,
+		k.CreatedAt, oldID, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao revogar chave de API: %w", err)
+	}
+	var rows int64
+	if rows, err = result.RowsAffected(); err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if rows == 0 {
+		err = ErrAPIKeyNotFound
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO tenant_api_keys (id, tenant_id, name, key_prefix, key_hash, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		k.ID, k.TenantID, k.Name, k.Prefix, k.KeyHash, k.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao criar chave de API: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar rotação da chave: %w", err)
+	}
+	return nil
+}
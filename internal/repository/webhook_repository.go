@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+var ErrWebhookDeliveryNotFound = errors.New("entrega de webhook não encontrada")
+
+type WebhookRepository struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewWebhookRepository(db *sql.DB, log *logger.Logger) *WebhookRepository {
+	return &WebhookRepository{db: db, logger: log}
+}
+
+// DueWebhookDelivery é uma entrega pendente já acompanhada do destino atual
+// configurado na sessão, para que retries usem sempre a URL/segredo vigentes.
+type DueWebhookDelivery struct {
+	*models.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+const webhookDeliverySelectCols = `
+	d.id, d.session_id, d.tenant_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_error, d.last_status_code, d.created_at, d.updated_at, d.delivered_at
+`
+
+func scanWebhookDelivery(scanner interface{ Scan(dest ...any) error }, extra ...any) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	var payload string
+	dest := []any{
+		&d.ID,
+		&d.SessionID,
+		&d.TenantID,
+		&d.EventID,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastError,
+		&d.LastStatusCode,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.DeliveredAt,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	d.Payload = []byte(payload)
+	return d, nil
+}
+
+func (r *WebhookRepository) Enqueue(d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, session_id, tenant_id, event_id, event_type, payload, status,
+			attempts, next_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.Exec(query,
+		d.ID,
+		d.SessionID,
+		d.TenantID,
+		d.EventID,
+		d.EventType,
+		string(d.Payload),
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.CreatedAt,
+		d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao enfileirar webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListDue(now time.Time, limit int) ([]*DueWebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliverySelectCols + `, s.webhook_url, s.webhook_secret
+		FROM webhook_deliveries d
+		JOIN whatsapp_sessions s ON s.id = d.session_id
+		WHERE d.status = $1 AND d.next_attempt_at <= $2
+		ORDER BY d.next_attempt_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(query, models.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar webhooks pendentes: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	due := make([]*DueWebhookDelivery, 0)
+	for rows.Next() {
+		var url, secret sql.NullString
+		d, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear webhook: %w", err)
+		}
+		due = append(due, &DueWebhookDelivery{WebhookDelivery: d, URL: url.String, Secret: secret.String})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar webhooks: %w", err)
+	}
+
+	return due, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(id uuid.UUID, attempts int, statusCode int) error {
+	now := time.Now()
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, last_status_code = $3, last_error = NULL,
+		    updated_at = $4, delivered_at = $4
+		WHERE id = $5
+	`
+
+	if _, err := r.db.Exec(query, models.WebhookDeliveryDelivered, attempts, statusCode, now, id); err != nil {
+		return fmt.Errorf("falha ao marcar webhook como entregue: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) MarkFailed(id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string, statusCode *int, dead bool) error {
+	status := models.WebhookDeliveryPending
+	if dead {
+		status = models.WebhookDeliveryDead
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4,
+		    last_status_code = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	if _, err := r.db.Exec(query, status, attempts, nextAttemptAt, lastError, statusCode, time.Now(), id); err != nil {
+		return fmt.Errorf("falha ao registrar falha de webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListBySession(sessionID uuid.UUID, status string, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliverySelectCols + ` FROM webhook_deliveries d WHERE d.session_id = $1`
+	args := []any{sessionID}
+	if status != "" {
+		query += ` AND d.status = $2 ORDER BY d.created_at DESC LIMIT $3`
+		args = append(args, status, limit)
+	} else {
+		query += ` ORDER BY d.created_at DESC LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar entregas de webhook: %w", err)
+	}
+	defer closeRows(r.logger, rows)
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear webhook: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao iterar webhooks: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) GetBySession(id uuid.UUID, sessionID uuid.UUID) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliverySelectCols + ` FROM webhook_deliveries d WHERE d.id = $1 AND d.session_id = $2`
+
+	d, err := scanWebhookDelivery(r.db.QueryRow(query, id, sessionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("entrega de webhook não encontrada")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar entrega de webhook: %w", err)
+	}
+	return d, nil
+}
+
+func (r *WebhookRepository) Requeue(id uuid.UUID, sessionID uuid.UUID) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, next_attempt_at = $2, updated_at = $2
+		WHERE id = $3 AND session_id = $4
+	`
+
+	result, err := r.db.Exec(query, models.WebhookDeliveryPending, time.Now(), id, sessionID)
+	if err != nil {
+		return fmt.Errorf("falha ao reenfileirar webhook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if rows == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errCampaignHalted interrompe a campanha inteira (ex.: a mídia não pôde ser
+// obtida): ela é pausada com o motivo em vez de falhar destinatário a
+// destinatário.
+var errCampaignHalted = errors.New("campanha interrompida")
+
+type campaignExecutor func(ctx context.Context, c *models.Campaign, rec *models.CampaignRecipient) (string, error)
+
+type campaignWorker struct {
+	sessionID uuid.UUID
+	wake      chan struct{}
+}
+
+// CampaignRunner executa as campanhas em andamento com um worker por
+// campanha. Destinatários e status ficam no banco, então campanhas em
+// execução são retomadas após um restart. Tentativas e backoff seguem a
+// configuração da fila de envio.
+type CampaignRunner struct {
+	repo    *repository.CampaignRepository
+	cfg     config.QueueConfig
+	logger  *logger.Logger
+	execute campaignExecutor
+
+	mu       sync.Mutex
+	workers  map[uuid.UUID]*campaignWorker
+	stopping bool
+	wg       sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+}
+
+func NewCampaignRunner(repo *repository.CampaignRepository, cfg config.QueueConfig, log *logger.Logger, execute campaignExecutor) *CampaignRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CampaignRunner{
+		repo:    repo,
+		cfg:     cfg,
+		logger:  log,
+		execute: execute,
+		workers: make(map[uuid.UUID]*campaignWorker),
+		ctx:     ctx,
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start retoma as campanhas que estavam em execução.
+func (cr *CampaignRunner) Start() {
+	if n, err := cr.repo.RequeueProcessing(); err != nil {
+		cr.logger.Errorf("Falha ao recuperar envios de campanha em processamento: %v", err)
+	} else if n > 0 {
+		cr.logger.Infof("%d envios de campanha interrompidos foram devolvidos à fila", n)
+	}
+
+	campaigns, err := cr.repo.ListByStatus(models.CampaignStatusRunning)
+	if err != nil {
+		cr.logger.Errorf("Falha ao listar campanhas em execução: %v", err)
+		return
+	}
+	for _, c := range campaigns {
+		cr.Wake(c.ID, c.SessionID)
+	}
+}
+
+// Stop espera o envio em andamento de cada campanha por até DrainTimeout e
+// então cancela; destinatários interrompidos voltam para a fila.
+func (cr *CampaignRunner) Stop() {
+	cr.mu.Lock()
+	if cr.stopping {
+		cr.mu.Unlock()
+		return
+	}
+	cr.stopping = true
+	cr.mu.Unlock()
+	close(cr.stop)
+
+	done := make(chan struct{})
+	go func() {
+		cr.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(cr.cfg.DrainTimeout):
+		cr.logger.Warn("Tempo de drenagem das campanhas esgotado, cancelando envios em andamento")
+		cr.cancel()
+		<-done
+	}
+	cr.cancel()
+
+	if _, err := cr.repo.RequeueProcessing(); err != nil {
+		cr.logger.Errorf("Falha ao devolver envios de campanha à fila: %v", err)
+	}
+}
+
+// Wake inicia o worker da campanha ou o acorda para reler o status (após
+// início, pausa, cancelamento ou novos destinatários).
+func (cr *CampaignRunner) Wake(campaignID, sessionID uuid.UUID) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.stopping {
+		return
+	}
+
+	w, ok := cr.workers[campaignID]
+	if !ok {
+		w = &campaignWorker{sessionID: sessionID, wake: make(chan struct{}, 1)}
+		cr.workers[campaignID] = w
+		cr.wg.Add(1)
+		go cr.worker(campaignID, w.wake)
+	}
+	wakeUp(w.wake)
+}
+
+// NotifySession acorda as campanhas da sessão (ex.: após reconectar).
+func (cr *CampaignRunner) NotifySession(sessionID uuid.UUID) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	for _, w := range cr.workers {
+		if w.sessionID == sessionID {
+			wakeUp(w.wake)
+		}
+	}
+}
+
+func (cr *CampaignRunner) retire(campaignID uuid.UUID, wake chan struct{}) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	select {
+	case <-wake:
+		return false
+	default:
+	}
+	delete(cr.workers, campaignID)
+	return true
+}
+
+func (cr *CampaignRunner) worker(campaignID uuid.UUID, wake chan struct{}) {
+	defer cr.wg.Done()
+
+	var lastSend time.Time
+	for {
+		select {
+		case <-cr.stop:
+			return
+		default:
+		}
+
+		c, err := cr.repo.Get(campaignID)
+		if err != nil {
+			if errors.Is(err, repository.ErrCampaignNotFound) {
+				if cr.retire(campaignID, wake) {
+					return
+				}
+				continue
+			}
+			cr.logger.Errorf("Falha ao consultar campanha %s: %v", campaignID, err)
+			if !cr.sleep(wake, cr.cfg.PollInterval) {
+				return
+			}
+			continue
+		}
+		if c.Status != models.CampaignStatusRunning {
+			if cr.retire(campaignID, wake) {
+				return
+			}
+			continue
+		}
+
+		now := time.Now()
+		if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+			if !cr.expire(c) && !cr.sleep(wake, cr.cfg.PollInterval) {
+				return
+			}
+			continue
+		}
+		if wait := campaignWait(c, now); wait > 0 {
+			if !cr.sleep(wake, wait) {
+				return
+			}
+			continue
+		}
+
+		rec, err := cr.repo.NextRecipient(campaignID)
+		if err != nil {
+			cr.logger.Errorf("Falha ao consultar destinatários da campanha %s: %v", campaignID, err)
+			if !cr.sleep(wake, cr.cfg.PollInterval) {
+				return
+			}
+			continue
+		}
+		if rec == nil {
+			if done, err := cr.repo.Complete(campaignID); err != nil {
+				cr.logger.Errorf("Falha ao concluir campanha %s: %v", campaignID, err)
+				if !cr.sleep(wake, cr.cfg.PollInterval) {
+					return
+				}
+			} else if done {
+				cr.logger.Infof("[%s] Campanha %s concluída", c.SessionKey, campaignID)
+			}
+			continue
+		}
+
+		if wait := time.Until(rec.NextAttemptAt); wait > 0 {
+			if !cr.sleep(wake, wait) {
+				return
+			}
+			continue
+		}
+		if c.PerMinute > 0 && !lastSend.IsZero() {
+			if wait := time.Until(lastSend.Add(time.Minute / time.Duration(c.PerMinute))); wait > 0 {
+				if !cr.sleep(wake, wait) {
+					return
+				}
+				continue
+			}
+		}
+
+		attempted, wait := cr.process(c, rec)
+		if attempted {
+			lastSend = time.Now()
+		}
+		if wait > 0 && !cr.sleep(wake, wait) {
+			return
+		}
+	}
+}
+
+// expire encerra a campanha que passou de ends_at; quem não recebeu até lá
+// fica como cancelado. Devolve false se a campanha não pôde ser encerrada.
+func (cr *CampaignRunner) expire(c *models.Campaign) bool {
+	n, err := cr.repo.CancelQueued(c.ID, "período da campanha encerrado")
+	if err != nil {
+		cr.logger.Errorf("Falha ao encerrar campanha %s: %v", c.ID, err)
+		return false
+	}
+	if n > 0 {
+		cr.logger.Warnf("[%s] Campanha %s encerrada em ends_at com %d destinatários não enviados", c.SessionKey, c.ID, n)
+		if err := cr.repo.SetLastError(c.ID, fmt.Sprintf("período encerrado com %d destinatários não enviados", n)); err != nil {
+			cr.logger.Errorf("Falha ao registrar erro da campanha %s: %v", c.ID, err)
+		}
+	}
+	if _, err := cr.repo.Complete(c.ID); err != nil {
+		cr.logger.Errorf("Falha ao concluir campanha %s: %v", c.ID, err)
+		return false
+	}
+	return true
+}
+
+func (cr *CampaignRunner) sleep(wake chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-cr.stop:
+		return false
+	case <-wake:
+	case <-timer.C:
+	}
+	return true
+}
+
+// process envia para um destinatário. Devolve se houve tentativa de envio,
+// o que conta para o ritmo da campanha, e quanto o worker deve aguardar
+// antes do próximo: sessão desconectada e limites de envio valem para toda
+// a campanha, então o destinatário mantém a vez e o worker espera.
+func (cr *CampaignRunner) process(c *models.Campaign, rec *models.CampaignRecipient) (bool, time.Duration) {
+	if err := cr.repo.MarkRecipientProcessing(c.ID, rec.Position); err != nil {
+		cr.logger.Errorf("Falha ao iniciar envio da campanha %s: %v", c.ID, err)
+		return false, cr.cfg.PollInterval
+	}
+
+	attempts := rec.Attempts + 1
+	messageID, err := cr.execute(cr.ctx, c, rec)
+
+	var limited *RateLimitError
+	switch {
+	case err == nil:
+		if markErr := cr.repo.MarkRecipientSent(c.ID, rec.Position, attempts, messageID); markErr != nil {
+			cr.logger.Errorf("Falha ao marcar envio da campanha %s como enviado: %v", c.ID, markErr)
+		}
+		return true, 0
+
+	case cr.ctx.Err() != nil:
+		cr.retry(c, rec, rec.Attempts, rec.NextAttemptAt, "envio interrompido pelo desligamento")
+		return false, 0
+
+	case errors.As(err, &limited):
+		cr.retry(c, rec, rec.Attempts, rec.NextAttemptAt, err.Error())
+		return false, limited.RetryAfter
+
+	case errors.Is(err, errSessionUnavailable):
+		cr.retry(c, rec, rec.Attempts, rec.NextAttemptAt, err.Error())
+		return false, cr.cfg.PollInterval
+
+	case errors.Is(err, errCampaignHalted):
+		cr.logger.Warnf("[%s] Campanha %s pausada: %v", c.SessionKey, c.ID, err)
+		cr.retry(c, rec, rec.Attempts, rec.NextAttemptAt, err.Error())
+		if markErr := cr.repo.Pause(c.ID, err.Error()); markErr != nil {
+			cr.logger.Errorf("Falha ao pausar campanha %s: %v", c.ID, markErr)
+		}
+		return false, 0
+	}
+
+	var perm *permanentSendError
+	if errors.As(err, &perm) || attempts >= cr.cfg.MaxAttempts {
+		cr.logger.Warnf("[%s] Campanha %s: envio para %s falhou definitivamente após %d tentativas: %v", c.SessionKey, c.ID, rec.Number, attempts, err)
+		if markErr := cr.repo.MarkRecipientFailed(c.ID, rec.Position, attempts, err.Error()); markErr != nil {
+			cr.logger.Errorf("Falha ao marcar envio da campanha %s como falho: %v", c.ID, markErr)
+		}
+		return true, 0
+	}
+
+	cr.logger.Warnf("[%s] Campanha %s: falha no envio para %s (tentativa %d): %v", c.SessionKey, c.ID, rec.Number, attempts, err)
+	cr.retry(c, rec, attempts, time.Now().Add(retryBackoff(cr.cfg.InitialBackoff, cr.cfg.MaxBackoff, attempts)), err.Error())
+	return true, 0
+}
+
+func (cr *CampaignRunner) retry(c *models.Campaign, rec *models.CampaignRecipient, attempts int, next time.Time, reason string) {
+	if err := cr.repo.MarkRecipientRetry(c.ID, rec.Position, attempts, next, reason); err != nil {
+		cr.logger.Errorf("Falha ao reagendar envio da campanha %s: %v", c.ID, err)
+	}
+}
+
+// campaignWait devolve quanto falta para a campanha poder enviar: até
+// starts_at e, fora da janela diária, até a próxima abertura dela.
+func campaignWait(c *models.Campaign, now time.Time) time.Duration {
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return c.StartsAt.Sub(now)
+	}
+	if c.WindowStart == "" || c.WindowEnd == "" {
+		return 0
+	}
+
+	loc := time.UTC
+	if c.Timezone != "" {
+		if l, err := time.LoadLocation(c.Timezone); err == nil {
+			loc = l
+		}
+	}
+	start, errStart := parseClock(c.WindowStart)
+	end, errEnd := parseClock(c.WindowEnd)
+	if errStart != nil || errEnd != nil {
+		return 0
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	inWindow := minute >= start && minute < end
+	if start > end {
+		// Janela que atravessa a meia-noite (ex.: 22:00–06:00).
+		inWindow = minute >= start || minute < end
+	}
+	if inWindow {
+		return 0
+	}
+
+	open := time.Date(local.Year(), local.Month(), local.Day(), start/60, start%60, 0, 0, loc)
+	if !open.After(local) {
+		open = open.AddDate(0, 0, 1)
+	}
+	return open.Sub(now)
+}
+
+// parseClock converte "HH:MM" em minutos desde a meia-noite.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("horário inválido %q: use HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,533 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/msgtemplate"
+	"boot-whatsapp-golang/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // fusos de campanha sem depender do zoneinfo do sistema
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+)
+
+var (
+	ErrCampaignNotFound     = repository.ErrCampaignNotFound
+	ErrCampaignInvalidState = errors.New("operação não permitida no status atual da campanha")
+	ErrInvalidCampaign      = errors.New("campanha inválida")
+)
+
+// maxCampaignErrorDetails limita os erros por linha devolvidos ao cliente.
+const maxCampaignErrorDetails = 50
+
+// CampaignValidationError descreve os campos ou linhas da lista recusados.
+type CampaignValidationError struct {
+	Details map[string]string
+}
+
+func (e *CampaignValidationError) Error() string {
+	keys := make([]string, 0, len(e.Details))
+	for k := range e.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+e.Details[k])
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidCampaign, strings.Join(parts, "; "))
+}
+
+func (e *CampaignValidationError) Unwrap() error { return ErrInvalidCampaign }
+
+// CampaignStateError informa o status que impediu a operação.
+type CampaignStateError struct {
+	Status string
+}
+
+func (e *CampaignStateError) Error() string {
+	return fmt.Sprintf("%s (%s)", ErrCampaignInvalidState, e.Status)
+}
+
+func (e *CampaignStateError) Unwrap() error { return ErrCampaignInvalidState }
+
+// CreateCampaign valida e grava a campanha como rascunho, com os
+// destinatários enviados junto, se houver.
+func (s *MultiTenantWhatsAppService) CreateCampaign(tenantID string, req *models.CampaignRequest) (*models.Campaign, error) {
+	details := s.validateCampaign(tenantID, req)
+	if len(details) > 0 {
+		return nil, &CampaignValidationError{Details: details}
+	}
+
+	session, err := s.resolveSession(req.SessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	c := &models.Campaign{
+		ID:          uuid.New(),
+		TenantID:    session.TenantID,
+		SessionID:   session.ID,
+		SessionKey:  session.WhatsAppSessionKey,
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        models.CampaignKindText,
+		Message:     req.Message,
+		Status:      models.CampaignStatusDraft,
+		StartsAt:    utcPtr(req.StartsAt),
+		EndsAt:      utcPtr(req.EndsAt),
+		WindowStart: req.WindowStart,
+		WindowEnd:   req.WindowEnd,
+		Timezone:    req.Timezone,
+		PerMinute:   req.PerMinute,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if c.Message.MediaURL != "" || c.Message.MediaID != "" {
+		c.Kind = models.CampaignKindMedia
+	}
+	if c.PerMinute == 0 {
+		c.PerMinute = s.config.Campaign.DefaultPerMinute
+	}
+
+	recipients, err := s.checkRecipients(c, req.Recipients, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.campaigns.Create(c); err != nil {
+		return nil, err
+	}
+	if len(recipients) > 0 {
+		if err := s.campaigns.AddRecipients(c.ID, recipients); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetCampaign(tenantID, c.ID.String())
+}
+
+// validateCampaign confere as regras entre campos da campanha;
+// obrigatoriedade e formatos ficam nas tags de models.CampaignRequest.
+func (s *MultiTenantWhatsAppService) validateCampaign(tenantID string, req *models.CampaignRequest) map[string]string {
+	details := map[string]string{}
+	msg := &req.Message
+	if msg.MediaURL == "" && msg.MediaID == "" {
+		if strings.TrimSpace(msg.Text) == "" {
+			details["message.text"] = "obrigatório em campanhas de texto"
+		}
+		for field, value := range map[string]string{"caption": msg.Caption, "mime_type": msg.MimeType, "filename": msg.FileName, "mode": msg.Mode} {
+			if value != "" {
+				details["message."+field] = "só permitido com media_url ou media_id"
+			}
+		}
+	} else {
+		if msg.Mode != "" && msg.Caption != "" {
+			details["message.caption"] = "não permitido com mode " + msg.Mode
+		}
+		if msg.MediaID != "" {
+			if _, err := s.GetMedia(tenantID, msg.MediaID); err != nil {
+				details["message.media_id"] = err.Error()
+			}
+		}
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		details["ends_at"] = "deve ser posterior a starts_at"
+	} else if req.EndsAt != nil && !req.EndsAt.After(time.Now()) {
+		details["ends_at"] = "deve estar no futuro"
+	}
+	if (req.WindowStart == "") != (req.WindowEnd == "") {
+		details["window_start"] = "informe window_start e window_end juntos"
+	}
+	if req.WindowStart != "" {
+		if _, err := parseClock(req.WindowStart); err != nil {
+			details["window_start"] = err.Error()
+		}
+	}
+	if req.WindowEnd != "" {
+		if _, err := parseClock(req.WindowEnd); err != nil {
+			details["window_end"] = err.Error()
+		}
+	}
+	if req.WindowStart != "" && req.WindowStart == req.WindowEnd {
+		details["window_end"] = "deve ser diferente de window_start"
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			details["timezone"] = "fuso desconhecido, use o nome IANA (ex.: America/Sao_Paulo)"
+		}
+	}
+	return details
+}
+
+// checkRecipients valida a lista contra a mensagem da campanha: números
+// aceitos nos envios (exceto links de convite) e todas as variáveis do
+// texto preenchidas. Números já presentes na campanha ou repetidos na lista
+// são descartados. Devolve os destinatários a gravar.
+func (s *MultiTenantWhatsAppService) checkRecipients(c *models.Campaign, inputs []models.CampaignRecipientInput, existing map[string]bool, current int) ([]models.CampaignRecipientInput, error) {
+	if existing == nil {
+		existing = map[string]bool{}
+	}
+	template := c.Message.Text + "\n" + c.Message.Caption
+
+	details := map[string]string{}
+	accepted := make([]models.CampaignRecipientInput, 0, len(inputs))
+	for i, in := range inputs {
+		line := in.Line
+		if line == 0 {
+			line = i + 1
+		}
+		problem := ""
+		number := strings.TrimSpace(in.Number)
+		vars := msgtemplate.Normalize(in.Variables)
+		kind, err := validator.ValidateRecipient(number)
+		switch {
+		case err != nil:
+			problem = err.Error()
+		case kind == validator.RecipientInvite:
+			problem = "links de convite não são aceitos em campanhas"
+		case kind == validator.RecipientPhone:
+			// Números são gravados em E.164, para que grafias diferentes do
+			// mesmo número contem como duplicadas.
+			phone, err := validator.NormalizePhone(number, s.config.WhatsApp.DefaultCountry)
+			if err != nil {
+				problem = err.Error()
+			} else {
+				number = phone.E164()
+			}
+		}
+		if missing := msgtemplate.Missing(template, vars); problem == "" && len(missing) > 0 {
+			problem = "variáveis sem valor: " + strings.Join(missing, ", ")
+		}
+		if problem != "" {
+			if len(details) < maxCampaignErrorDetails {
+				details["linha "+strconv.Itoa(line)] = problem
+			}
+			continue
+		}
+		if existing[number] {
+			continue
+		}
+		existing[number] = true
+		accepted = append(accepted, models.CampaignRecipientInput{Number: number, Variables: vars, Line: line})
+	}
+
+	if len(details) > 0 {
+		return nil, &CampaignValidationError{Details: details}
+	}
+	if limit := s.config.Campaign.MaxRecipients; limit > 0 && current+len(accepted) > limit {
+		return nil, &CampaignValidationError{Details: map[string]string{
+			"recipients": fmt.Sprintf("a campanha comporta até %d destinatários (já tem %d)", limit, current),
+		}}
+	}
+	return accepted, nil
+}
+
+// AddCampaignRecipients anexa destinatários a uma campanha ainda não
+// encerrada; campanhas em execução passam a enviar para eles também.
+func (s *MultiTenantWhatsAppService) AddCampaignRecipients(tenantID, campaignID string, inputs []models.CampaignRecipientInput) (*models.CampaignRecipientsAdded, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Status {
+	case models.CampaignStatusDraft, models.CampaignStatusRunning, models.CampaignStatusPaused:
+	default:
+		return nil, &CampaignStateError{Status: c.Status}
+	}
+	if len(inputs) == 0 {
+		return nil, &CampaignValidationError{Details: map[string]string{"recipients": "lista vazia"}}
+	}
+
+	existing, err := s.campaigns.Numbers(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	current := len(existing)
+	accepted, err := s.checkRecipients(c, inputs, existing, current)
+	if err != nil {
+		return nil, err
+	}
+	if len(accepted) > 0 {
+		if err := s.campaigns.AddRecipients(c.ID, accepted); err != nil {
+			return nil, err
+		}
+	}
+	if c.Status == models.CampaignStatusRunning {
+		s.runner.Wake(c.ID, c.SessionID)
+	}
+	return &models.CampaignRecipientsAdded{
+		Added:      len(accepted),
+		Duplicates: len(inputs) - len(accepted),
+		Total:      current + len(accepted),
+	}, nil
+}
+
+func (s *MultiTenantWhatsAppService) tenantCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	id, err := uuid.Parse(campaignID)
+	if err != nil {
+		return nil, ErrCampaignNotFound
+	}
+	return s.campaigns.GetByIDAndTenant(id, tenantID)
+}
+
+// GetCampaign devolve a campanha com a contagem de destinatários por status.
+func (s *MultiTenantWhatsAppService) GetCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Stats, err = s.campaigns.Stats(c.ID, c.SessionID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *MultiTenantWhatsAppService) ListCampaigns(tenantID string) ([]*models.Campaign, error) {
+	campaigns, err := s.campaigns.ListByTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range campaigns {
+		if c.Stats, err = s.campaigns.Stats(c.ID, c.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	return campaigns, nil
+}
+
+func (s *MultiTenantWhatsAppService) ListCampaignRecipients(tenantID, campaignID string, filter models.CampaignRecipientFilter) ([]*models.CampaignRecipient, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.campaigns.ListRecipients(c.ID, c.SessionID, filter)
+}
+
+// CountCampaignRecipients conta os destinatários da campanha com o status de
+// relatório informado (vazio conta todos), independentemente da paginação.
+func (s *MultiTenantWhatsAppService) CountCampaignRecipients(tenantID, campaignID, status string) (int, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	stats, err := s.campaigns.Stats(c.ID, c.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	switch status {
+	case models.CampaignRecipientQueued:
+		return stats.Queued, nil
+	case models.CampaignRecipientProcessing:
+		return stats.Processing, nil
+	case models.CampaignRecipientSent:
+		return stats.Sent, nil
+	case models.CampaignRecipientDelivered:
+		return stats.Delivered, nil
+	case models.CampaignRecipientRead:
+		return stats.Read, nil
+	case models.CampaignRecipientFailed:
+		return stats.Failed, nil
+	case models.CampaignRecipientCanceled:
+		return stats.Canceled, nil
+	}
+	return stats.Total, nil
+}
+
+// StartCampaign inicia um rascunho com destinatários.
+func (s *MultiTenantWhatsAppService) StartCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := s.campaigns.Numbers(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, &CampaignValidationError{Details: map[string]string{"recipients": "envie a lista de destinatários antes de iniciar"}}
+	}
+	return s.transitionCampaign(tenantID, c, []string{models.CampaignStatusDraft}, models.CampaignStatusRunning)
+}
+
+func (s *MultiTenantWhatsAppService) PauseCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transitionCampaign(tenantID, c, []string{models.CampaignStatusRunning}, models.CampaignStatusPaused)
+}
+
+func (s *MultiTenantWhatsAppService) ResumeCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transitionCampaign(tenantID, c, []string{models.CampaignStatusPaused}, models.CampaignStatusRunning)
+}
+
+// CancelCampaign encerra a campanha; destinatários ainda na fila ficam como
+// cancelados e o envio em andamento, se houver, termina normalmente.
+func (s *MultiTenantWhatsAppService) CancelCampaign(tenantID, campaignID string) (*models.Campaign, error) {
+	c, err := s.tenantCampaign(tenantID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	from := []string{models.CampaignStatusDraft, models.CampaignStatusRunning, models.CampaignStatusPaused}
+	canceled, err := s.transitionCampaign(tenantID, c, from, models.CampaignStatusCanceled)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.campaigns.CancelQueued(c.ID, "campanha cancelada"); err != nil {
+		return nil, err
+	}
+	return s.GetCampaign(tenantID, canceled.ID.String())
+}
+
+func (s *MultiTenantWhatsAppService) transitionCampaign(tenantID string, c *models.Campaign, from []string, to string) (*models.Campaign, error) {
+	ok, err := s.campaigns.Transition(c.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		current, err := s.campaigns.Get(c.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &CampaignStateError{Status: current.Status}
+	}
+	s.logger.Infof("[%s] Campanha %s: %s -> %s", c.SessionKey, c.ID, c.Status, to)
+	s.runner.Wake(c.ID, c.SessionID)
+	return s.GetCampaign(tenantID, c.ID.String())
+}
+
+// executeCampaignSend é chamado pelo worker da campanha para um destinatário.
+func (s *MultiTenantWhatsAppService) executeCampaignSend(ctx context.Context, c *models.Campaign, rec *models.CampaignRecipient) (string, error) {
+	waClient, err := s.getConnectedClient(c.TenantID, c.SessionKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errSessionUnavailable, err)
+	}
+
+	var sent *models.MessageSent
+	switch c.Kind {
+	case models.CampaignKindText:
+		text, err := msgtemplate.Render(c.Message.Text, rec.Variables)
+		if err != nil {
+			return "", permanent(err)
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		sent, err = s.sendText(sendCtx, waClient, &models.MessageRequest{
+			Number:      rec.Number,
+			Text:        text,
+			LinkPreview: c.Message.LinkPreview,
+		})
+		if err != nil {
+			return "", campaignSendError(err)
+		}
+
+	case models.CampaignKindMedia:
+		caption, err := msgtemplate.Render(c.Message.Caption, rec.Variables)
+		if err != nil {
+			return "", permanent(err)
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		mediaID, err := s.campaignMedia(sendCtx, waClient, c)
+		if err != nil {
+			return "", err
+		}
+		sent, err = s.sendMedia(sendCtx, waClient, &models.MediaRequest{
+			Number:   rec.Number,
+			Caption:  caption,
+			MediaID:  mediaID,
+			FileName: c.Message.FileName,
+		}, nil)
+		if errors.Is(err, ErrMediaExpired) && c.Message.MediaURL != "" {
+			// O upload venceu durante a campanha: a próxima tentativa baixa
+			// a media_url de novo.
+			c.Message.MediaID = ""
+			if updateErr := s.campaigns.UpdateMessage(c.ID, c.Message); updateErr != nil {
+				return "", updateErr
+			}
+			return "", fmt.Errorf("mídia da campanha expirou, refazendo upload: %v", ErrMediaExpired)
+		}
+		if errors.Is(err, ErrMediaNotFound) || errors.Is(err, ErrMediaExpired) {
+			return "", fmt.Errorf("%w: %v", errCampaignHalted, err)
+		}
+		if err != nil {
+			return "", campaignSendError(err)
+		}
+
+	default:
+		return "", permanent(fmt.Errorf("tipo de campanha desconhecido: %s", c.Kind))
+	}
+	return sent.MessageID, nil
+}
+
+// campaignMedia devolve o media_id da campanha. Com media_url, a mídia é
+// baixada e enviada ao WhatsApp no primeiro envio e o media_id é gravado
+// na campanha; falhas nessa etapa pausam a campanha.
+func (s *MultiTenantWhatsAppService) campaignMedia(ctx context.Context, waClient *WhatsAppClient, c *models.Campaign) (string, error) {
+	if c.Message.MediaID != "" {
+		return c.Message.MediaID, nil
+	}
+
+	file, contentType, filename, err := s.prepareMedia(c.Message.MediaURL, "", c.Message.MimeType)
+	if err != nil {
+		return "", campaignMediaError(err)
+	}
+	defer func() { file.Close() }()
+	if c.Message.FileName != "" {
+		filename = c.Message.FileName
+	}
+
+	mediaType, d, err := s.describeMedia(c.Message.Mode, "", file, contentType, filename)
+	if err != nil {
+		return "", campaignMediaError(err)
+	}
+	_, record, err := s.uploadCached(ctx, waClient, file, mediaType, d, true)
+	if err != nil {
+		if errors.Is(err, whatsmeow.ErrNotConnected) {
+			return "", fmt.Errorf("%w: %v", errSessionUnavailable, err)
+		}
+		return "", fmt.Errorf("falha ao fazer upload da mídia: %w", err)
+	}
+
+	c.Message.MediaID = record.ID.String()
+	if err := s.campaigns.UpdateMessage(c.ID, c.Message); err != nil {
+		return "", err
+	}
+	return c.Message.MediaID, nil
+}
+
+// campaignMediaError pausa a campanha quando a mídia é recusada de forma
+// definitiva; falhas transitórias do download seguem o retry normal.
+func campaignMediaError(err error) error {
+	var perm *permanentSendError
+	if errors.As(err, &perm) {
+		return fmt.Errorf("%w: mídia recusada: %v", errCampaignHalted, err)
+	}
+	return err
+}
+
+func campaignSendError(err error) error {
+	if errors.Is(err, whatsmeow.ErrNotConnected) {
+		return fmt.Errorf("%w: %v", errSessionUnavailable, err)
+	}
+	return err
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
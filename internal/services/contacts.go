@@ -0,0 +1,246 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var ErrNotOnWhatsApp = errors.New("número não tem WhatsApp")
+
+const (
+	// contactCheckBatch é quantos números vão em cada consulta ao WhatsApp.
+	contactCheckBatch = 100
+	// Números sem WhatsApp podem se registrar a qualquer momento, então a
+	// resposta negativa vale por menos tempo.
+	contactNegativeTTL = 10 * time.Minute
+	// contactPruneSize é o tamanho a partir do qual o cache de uma sessão
+	// descarta entradas vencidas ao gravar.
+	contactPruneSize = 10000
+)
+
+type contactEntry struct {
+	jid        types.JID
+	registered bool
+	expiresAt  time.Time
+}
+
+// contactCache guarda, por sessão, o resultado das consultas de número no
+// WhatsApp, indexado pelo número consultado (sem o +).
+type contactCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]map[string]contactEntry
+}
+
+func newContactCache(ttl time.Duration) *contactCache {
+	return &contactCache{ttl: ttl, sessions: make(map[uuid.UUID]map[string]contactEntry)}
+}
+
+func (c *contactCache) get(sessionID uuid.UUID, number string) (contactEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.sessions[sessionID][number]
+	if !ok || time.Now().After(e.expiresAt) {
+		return contactEntry{}, false
+	}
+	return e, true
+}
+
+func (c *contactCache) put(sessionID uuid.UUID, number string, jid types.JID, registered bool) {
+	if c.ttl <= 0 {
+		return
+	}
+	ttl := c.ttl
+	if !registered && ttl > contactNegativeTTL {
+		ttl = contactNegativeTTL
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.sessions[sessionID]
+	if entries == nil {
+		entries = make(map[string]contactEntry)
+		c.sessions[sessionID] = entries
+	}
+	if len(entries) >= contactPruneSize {
+		for k, e := range entries {
+			if now.After(e.expiresAt) {
+				delete(entries, k)
+			}
+		}
+	}
+	entries[number] = contactEntry{jid: jid, registered: registered, expiresAt: now.Add(ttl)}
+}
+
+func (c *contactCache) Remove(sessionID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, sessionID)
+}
+
+// lookupNumbers consulta no WhatsApp as grafias dos números que não estão
+// no cache da sessão. O resultado é indexado pelo número consultado. As
+// grafias consultadas contam nos limites de consulta; acima deles devolve um
+// *RateLimitError sem consultar nada.
+func (s *MultiTenantWhatsAppService) lookupNumbers(ctx context.Context, waClient *WhatsAppClient, phones []validator.PhoneNumber) (map[string]contactEntry, error) {
+	sessionID := waClient.Session.ID
+	found := make(map[string]contactEntry)
+	queued := make(map[string]bool)
+	pending := make([]string, 0)
+	for _, p := range phones {
+		for _, n := range p.Candidates() {
+			if _, done := found[n]; done || queued[n] {
+				continue
+			}
+			if e, ok := s.contacts.get(sessionID, n); ok {
+				found[n] = e
+				continue
+			}
+			queued[n] = true
+			pending = append(pending, n)
+		}
+	}
+
+	if len(pending) > 0 {
+		session := waClient.Session
+		if err := s.limiter.AllowLookup(session.WhatsAppSessionKey, session.TenantID, len(pending)); err != nil {
+			return found, err
+		}
+	}
+
+	for start := 0; start < len(pending); start += contactCheckBatch {
+		end := min(start+contactCheckBatch, len(pending))
+		query := make([]string, 0, end-start)
+		for _, n := range pending[start:end] {
+			query = append(query, "+"+n)
+		}
+		resp, err := waClient.Client.IsOnWhatsApp(ctx, query)
+		if err != nil {
+			return found, fmt.Errorf("falha ao consultar números no WhatsApp: %w", err)
+		}
+		for _, r := range resp {
+			n := strings.TrimPrefix(r.Query, "+")
+			jid := r.JID.ToNonAD()
+			s.contacts.put(sessionID, n, jid, r.IsIn)
+			found[n] = contactEntry{jid: jid, registered: r.IsIn}
+		}
+	}
+	return found, nil
+}
+
+// registeredJID devolve o JID da primeira grafia do número registrada no
+// WhatsApp. checked indica se todas as grafias foram consultadas.
+func registeredJID(p validator.PhoneNumber, found map[string]contactEntry) (jid types.JID, ok, checked bool) {
+	checked = true
+	for _, n := range p.Candidates() {
+		e, seen := found[n]
+		if !seen {
+			checked = false
+			continue
+		}
+		if e.registered {
+			return e.jid, true, true
+		}
+	}
+	return types.JID{}, false, checked
+}
+
+// resolvePhone converte um número no JID de envio. Números com uma única
+// grafia são convertidos localmente; os demais (celulares brasileiros com
+// ou sem o nono dígito) são consultados no WhatsApp para enviar à grafia
+// registrada. Se a consulta falhar, vale a grafia canônica.
+func (s *MultiTenantWhatsAppService) resolvePhone(ctx context.Context, waClient *WhatsAppClient, raw string) (types.JID, error) {
+	phone, err := validator.NormalizePhone(raw, s.config.WhatsApp.DefaultCountry)
+	if err != nil {
+		return types.JID{}, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	canonical := types.NewJID(phone.Digits(), types.DefaultUserServer)
+	if len(phone.Alternates) == 0 {
+		return canonical, nil
+	}
+
+	found, err := s.lookupNumbers(ctx, waClient, []validator.PhoneNumber{phone})
+	if err != nil {
+		s.logger.Warnf("[%s] %v; enviando para %s", waClient.Session.WhatsAppSessionKey, err, phone.E164())
+		return canonical, nil
+	}
+	jid, ok, checked := registeredJID(phone, found)
+	switch {
+	case ok:
+		return jid, nil
+	case checked:
+		return types.JID{}, fmt.Errorf("%w: %s", ErrNotOnWhatsApp, strings.Join(prefixed(phone.Candidates()), ", "))
+	default:
+		return canonical, nil
+	}
+}
+
+func prefixed(numbers []string) []string {
+	out := make([]string, len(numbers))
+	for i, n := range numbers {
+		out[i] = "+" + n
+	}
+	return out
+}
+
+// CheckContacts verifica em lote quais números têm WhatsApp e devolve o JID
+// canônico de cada um, na ordem recebida.
+func (s *MultiTenantWhatsAppService) CheckContacts(sessionKey, tenantID string, numbers []string) (*models.ContactCheckResponse, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	waClient, err := s.getConnectedClient(session.TenantID, session.WhatsAppSessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSessionNotConnected, err)
+	}
+
+	results := make([]models.ContactCheckResult, len(numbers))
+	phones := make([]validator.PhoneNumber, len(numbers))
+	valid := make([]validator.PhoneNumber, 0, len(numbers))
+	for i, raw := range numbers {
+		results[i].Input = raw
+		phone, err := validator.NormalizePhone(raw, s.config.WhatsApp.DefaultCountry)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		phones[i] = phone
+		valid = append(valid, phone)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	found, err := s.lookupNumbers(ctx, waClient, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.ContactCheckResponse{Total: len(numbers), Results: results}
+	for i := range results {
+		if results[i].Error != "" {
+			continue
+		}
+		results[i].Number = phones[i].E164()
+		if jid, ok, _ := registeredJID(phones[i], found); ok {
+			if jid.Server == types.DefaultUserServer {
+				results[i].Number = "+" + jid.User
+			}
+			results[i].JID = jid.String()
+			results[i].OnWhatsApp = true
+			resp.Registered++
+		}
+	}
+	return resp, nil
+}
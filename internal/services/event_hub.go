@@ -0,0 +1,150 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const streamSubscriberBuffer = 64
+
+// EventHub distribui os eventos normalizados das sessões para assinantes em
+// tempo real (SSE/WebSocket), mantendo um ring buffer por sessão para que
+// clientes que reconectam recuperem o que perderam.
+type EventHub struct {
+	mu      sync.Mutex
+	streams map[uuid.UUID]*sessionStream
+	size    int
+}
+
+type sessionStream struct {
+	mu      sync.Mutex
+	buf     []*models.StreamEvent
+	start   int
+	count   int
+	nextSeq uint64
+	subs    map[chan *models.StreamEvent]struct{}
+}
+
+func NewEventHub(bufferSize int) *EventHub {
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+	return &EventHub{
+		streams: make(map[uuid.UUID]*sessionStream),
+		size:    bufferSize,
+	}
+}
+
+func (h *EventHub) stream(sessionID uuid.UUID) *sessionStream {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st, ok := h.streams[sessionID]
+	if !ok {
+		st = &sessionStream{
+			buf: make([]*models.StreamEvent, h.size),
+			// A sequência parte do relógio para que IDs emitidos após um
+			// restart sejam sempre maiores que os anteriores: um cliente com
+			// Last-Event-ID antigo recebe o buffer inteiro em vez de nada.
+			nextSeq: uint64(time.Now().UnixMilli()) * 1000,
+			subs:    make(map[chan *models.StreamEvent]struct{}),
+		}
+		h.streams[sessionID] = st
+	}
+	return st
+}
+
+func (h *EventHub) Publish(sessionID uuid.UUID, evt *models.SessionEvent) {
+	st := h.stream(sessionID)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.nextSeq++
+	se := &models.StreamEvent{StreamID: st.nextSeq, SessionEvent: evt}
+
+	idx := (st.start + st.count) % len(st.buf)
+	st.buf[idx] = se
+	if st.count < len(st.buf) {
+		st.count++
+	} else {
+		st.start = (st.start + 1) % len(st.buf)
+	}
+
+	for ch := range st.subs {
+		select {
+		case ch <- se:
+		default:
+			// Assinante lento: encerramos o stream e ele retoma pelo
+			// Last-Event-ID a partir do buffer.
+			delete(st.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe devolve os eventos do buffer posteriores a lastID e um canal com
+// os próximos eventos. O canal é fechado se o assinante não acompanhar o ritmo.
+// gapFrom, quando diferente de zero, indica que eventos posteriores a lastID
+// já saíram do buffer (ou são de antes de um restart): o replay retoma a
+// partir de gapFrom.
+func (h *EventHub) Subscribe(sessionID uuid.UUID, lastID uint64) (replay []*models.StreamEvent, ch <-chan *models.StreamEvent, cancel func(), gapFrom uint64) {
+	st := h.stream(sessionID)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	replay = make([]*models.StreamEvent, 0)
+	if lastID > 0 {
+		// O primeiro ID que o buffer ainda consegue entregar.
+		oldest := st.nextSeq + 1
+		if st.count > 0 {
+			oldest = st.buf[st.start].StreamID
+		}
+		if lastID+1 < oldest {
+			gapFrom = oldest - 1
+		}
+		for i := 0; i < st.count; i++ {
+			se := st.buf[(st.start+i)%len(st.buf)]
+			if se.StreamID > lastID {
+				replay = append(replay, se)
+			}
+		}
+	}
+
+	sub := make(chan *models.StreamEvent, streamSubscriberBuffer)
+	st.subs[sub] = struct{}{}
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			st.mu.Lock()
+			if _, ok := st.subs[sub]; ok {
+				delete(st.subs, sub)
+				close(sub)
+			}
+			st.mu.Unlock()
+		})
+	}
+	return replay, sub, cancel, gapFrom
+}
+
+func (h *EventHub) Remove(sessionID uuid.UUID) {
+	h.mu.Lock()
+	st, ok := h.streams[sessionID]
+	delete(h.streams, sessionID)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	st.mu.Lock()
+	for ch := range st.subs {
+		delete(st.subs, ch)
+		close(ch)
+	}
+	st.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"boot-whatsapp-golang/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func streamIDs(events []*models.StreamEvent) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.StreamID
+	}
+	return ids
+}
+
+// publishN publica n eventos e devolve os IDs atribuídos, em ordem.
+func publishN(t *testing.T, hub *EventHub, sessionID uuid.UUID, n int) []uint64 {
+	t.Helper()
+	_, ch, cancel, _ := hub.Subscribe(sessionID, 0)
+	defer cancel()
+	ids := make([]uint64, n)
+	for i := range ids {
+		hub.Publish(sessionID, &models.SessionEvent{Type: "message"})
+		ids[i] = (<-ch).StreamID
+	}
+	return ids
+}
+
+func TestEventHubReplay(t *testing.T) {
+	hub := NewEventHub(4)
+	sessionID := uuid.New()
+	ids := publishN(t, hub, sessionID, 6) // o buffer guarda ids[2:]
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i] != ids[i-1]+1 {
+			t.Fatalf("IDs não consecutivos: %v", ids)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		lastID  uint64
+		replay  []uint64
+		gapFrom uint64
+	}{
+		{"sem Last-Event-ID", 0, []uint64{}, 0},
+		{"no meio do buffer", ids[3], ids[4:], 0},
+		{"último entregue", ids[5], []uint64{}, 0},
+		{"imediatamente antes do mais antigo", ids[1], ids[2:], 0},
+		{"já descartado pelo ring buffer", ids[0], ids[2:], ids[1]},
+		{"de antes de um restart", 1, ids[2:], ids[1]},
+		{"à frente do stream", ids[5] + 10, []uint64{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, _, cancel, gapFrom := hub.Subscribe(sessionID, tt.lastID)
+			defer cancel()
+			if got := streamIDs(replay); !slices.Equal(got, tt.replay) {
+				t.Errorf("replay = %v, esperado %v", got, tt.replay)
+			}
+			if gapFrom != tt.gapFrom {
+				t.Errorf("gapFrom = %d, esperado %d", gapFrom, tt.gapFrom)
+			}
+		})
+	}
+}
+
+func TestEventHubReplayWraparound(t *testing.T) {
+	hub := NewEventHub(3)
+	sessionID := uuid.New()
+	// Várias voltas no ring buffer: start deixa de ser zero.
+	ids := publishN(t, hub, sessionID, 11)
+
+	replay, _, cancel, gapFrom := hub.Subscribe(sessionID, ids[7])
+	defer cancel()
+	if got := streamIDs(replay); !slices.Equal(got, ids[8:]) {
+		t.Errorf("replay = %v, esperado %v", got, ids[8:])
+	}
+	if gapFrom != 0 {
+		t.Errorf("gapFrom = %d, esperado 0", gapFrom)
+	}
+}
+
+func TestEventHubEmptyStream(t *testing.T) {
+	hub := NewEventHub(4)
+	sessionID := uuid.New()
+	ids := publishN(t, hub, sessionID, 1)
+	hub.Remove(sessionID)
+	// A sequência nova parte do relógio em milissegundos.
+	time.Sleep(2 * time.Millisecond)
+
+	// Depois de um restart o stream recomeça vazio, com IDs maiores: um
+	// cliente com ID anterior é avisado da lacuna.
+	replay, _, cancel, gapFrom := hub.Subscribe(sessionID, ids[0])
+	defer cancel()
+	if len(replay) != 0 {
+		t.Errorf("replay = %v, esperado vazio", streamIDs(replay))
+	}
+	if gapFrom <= ids[0] {
+		t.Errorf("gapFrom = %d, esperado maior que %d", gapFrom, ids[0])
+	}
+}
+
+// O que chega ao buffer antes do Subscribe vem só no replay; o que chega
+// depois, só no canal.
+func TestEventHubReplayThenLive(t *testing.T) {
+	hub := NewEventHub(8)
+	sessionID := uuid.New()
+	ids := publishN(t, hub, sessionID, 3)
+
+	replay, ch, cancel, _ := hub.Subscribe(sessionID, ids[0])
+	defer cancel()
+	hub.Publish(sessionID, &models.SessionEvent{Type: "message"})
+	hub.Publish(sessionID, &models.SessionEvent{Type: "message"})
+
+	got := streamIDs(replay)
+	for len(ch) > 0 {
+		got = append(got, (<-ch).StreamID)
+	}
+	want := []uint64{ids[1], ids[2], ids[2] + 1, ids[2] + 2}
+	if !slices.Equal(got, want) {
+		t.Errorf("replay + ao vivo = %v, esperado %v", got, want)
+	}
+}
+
+func TestEventHubSlowSubscriber(t *testing.T) {
+	hub := NewEventHub(streamSubscriberBuffer * 2)
+	sessionID := uuid.New()
+	_, slow, cancelSlow, _ := hub.Subscribe(sessionID, 0)
+	defer cancelSlow()
+
+	for i := 0; i <= streamSubscriberBuffer; i++ {
+		hub.Publish(sessionID, &models.SessionEvent{Type: "message"})
+	}
+
+	// O canal traz o que coube e é fechado; o restante vem pelo replay.
+	var last uint64
+	n := 0
+	for e := range slow {
+		last = e.StreamID
+		n++
+	}
+	if n != streamSubscriberBuffer {
+		t.Fatalf("assinante lento recebeu %d eventos, esperado %d", n, streamSubscriberBuffer)
+	}
+	replay, _, cancel, gapFrom := hub.Subscribe(sessionID, last)
+	defer cancel()
+	if len(replay) != 1 || replay[0].StreamID != last+1 || gapFrom != 0 {
+		t.Errorf("replay = %v, gapFrom = %d; esperado [%d], 0", streamIDs(replay), gapFrom, last+1)
+	}
+
+	// cancel depois do fechamento não fecha o canal de novo.
+	cancelSlow()
+}
+
+func TestEventHubCancelAndRemove(t *testing.T) {
+	hub := NewEventHub(4)
+	sessionID := uuid.New()
+
+	_, ch, cancel, _ := hub.Subscribe(sessionID, 0)
+	cancel()
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("canal aberto depois de cancel")
+	}
+	hub.Publish(sessionID, &models.SessionEvent{Type: "message"})
+
+	_, ch, cancel, _ = hub.Subscribe(sessionID, 0)
+	defer cancel()
+	hub.Remove(sessionID)
+	if _, ok := <-ch; ok {
+		t.Fatal("canal aberto depois de Remove")
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func newSessionEvent(session *models.WhatsAppSession, eventType string, data interface{}) *models.SessionEvent {
+	return &models.SessionEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		SessionKey: session.WhatsAppSessionKey,
+		Timestamp:  time.Now(),
+		Data:       data,
+	}
+}
+
+// webhookEventTypes são os eventos persistidos na fila de webhooks. Presença
+// e digitação mudam a todo instante e só interessam ao stream ao vivo; QR
+// codes permitiriam vincular a conta e também ficam só no stream.
+var webhookEventTypes = map[string]bool{
+	models.EventTypeMessage:    true,
+	models.EventTypeReceipt:    true,
+	models.EventTypeConnection: true,
+	models.EventTypePollVote:   true,
+}
+
+func (s *MultiTenantWhatsAppService) publishEvent(session *models.WhatsAppSession, evt *models.SessionEvent) {
+	s.events.Publish(session.ID, evt)
+
+	if !webhookEventTypes[evt.Type] {
+		return
+	}
+	if err := s.webhooks.Enqueue(session, evt); err != nil {
+		s.logger.Errorf("[%s] Falha ao enfileirar webhook de %s: %v", session.WhatsAppSessionKey, evt.Type, err)
+	}
+}
+
+func (s *MultiTenantWhatsAppService) SubscribeEvents(sessionKey, tenantID string, lastID uint64) ([]*models.StreamEvent, <-chan *models.StreamEvent, func(), error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	replay, ch, cancel, gapFrom := s.events.Subscribe(session.ID, lastID)
+	if gapFrom > 0 {
+		// O gap leva o ID de retomada: se o cliente reconectar logo em
+		// seguida com ele, não recebe outro aviso.
+		gap := &models.StreamEvent{
+			StreamID: gapFrom,
+			SessionEvent: newSessionEvent(session, models.EventTypeGap, &models.StreamGapEvent{
+				LastEventID: lastID,
+				ResumedFrom: gapFrom,
+			}),
+		}
+		replay = append([]*models.StreamEvent{gap}, replay...)
+	}
+	return replay, ch, cancel, nil
+}
+
+func normalizeMessageEvent(evt *events.Message) *models.InboundMessageEvent {
+	msgType, text := messageContentSummary(evt.Message)
+	out := &models.InboundMessageEvent{
+		MessageID: evt.Info.ID,
+		Chat:      evt.Info.Chat.String(),
+		Sender:    evt.Info.Sender.String(),
+		PushName:  evt.Info.PushName,
+		FromMe:    evt.Info.IsFromMe,
+		IsGroup:   evt.Info.IsGroup,
+		Type:      msgType,
+		Text:      text,
+		IsEdit:    evt.IsEdit,
+		Timestamp: evt.Info.Timestamp,
+	}
+	if !evt.Info.SenderAlt.IsEmpty() {
+		out.SenderAlt = evt.Info.SenderAlt.String()
+	}
+	out.MimeType, out.FileName = messageMediaInfo(evt.Message)
+	if ci := messageContextInfo(evt.Message); ci != nil {
+		out.QuotedID = ci.GetStanzaID()
+	}
+	return out
+}
+
+func normalizeReceiptEvent(evt *events.Receipt) *models.ReceiptEvent {
+	return &models.ReceiptEvent{
+		MessageIDs: append([]string(nil), evt.MessageIDs...),
+		Chat:       evt.Chat.String(),
+		Sender:     evt.Sender.String(),
+		IsGroup:    evt.IsGroup,
+		Type:       receiptTypeName(evt.Type),
+		Timestamp:  evt.Timestamp,
+	}
+}
+
+func normalizePresenceEvent(evt *events.Presence) *models.PresenceEvent {
+	out := &models.PresenceEvent{
+		JID:         evt.From.String(),
+		Unavailable: evt.Unavailable,
+	}
+	if !evt.LastSeen.IsZero() {
+		lastSeen := evt.LastSeen
+		out.LastSeen = &lastSeen
+	}
+	return out
+}
+
+func normalizeChatPresenceEvent(evt *events.ChatPresence) *models.ChatPresenceEvent {
+	return &models.ChatPresenceEvent{
+		Chat:   evt.Chat.String(),
+		Sender: evt.Sender.String(),
+		State:  string(evt.State),
+		Media:  string(evt.Media),
+	}
+}
+
+func receiptTypeName(t types.ReceiptType) string {
+	if t == types.ReceiptTypeDelivered {
+		return "delivered"
+	}
+	return string(t)
+}
+
+// messageContentSummary classifica a mensagem e extrai o texto legível
+// (corpo ou legenda) usado nos eventos e no histórico de mensagens.
+func messageContentSummary(msg *waE2E.Message) (string, string) {
+	switch {
+	case msg == nil:
+		return "unknown", ""
+	case msg.GetConversation() != "":
+		return "text", msg.GetConversation()
+	case msg.GetExtendedTextMessage() != nil:
+		return "text", msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		return "image", msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		return "video", msg.GetVideoMessage().GetCaption()
+	case msg.GetAudioMessage() != nil:
+		if msg.GetAudioMessage().GetPTT() {
+			return "voice", ""
+		}
+		return "audio", ""
+	case msg.GetDocumentMessage() != nil:
+		return "document", msg.GetDocumentMessage().GetCaption()
+	case msg.GetStickerMessage() != nil:
+		return "sticker", ""
+	case msg.GetLocationMessage() != nil:
+		return "location", msg.GetLocationMessage().GetName()
+	case msg.GetContactMessage() != nil:
+		return "contact", msg.GetContactMessage().GetDisplayName()
+	case msg.GetContactsArrayMessage() != nil:
+		return "contacts", msg.GetContactsArrayMessage().GetDisplayName()
+	case msg.GetReactionMessage() != nil:
+		return "reaction", msg.GetReactionMessage().GetText()
+	case msg.GetPollCreationMessage() != nil:
+		return "poll", msg.GetPollCreationMessage().GetName()
+	case msg.GetPollCreationMessageV3() != nil:
+		return "poll", msg.GetPollCreationMessageV3().GetName()
+	case msg.GetPollUpdateMessage() != nil:
+		return "poll_vote", ""
+	case msg.GetProtocolMessage() != nil:
+		return "protocol", ""
+	default:
+		return "unknown", ""
+	}
+}
+
+func messageMediaInfo(msg *waE2E.Message) (string, string) {
+	switch {
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetMimetype(), ""
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetMimetype(), ""
+	case msg.GetAudioMessage() != nil:
+		return msg.GetAudioMessage().GetMimetype(), ""
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetMimetype(), msg.GetDocumentMessage().GetFileName()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetMimetype(), ""
+	default:
+		return "", ""
+	}
+}
+
+func messageContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		return msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetContextInfo()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetContextInfo()
+	default:
+		return nil
+	}
+}
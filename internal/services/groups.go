@@ -0,0 +1,369 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const groupRequestTimeout = 30 * time.Second
+
+// Erros de grupo devolvidos ao handler. Os erros do whatsmeow são embrulhados
+// por classifyGroupError, preservando a mensagem original.
+var (
+	ErrSessionNotConnected  = errors.New("sessão não está conectada")
+	ErrInvalidGroupJID      = errors.New("JID de grupo inválido")
+	ErrGroupNotFound        = errors.New("grupo não encontrado")
+	ErrNotInGroup           = errors.New("a sessão não participa deste grupo")
+	ErrGroupForbidden       = errors.New("a sessão não tem permissão para esta ação no grupo")
+	ErrInviteLinkInvalid    = errors.New("link de convite inválido")
+	ErrInviteLinkRevoked    = errors.New("link de convite revogado")
+	ErrGroupRequestRejected = errors.New("requisição recusada pelo WhatsApp")
+	ErrGroupRateLimited     = errors.New("limite de requisições de grupo do WhatsApp excedido")
+	ErrInvalidGroupPicture  = errors.New("a foto do grupo deve ser JPEG")
+)
+
+func classifyGroupError(err error) error {
+	var kind error
+	switch {
+	case errors.Is(err, whatsmeow.ErrNotConnected):
+		kind = ErrSessionNotConnected
+	case errors.Is(err, whatsmeow.ErrNotInGroup):
+		kind = ErrNotInGroup
+	case errors.Is(err, whatsmeow.ErrGroupNotFound), errors.Is(err, whatsmeow.ErrIQNotFound):
+		kind = ErrGroupNotFound
+	case errors.Is(err, whatsmeow.ErrInviteLinkInvalid):
+		kind = ErrInviteLinkInvalid
+	case errors.Is(err, whatsmeow.ErrInviteLinkRevoked), errors.Is(err, whatsmeow.ErrIQGone):
+		kind = ErrInviteLinkRevoked
+	case errors.Is(err, whatsmeow.ErrGroupInviteLinkUnauthorized),
+		errors.Is(err, whatsmeow.ErrIQNotAuthorized),
+		errors.Is(err, whatsmeow.ErrIQForbidden),
+		errors.Is(err, whatsmeow.ErrIQNotAllowed):
+		kind = ErrGroupForbidden
+	case errors.Is(err, whatsmeow.ErrInvalidImageFormat):
+		kind = ErrInvalidGroupPicture
+	case errors.Is(err, whatsmeow.ErrIQBadRequest), errors.Is(err, whatsmeow.ErrIQNotAcceptable):
+		kind = ErrGroupRequestRejected
+	case errors.Is(err, whatsmeow.ErrIQRateOverLimit):
+		kind = ErrGroupRateLimited
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %v", kind, err)
+}
+
+// parseGroupJID aceita o JID completo (123-456@g.us) ou apenas o ID do grupo.
+func parseGroupJID(raw string) (types.JID, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return types.JID{}, ErrInvalidGroupJID
+	}
+	if !strings.Contains(raw, "@") {
+		raw += "@" + types.GroupServer
+	}
+
+	jid, err := types.ParseJID(raw)
+	if err != nil || jid.Server != types.GroupServer || jid.User == "" {
+		return types.JID{}, fmt.Errorf("%w: %s", ErrInvalidGroupJID, raw)
+	}
+	return jid, nil
+}
+
+func (s *MultiTenantWhatsAppService) parseParticipants(numbers []string) ([]types.JID, error) {
+	jids := make([]types.JID, 0, len(numbers))
+	for _, n := range numbers {
+		jid, err := s.parsePhoneNumber(n)
+		if err != nil {
+			return nil, fmt.Errorf("participante %q: %w", n, err)
+		}
+		jids = append(jids, jid)
+	}
+	return jids, nil
+}
+
+// groupClient resolve a sessão pelo tenant e devolve o cliente conectado.
+func (s *MultiTenantWhatsAppService) groupClient(sessionKey, tenantID string) (*whatsmeow.Client, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	waClient, err := s.getConnectedClient(session.TenantID, session.WhatsAppSessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSessionNotConnected, err)
+	}
+	return waClient.Client, nil
+}
+
+func toGroupParticipant(p types.GroupParticipant) models.GroupParticipant {
+	gp := models.GroupParticipant{
+		JID:          p.JID.String(),
+		IsAdmin:      p.IsAdmin,
+		IsSuperAdmin: p.IsSuperAdmin,
+		Error:        p.Error,
+	}
+	if !p.PhoneNumber.IsEmpty() {
+		gp.PhoneNumber = p.PhoneNumber.User
+	}
+	return gp
+}
+
+func toGroup(info *types.GroupInfo, withParticipants bool) *models.Group {
+	g := &models.Group{
+		JID:              info.JID.String(),
+		Name:             info.Name,
+		Description:      info.Topic,
+		CreatedAt:        info.GroupCreated,
+		IsAnnounce:       info.IsAnnounce,
+		IsLocked:         info.IsLocked,
+		ParticipantCount: info.ParticipantCount,
+	}
+	if !info.OwnerJID.IsEmpty() {
+		g.OwnerJID = info.OwnerJID.String()
+	}
+	if g.ParticipantCount == 0 {
+		g.ParticipantCount = len(info.Participants)
+	}
+	if withParticipants {
+		g.Participants = make([]models.GroupParticipant, 0, len(info.Participants))
+		for _, p := range info.Participants {
+			g.Participants = append(g.Participants, toGroupParticipant(p))
+		}
+	}
+	return g
+}
+
+func (s *MultiTenantWhatsAppService) ListGroups(sessionKey, tenantID string) ([]*models.Group, error) {
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	infos, err := client.GetJoinedGroups(ctx)
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+
+	groups := make([]*models.Group, 0, len(infos))
+	for _, info := range infos {
+		groups = append(groups, toGroup(info, false))
+	}
+	return groups, nil
+}
+
+func (s *MultiTenantWhatsAppService) GetGroup(sessionKey, tenantID, groupJID string) (*models.Group, error) {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	info, err := client.GetGroupInfo(ctx, jid)
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+	return toGroup(info, true), nil
+}
+
+func (s *MultiTenantWhatsAppService) CreateGroup(sessionKey, tenantID string, req *models.CreateGroupRequest) (*models.Group, error) {
+	participants, err := s.parseParticipants(req.Participants)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	info, err := client.CreateGroup(ctx, whatsmeow.ReqCreateGroup{
+		Name:         req.Name,
+		Participants: participants,
+	})
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+
+	s.logger.Infof("[%s] Grupo criado: %s [Tenant: %s]", sessionKey, info.JID, tenantID)
+	return toGroup(info, true), nil
+}
+
+// UpdateGroupParticipants devolve o resultado por participante; falhas
+// individuais vêm no campo error de cada item, não como erro da chamada.
+func (s *MultiTenantWhatsAppService) UpdateGroupParticipants(sessionKey, tenantID, groupJID string, req *models.UpdateGroupParticipantsRequest) ([]models.GroupParticipant, error) {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return nil, err
+	}
+	participants, err := s.parseParticipants(req.Participants)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	changed, err := client.UpdateGroupParticipants(ctx, jid, participants, whatsmeow.ParticipantChange(req.Action))
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+
+	result := make([]models.GroupParticipant, 0, len(changed))
+	for _, p := range changed {
+		result = append(result, toGroupParticipant(p))
+	}
+	return result, nil
+}
+
+func (s *MultiTenantWhatsAppService) UpdateGroup(sessionKey, tenantID, groupJID string, req *models.UpdateGroupRequest) (*models.Group, error) {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	if req.Name != nil {
+		if err := client.SetGroupName(ctx, jid, *req.Name); err != nil {
+			return nil, classifyGroupError(err)
+		}
+	}
+	if req.Description != nil {
+		if err := client.SetGroupTopic(ctx, jid, "", "", *req.Description); err != nil {
+			return nil, classifyGroupError(err)
+		}
+	}
+
+	info, err := client.GetGroupInfo(ctx, jid)
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+	return toGroup(info, false), nil
+}
+
+// SetGroupPicture troca a foto do grupo; sem mídia, a foto é removida.
+func (s *MultiTenantWhatsAppService) SetGroupPicture(sessionKey, tenantID, groupJID string, req *models.GroupPictureRequest) (string, error) {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return "", err
+	}
+	// A sessão é conferida antes do download: uma sessão alheia ou
+	// desconhecida não faz o servidor buscar a URL.
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	var avatar []byte
+	if req != nil && (req.MediaURL != "" || req.MediaBase64 != "") {
+		file, contentType, _, err := s.prepareMedia(req.MediaURL, req.MediaBase64, req.MimeType)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		if contentType != "image/jpeg" {
+			return "", fmt.Errorf("%w: recebido %s", ErrInvalidGroupPicture, contentType)
+		}
+		if avatar, err = file.Bytes(); err != nil {
+			return "", fmt.Errorf("falha ao ler foto do grupo: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	pictureID, err := client.SetGroupPhoto(ctx, jid, avatar)
+	if err != nil {
+		return "", classifyGroupError(err)
+	}
+	return pictureID, nil
+}
+
+// GetGroupInviteLink devolve o link atual; com reset, o link anterior é
+// revogado e um novo é gerado.
+func (s *MultiTenantWhatsAppService) GetGroupInviteLink(sessionKey, tenantID, groupJID string, reset bool) (*models.GroupInviteLink, error) {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	link, err := client.GetGroupInviteLink(ctx, jid, reset)
+	if err != nil {
+		return nil, classifyGroupError(err)
+	}
+	return &models.GroupInviteLink{GroupJID: jid.String(), InviteLink: link}, nil
+}
+
+// JoinGroup entra no grupo pelo link de convite. Em grupos com aprovação, o
+// JID devolvido é o do pedido pendente.
+func (s *MultiTenantWhatsAppService) JoinGroup(sessionKey, tenantID, inviteLink string) (string, error) {
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	jid, err := client.JoinGroupWithLink(ctx, strings.TrimSpace(inviteLink))
+	if err != nil {
+		return "", classifyGroupError(err)
+	}
+
+	s.logger.Infof("[%s] Entrou no grupo %s via convite [Tenant: %s]", sessionKey, jid, tenantID)
+	return jid.String(), nil
+}
+
+func (s *MultiTenantWhatsAppService) LeaveGroup(sessionKey, tenantID, groupJID string) error {
+	jid, err := parseGroupJID(groupJID)
+	if err != nil {
+		return err
+	}
+	client, err := s.groupClient(sessionKey, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	if err := client.LeaveGroup(ctx, jid); err != nil {
+		return classifyGroupError(err)
+	}
+
+	s.logger.Infof("[%s] Saiu do grupo %s [Tenant: %s]", sessionKey, jid, tenantID)
+	return nil
+}
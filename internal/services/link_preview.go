@@ -0,0 +1,325 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/media"
+	"boot-whatsapp-golang/pkg/validator"
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	previewUserAgent           = "Mozilla/5.0 (compatible; WhatsAppBotLinkPreview/1.0)"
+	maxPreviewTitleRunes       = 200
+	maxPreviewDescriptionRunes = 300
+)
+
+var errNoPreview = errors.New("página sem metadados de prévia")
+
+// linkPreview é a prévia pronta para o ExtendedTextMessage.
+type linkPreview struct {
+	URL         string
+	Title       string
+	Description string
+	Thumbnail   *media.Thumbnail
+}
+
+func (p *linkPreview) apply(msg *waE2E.ExtendedTextMessage) {
+	msg.MatchedText = proto.String(p.URL)
+	msg.Title = proto.String(p.Title)
+	if p.Description != "" {
+		msg.Description = proto.String(p.Description)
+	}
+	if p.Thumbnail != nil {
+		msg.JPEGThumbnail = p.Thumbnail.JPEG
+		msg.ThumbnailWidth = proto.Uint32(uint32(p.Thumbnail.Width))
+		msg.ThumbnailHeight = proto.Uint32(uint32(p.Thumbnail.Height))
+	}
+}
+
+type previewEntry struct {
+	url     string
+	preview *linkPreview // nil quando a página não rendeu prévia
+	expires time.Time
+}
+
+// LinkPreviewer busca as tags OpenGraph/Twitter das páginas e guarda o
+// resultado num cache LRU por URL, inclusive as falhas, para que a mesma
+// URL enviada em massa gere uma única requisição por CacheTTL.
+type LinkPreviewer struct {
+	cfg    config.LinkPreviewConfig
+	client *http.Client
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+func NewLinkPreviewer(cfg config.LinkPreviewConfig, client *http.Client, log *logger.Logger) *LinkPreviewer {
+	return &LinkPreviewer{
+		cfg:     cfg,
+		client:  client,
+		logger:  log,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get devolve a prévia da URL ou nil quando ela não está disponível. Falhas
+// nunca impedem o envio da mensagem.
+func (p *LinkPreviewer) Get(ctx context.Context, rawURL string) *linkPreview {
+	if preview, ok := p.cached(rawURL); ok {
+		return preview
+	}
+
+	preview, err := p.fetch(ctx, rawURL)
+	if err != nil {
+		p.logger.Debugf("Prévia indisponível para %s: %v", rawURL, err)
+		// Cancelamento do próprio envio não diz nada sobre a página.
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	p.store(rawURL, preview)
+	return preview
+}
+
+func (p *LinkPreviewer) cached(rawURL string) (*linkPreview, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	el, ok := p.entries[rawURL]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*previewEntry)
+	if time.Now().After(entry.expires) {
+		p.lru.Remove(el)
+		delete(p.entries, rawURL)
+		return nil, false
+	}
+	p.lru.MoveToFront(el)
+	return entry.preview, true
+}
+
+func (p *LinkPreviewer) store(rawURL string, preview *linkPreview) {
+	if p.cfg.CacheSize <= 0 || p.cfg.CacheTTL <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := &previewEntry{url: rawURL, preview: preview, expires: time.Now().Add(p.cfg.CacheTTL)}
+	if el, ok := p.entries[rawURL]; ok {
+		el.Value = entry
+		p.lru.MoveToFront(el)
+		return
+	}
+	p.entries[rawURL] = p.lru.PushFront(entry)
+	for p.lru.Len() > p.cfg.CacheSize {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*previewEntry).url)
+	}
+}
+
+func (p *LinkPreviewer) fetch(ctx context.Context, rawURL string) (*linkPreview, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	page, contentType, finalURL, err := p.get(ctx, rawURL, "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("conteúdo %q não é HTML", contentType)
+	}
+
+	body, err := charset.NewReader(bytes.NewReader(page), contentType)
+	if err != nil {
+		body = bytes.NewReader(page)
+	}
+	tags := parseMetaTags(body)
+
+	preview := &linkPreview{
+		URL:         rawURL,
+		Title:       truncateRunes(firstNonEmpty(tags["og:title"], tags["twitter:title"], tags["title"]), maxPreviewTitleRunes),
+		Description: truncateRunes(firstNonEmpty(tags["og:description"], tags["twitter:description"], tags["description"]), maxPreviewDescriptionRunes),
+	}
+	if preview.Title == "" {
+		return nil, errNoPreview
+	}
+
+	imageURL := firstNonEmpty(tags["og:image:secure_url"], tags["og:image"], tags["og:image:url"], tags["twitter:image"], tags["twitter:image:src"])
+	if imageURL == "" {
+		return preview, nil
+	}
+	if ref, err := finalURL.Parse(imageURL); err == nil {
+		imageURL = ref.String()
+	}
+	image, _, _, err := p.get(ctx, imageURL, "image/*")
+	if err != nil {
+		p.logger.Debugf("Imagem da prévia indisponível (%s): %v", imageURL, err)
+		return preview, nil
+	}
+	if thumb, err := media.MakeThumbnail(bytes.NewReader(image)); err == nil {
+		preview.Thumbnail = thumb
+	} else {
+		p.logger.Debugf("Imagem da prévia inválida (%s): %v", imageURL, err)
+	}
+	return preview, nil
+}
+
+// get baixa até MaxBytes do recurso. O conteúdo além do limite é
+// descartado: o <head> do HTML costuma caber bem antes disso.
+func (p *LinkPreviewer) get(ctx context.Context, rawURL, accept string) ([]byte, string, *url.URL, error) {
+	if err := validator.ValidateURL(rawURL); err != nil {
+		return nil, "", nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	req.Header.Set("User-Agent", previewUserAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			p.logger.Errorf("falha ao fechar corpo da resposta: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.cfg.MaxBytes))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return data, resp.Header.Get("Content-Type"), resp.Request.URL, nil
+}
+
+// parseMetaTags lê as tags <meta property|name> e o <title> do <head>. A
+// primeira ocorrência de cada chave vence.
+func parseMetaTags(r io.Reader) map[string]string {
+	tags := make(map[string]string)
+	z := html.NewTokenizer(r)
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return tags
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "meta":
+				var key, content string
+				for hasAttr {
+					var k, v []byte
+					k, v, hasAttr = z.TagAttr()
+					switch string(k) {
+					case "property", "name":
+						if key == "" {
+							key = strings.ToLower(strings.TrimSpace(string(v)))
+						}
+					case "content":
+						content = strings.TrimSpace(string(v))
+					}
+				}
+				if _, seen := tags[key]; key != "" && content != "" && !seen {
+					tags[key] = content
+				}
+			case "title":
+				inTitle = true
+			case "body":
+				return tags
+			}
+		case html.TextToken:
+			if inTitle && tags["title"] == "" {
+				tags["title"] = strings.TrimSpace(string(z.Text()))
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return tags
+			}
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
+
+// textPreview decide a prévia da mensagem de texto: a informada no pedido,
+// nenhuma quando desativada, ou a buscada para o primeiro link do texto.
+func (s *MultiTenantWhatsAppService) textPreview(ctx context.Context, req *models.MessageRequest) (*linkPreview, error) {
+	if req.Preview != nil {
+		preview := &linkPreview{
+			URL:         firstNonEmpty(req.Preview.URL, validator.FirstURL(req.Text)),
+			Title:       req.Preview.Title,
+			Description: req.Preview.Description,
+		}
+		if req.Preview.ThumbnailBase64 != "" {
+			raw, err := base64.StdEncoding.DecodeString(stripDataURI(req.Preview.ThumbnailBase64))
+			if err != nil {
+				return nil, permanent(fmt.Errorf("%w: base64 malformado: %v", ErrInvalidThumbnail, err))
+			}
+			thumb, err := media.MakeThumbnail(bytes.NewReader(raw))
+			if err != nil {
+				return nil, permanent(fmt.Errorf("%w: %v", ErrInvalidThumbnail, err))
+			}
+			preview.Thumbnail = thumb
+		}
+		return preview, nil
+	}
+
+	if !s.config.Preview.Enabled || (req.LinkPreview != nil && !*req.LinkPreview) {
+		return nil, nil
+	}
+	link := validator.FirstURL(req.Text)
+	if link == "" {
+		return nil, nil
+	}
+	return s.previews.Get(ctx, link), nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/media"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+)
+
+var (
+	ErrMediaNotFound     = errors.New("media_id não encontrado")
+	ErrMediaExpired      = errors.New("media_id expirado, faça o upload novamente")
+	ErrMediaIDNewsletter = errors.New("canais não aceitam media_id, envie o arquivo")
+)
+
+var mediaTypeNames = map[whatsmeow.MediaType]string{
+	whatsmeow.MediaImage:    "image",
+	whatsmeow.MediaVideo:    "video",
+	whatsmeow.MediaAudio:    "audio",
+	whatsmeow.MediaDocument: "document",
+}
+
+// uploadCached envia o arquivo aos servidores do WhatsApp, reaproveitando o
+// upload de conteúdo idêntico (mesmo SHA-256 e tipo de mídia) do tenant
+// enquanto ele não vence. Com pin, usado pelo pré-upload, o registro sempre
+// é gravado e passa a descrever a mídia do media_id; nos envios comuns o
+// cache é só uma otimização e falhas dele não impedem o envio.
+func (s *MultiTenantWhatsAppService) uploadCached(ctx context.Context, waClient *WhatsAppClient, file *media.File, mediaType whatsmeow.MediaType, d *mediaDescription, pin bool) (whatsmeow.UploadResponse, *models.MediaUpload, error) {
+	if !pin && !s.config.Media.Enabled {
+		uploaded, err := waClient.Client.UploadReader(ctx, file.Reader(), nil, mediaType)
+		if err == nil {
+			observeUpload(waClient, mediaTypeNames[mediaType], uploaded.FileLength)
+		}
+		return uploaded, nil, err
+	}
+
+	sum, err := file.SHA256()
+	if err != nil {
+		return whatsmeow.UploadResponse{}, nil, fmt.Errorf("falha ao ler mídia: %w", err)
+	}
+	tenantID := waClient.Session.TenantID
+	record := &models.MediaUpload{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		SHA256:    hex.EncodeToString(sum),
+		MediaType: mediaTypeNames[mediaType],
+	}
+	if err := describeUpload(record, d); err != nil {
+		return whatsmeow.UploadResponse{}, nil, err
+	}
+
+	cached, err := s.uploads.FindByContent(tenantID, record.SHA256, record.MediaType)
+	switch {
+	case err == nil && time.Now().Before(cached.ExpiresAt):
+		s.logger.Debugf("Reaproveitando upload %s (%s)", cached.ID, record.SHA256)
+		if pin {
+			// Mantém o upload e atualiza só a descrição.
+			record.URL, record.DirectPath = cached.URL, cached.DirectPath
+			record.MediaKey, record.FileEncSHA256 = cached.MediaKey, cached.FileEncSHA256
+			record.CreatedAt, record.ExpiresAt = cached.CreatedAt, cached.ExpiresAt
+			if cached, err = s.uploads.Save(record, true); err != nil {
+				return whatsmeow.UploadResponse{}, nil, err
+			}
+		}
+		return storedUpload(cached), cached, nil
+	case err != nil && !errors.Is(err, repository.ErrMediaUploadNotFound):
+		if pin {
+			return whatsmeow.UploadResponse{}, nil, err
+		}
+		s.logger.Warnf("Cache de mídia indisponível: %v", err)
+	}
+
+	uploaded, err := waClient.Client.UploadReader(ctx, file.Reader(), nil, mediaType)
+	if err != nil {
+		return uploaded, nil, err
+	}
+	observeUpload(waClient, mediaTypeNames[mediaType], uploaded.FileLength)
+
+	now := time.Now().UTC()
+	record.URL, record.DirectPath = uploaded.URL, uploaded.DirectPath
+	record.MediaKey, record.FileEncSHA256 = uploaded.MediaKey, uploaded.FileEncSHA256
+	record.CreatedAt, record.ExpiresAt = now, uploadExpiry(uploaded.URL, now, s.config.Media.TTL)
+
+	saved, err := s.uploads.Save(record, pin)
+	if err != nil {
+		if pin {
+			return whatsmeow.UploadResponse{}, nil, err
+		}
+		s.logger.Warnf("Falha ao guardar upload no cache de mídia: %v", err)
+		return uploaded, nil, nil
+	}
+	// Registros vencidos há mais de um TTL já não servem nem para responder
+	// MEDIA_EXPIRED com clareza.
+	if _, err := s.uploads.DeleteExpired(tenantID, now.Add(-s.config.Media.TTL)); err != nil {
+		s.logger.Warnf("Falha ao limpar uploads expirados: %v", err)
+	}
+	return uploaded, saved, nil
+}
+
+// UploadMedia pré-envia uma mídia e devolve o media_id para envios
+// posteriores. Conteúdo já enviado pelo tenant reaproveita o upload e o
+// media_id existentes. O arquivo é fechado em qualquer caso.
+func (s *MultiTenantWhatsAppService) UploadMedia(sessionKey, tenantID string, req *models.MediaUploadRequest, file *media.File) (*models.MediaUpload, error) {
+	defer func() { file.Close() }()
+
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	contentType, filename := req.MimeType, req.FileName
+	if file == nil {
+		file, contentType, filename, err = s.prepareMedia(req.MediaURL, req.MediaBase64, req.MimeType)
+		if err != nil {
+			return nil, err
+		}
+		if req.FileName != "" {
+			filename = req.FileName
+		}
+	}
+
+	mediaType, d, err := s.describeMedia(req.Mode, req.ThumbnailBase64, file, contentType, filename)
+	if err != nil {
+		return nil, err
+	}
+	_, record, err := s.uploadCached(ctx, waClient, file, mediaType, d, true)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao fazer upload da mídia: %w", err)
+	}
+	return record, nil
+}
+
+// GetMedia devolve o registro de um media_id do tenant.
+func (s *MultiTenantWhatsAppService) GetMedia(tenantID, mediaID string) (*models.MediaUpload, error) {
+	id, err := uuid.Parse(mediaID)
+	if err != nil {
+		return nil, ErrMediaNotFound
+	}
+	record, err := s.uploads.GetByIDAndTenant(id, tenantID)
+	if errors.Is(err, repository.ErrMediaUploadNotFound) {
+		return nil, ErrMediaNotFound
+	}
+	return record, err
+}
+
+// storedMedia carrega o upload e a descrição de um media_id ainda válido.
+func (s *MultiTenantWhatsAppService) storedMedia(tenantID, mediaID string) (whatsmeow.UploadResponse, *mediaDescription, error) {
+	record, err := s.GetMedia(tenantID, mediaID)
+	if err != nil {
+		if errors.Is(err, ErrMediaNotFound) {
+			return whatsmeow.UploadResponse{}, nil, permanent(err)
+		}
+		return whatsmeow.UploadResponse{}, nil, err
+	}
+	if !time.Now().Before(record.ExpiresAt) {
+		return whatsmeow.UploadResponse{}, nil, permanent(fmt.Errorf("%w (venceu em %s)", ErrMediaExpired, record.ExpiresAt.Format(time.RFC3339)))
+	}
+
+	d := &mediaDescription{
+		Mode:        record.Mode,
+		ContentType: record.MimeType,
+		FileName:    record.FileName,
+		Size:        record.FileLength,
+		Meta:        &mediaMeta{},
+	}
+	if err := json.Unmarshal([]byte(record.Metadata), d.Meta); err != nil {
+		return whatsmeow.UploadResponse{}, nil, fmt.Errorf("metadados da mídia %s corrompidos: %w", record.ID, err)
+	}
+	return storedUpload(record), d, nil
+}
+
+func describeUpload(record *models.MediaUpload, d *mediaDescription) error {
+	meta, err := json.Marshal(d.Meta)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar metadados da mídia: %w", err)
+	}
+	record.Mode = d.Mode
+	record.MimeType = d.ContentType
+	record.FileName = d.FileName
+	record.FileLength = d.Size
+	record.Metadata = string(meta)
+	return nil
+}
+
+func storedUpload(record *models.MediaUpload) whatsmeow.UploadResponse {
+	sum, _ := hex.DecodeString(record.SHA256)
+	return whatsmeow.UploadResponse{
+		URL:           record.URL,
+		DirectPath:    record.DirectPath,
+		MediaKey:      record.MediaKey,
+		FileEncSHA256: record.FileEncSHA256,
+		FileSHA256:    sum,
+		FileLength:    record.FileLength,
+	}
+}
+
+// uploadExpiry limita a validade do upload ao TTL configurado e ao
+// vencimento que o CDN do WhatsApp grava na URL (parâmetro oe, timestamp
+// Unix em hexadecimal), o que vier primeiro.
+func uploadExpiry(rawURL string, now time.Time, ttl time.Duration) time.Time {
+	expires := now.Add(ttl)
+	if u, err := url.Parse(rawURL); err == nil {
+		if oe, err := strconv.ParseInt(u.Query().Get("oe"), 16, 64); err == nil {
+			if t := time.Unix(oe, 0).UTC(); t.After(now) && t.Before(expires) {
+				expires = t
+			}
+		}
+	}
+	return expires
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"boot-whatsapp-golang/pkg/media"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+)
+
+var ErrInvalidThumbnail = errors.New("miniatura inválida")
+
+// mediaMeta reúne o que o app do destinatário usa para exibir a mídia antes
+// do download: miniatura, dimensões, duração, número de páginas e, nos modos
+// voice e sticker, forma de onda e animação. É gravado em JSON junto dos
+// uploads reutilizáveis.
+type mediaMeta struct {
+	Width     uint32           `json:"width,omitempty"`
+	Height    uint32           `json:"height,omitempty"`
+	Seconds   uint32           `json:"seconds,omitempty"`
+	PageCount uint32           `json:"page_count,omitempty"`
+	Thumbnail *media.Thumbnail `json:"thumbnail,omitempty"`
+	Waveform  []byte           `json:"waveform,omitempty"`
+	Animated  bool             `json:"animated,omitempty"`
+}
+
+// mediaMetadata extrai os metadados da mídia. Falhas de leitura só deixam o
+// campo vazio; a exceção é a miniatura enviada pelo cliente, que precisa
+// ser uma imagem válida.
+func (s *MultiTenantWhatsAppService) mediaMetadata(file *media.File, contentType string, mediaType whatsmeow.MediaType, thumbnailBase64 string) (*mediaMeta, error) {
+	meta := &mediaMeta{}
+
+	if thumbnailBase64 != "" {
+		raw, err := base64.StdEncoding.DecodeString(stripDataURI(thumbnailBase64))
+		if err != nil {
+			return nil, permanent(fmt.Errorf("%w: base64 malformado: %v", ErrInvalidThumbnail, err))
+		}
+		thumb, err := media.MakeThumbnail(bytes.NewReader(raw))
+		if err != nil {
+			return nil, permanent(fmt.Errorf("%w: %v", ErrInvalidThumbnail, err))
+		}
+		meta.Thumbnail = thumb
+	}
+
+	switch mediaType {
+	case whatsmeow.MediaImage:
+		if w, h, err := media.ImageSize(file.Reader()); err == nil {
+			meta.Width, meta.Height = uint32(w), uint32(h)
+		}
+		if meta.Thumbnail == nil {
+			thumb, err := media.MakeThumbnail(file.Reader())
+			if err != nil {
+				s.logger.Debugf("Miniatura não gerada para %s: %v", contentType, err)
+			}
+			meta.Thumbnail = thumb
+		}
+	case whatsmeow.MediaVideo:
+		if info, err := media.ParseMP4(file.Reader(), file.Size); err == nil {
+			meta.Width, meta.Height = uint32(info.Width), uint32(info.Height)
+			meta.Seconds = info.Seconds
+		}
+	case whatsmeow.MediaDocument:
+		if n, ok := media.PDFPageCount(file.Reader(), file.Size); ok {
+			meta.PageCount = uint32(n)
+		}
+	}
+	return meta, nil
+}
+
+func stripDataURI(b string) string {
+	b = strings.TrimSpace(b)
+	if strings.HasPrefix(b, "data:") {
+		if idx := strings.IndexByte(b, ','); idx != -1 {
+			b = b[idx+1:]
+		}
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/pkg/media"
+	"errors"
+	"fmt"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// Limites de figurinha aceitos pelo WhatsApp.
+const (
+	stickerSize             = 512
+	maxStaticStickerBytes   = 100 << 10
+	maxAnimatedStickerBytes = 500 << 10
+)
+
+var (
+	ErrMediaTooLarge     = media.ErrTooLarge
+	ErrMediaTypeMismatch = media.ErrTypeMismatch
+	ErrMediaURLBlocked   = errors.New("media_url bloqueada pela política de download")
+)
+
+var (
+	ErrUnsupportedAudioFormat   = errors.New("mensagens de voz exigem áudio Ogg/Opus")
+	ErrUnsupportedStickerFormat = errors.New("figurinhas exigem imagem WebP")
+	ErrStickerDimensions        = errors.New("figurinhas devem ter 512x512 pixels")
+	ErrStickerTooLarge          = errors.New("figurinha excede o tamanho máximo")
+)
+
+// mediaDescription é o que a mensagem precisa além do upload. Vem da
+// análise do arquivo ou, nos envios por media_id, do registro do pré-upload.
+type mediaDescription struct {
+	Mode        string
+	ContentType string
+	FileName    string
+	Size        uint64
+	Meta        *mediaMeta
+}
+
+// describeMedia valida a mídia para o modo pedido e devolve o tipo de upload
+// e a descrição da mensagem. Sem modo, o tipo segue o mime_type.
+func (s *MultiTenantWhatsAppService) describeMedia(mode, thumbnailBase64 string, file *media.File, contentType, filename string) (whatsmeow.MediaType, *mediaDescription, error) {
+	d := &mediaDescription{Mode: mode, ContentType: contentType, FileName: filename, Size: uint64(file.Size)}
+
+	switch mode {
+	case models.MediaModeVoice:
+		info, err := media.ParseOggOpus(file.Reader())
+		if err != nil {
+			return "", nil, permanent(fmt.Errorf("%w: %v", ErrUnsupportedAudioFormat, err))
+		}
+		d.ContentType = "audio/ogg; codecs=opus"
+		d.Meta = &mediaMeta{Seconds: info.Seconds, Waveform: info.Waveform}
+		return whatsmeow.MediaAudio, d, nil
+
+	case models.MediaModeSticker:
+		info, err := media.ParseWebP(file.Reader())
+		if err != nil {
+			return "", nil, permanent(fmt.Errorf("%w: %v", ErrUnsupportedStickerFormat, err))
+		}
+		if info.Width != stickerSize || info.Height != stickerSize {
+			return "", nil, permanent(fmt.Errorf("%w (recebido %dx%d)", ErrStickerDimensions, info.Width, info.Height))
+		}
+		limit := int64(maxStaticStickerBytes)
+		if info.Animated {
+			limit = maxAnimatedStickerBytes
+		}
+		if file.Size > limit {
+			return "", nil, permanent(fmt.Errorf("%w de %d KB (recebido %d KB)", ErrStickerTooLarge, limit>>10, file.Size>>10))
+		}
+		d.ContentType = "image/webp"
+		d.Meta = &mediaMeta{Width: uint32(info.Width), Height: uint32(info.Height), Animated: info.Animated}
+		// Figurinhas usam as chaves de mídia de imagem.
+		return whatsmeow.MediaImage, d, nil
+
+	default:
+		mediaType := s.determineMediaType(contentType)
+		meta, err := s.mediaMetadata(file, contentType, mediaType, thumbnailBase64)
+		if err != nil {
+			return "", nil, err
+		}
+		d.Meta = meta
+		return mediaType, d, nil
+	}
+}
+
+// buildMedia monta a mensagem depois do upload, quando URL e chaves já são
+// conhecidas.
+func (s *MultiTenantWhatsAppService) buildMedia(uploaded whatsmeow.UploadResponse, d *mediaDescription, caption string) *waE2E.Message {
+	switch d.Mode {
+	case models.MediaModeVoice:
+		return &waE2E.Message{
+			AudioMessage: &waE2E.AudioMessage{
+				URL:           proto.String(uploaded.URL),
+				DirectPath:    proto.String(uploaded.DirectPath),
+				MediaKey:      uploaded.MediaKey,
+				Mimetype:      proto.String(d.ContentType),
+				FileEncSHA256: uploaded.FileEncSHA256,
+				FileSHA256:    uploaded.FileSHA256,
+				FileLength:    proto.Uint64(d.Size),
+				Seconds:       proto.Uint32(d.Meta.Seconds),
+				PTT:           proto.Bool(true),
+				Waveform:      d.Meta.Waveform,
+			},
+		}
+	case models.MediaModeSticker:
+		return &waE2E.Message{
+			StickerMessage: &waE2E.StickerMessage{
+				URL:           proto.String(uploaded.URL),
+				DirectPath:    proto.String(uploaded.DirectPath),
+				MediaKey:      uploaded.MediaKey,
+				Mimetype:      proto.String(d.ContentType),
+				FileEncSHA256: uploaded.FileEncSHA256,
+				FileSHA256:    uploaded.FileSHA256,
+				FileLength:    proto.Uint64(d.Size),
+				Width:         proto.Uint32(d.Meta.Width),
+				Height:        proto.Uint32(d.Meta.Height),
+				IsAnimated:    proto.Bool(d.Meta.Animated),
+			},
+		}
+	default:
+		return s.buildMediaMessage(uploaded, d.Size, d.ContentType, caption, d.FileName, d.Meta)
+	}
+}
+
+// setMediaContext anexa menções e citação à mensagem de mídia montada.
+func setMediaContext(msg *waE2E.Message, ctxInfo *waE2E.ContextInfo) {
+	if ctxInfo == nil {
+		return
+	}
+	switch {
+	case msg.ImageMessage != nil:
+		msg.ImageMessage.ContextInfo = ctxInfo
+	case msg.VideoMessage != nil:
+		msg.VideoMessage.ContextInfo = ctxInfo
+	case msg.AudioMessage != nil:
+		msg.AudioMessage.ContextInfo = ctxInfo
+	case msg.StickerMessage != nil:
+		msg.StickerMessage.ContextInfo = ctxInfo
+	case msg.DocumentMessage != nil:
+		msg.DocumentMessage.ContextInfo = ctxInfo
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	MessageActionReact   = "react"
+	MessageActionUnreact = "unreact"
+	MessageActionEdit    = "edit"
+	MessageActionRevoke  = "revoke"
+)
+
+var (
+	ErrQuotedMessageNotFound = errors.New("mensagem citada não encontrada para esta sessão")
+	ErrMessageRevoked        = errors.New("a mensagem foi apagada")
+	ErrMessageNotEditable    = errors.New("apenas mensagens de texto enviadas pela sessão podem ser editadas")
+	ErrEditWindowExpired     = errors.New("prazo de edição da mensagem expirou")
+	ErrMessageNotRevocable   = errors.New("mensagens recebidas só podem ser apagadas em grupos, por admins")
+)
+
+// messageContext monta o ContextInfo de envio com menções e, se informado, a
+// mensagem citada. A citação usa o resumo persistido como prévia; o WhatsApp
+// do destinatário exibe o conteúdo original quando o tem localmente.
+func (s *MultiTenantWhatsAppService) messageContext(waClient *WhatsAppClient, to types.JID, mentions []string, quotedID string) (*waE2E.ContextInfo, error) {
+	mentioned, err := s.resolveMentions(mentions)
+	if err != nil {
+		return nil, err
+	}
+	if len(mentioned) == 0 && quotedID == "" {
+		return nil, nil
+	}
+
+	ctxInfo := &waE2E.ContextInfo{MentionedJID: mentioned}
+	if quotedID == "" {
+		return ctxInfo, nil
+	}
+
+	quoted, err := s.messages.GetBySession(waClient.Session.ID, quotedID)
+	if errors.Is(err, ErrMessageNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrQuotedMessageNotFound, quotedID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	participant := quoted.SenderJID
+	if participant == "" {
+		participant = waClient.Client.Store.ID.ToNonAD().String()
+	}
+	ctxInfo.StanzaID = proto.String(quoted.MessageID)
+	ctxInfo.Participant = proto.String(participant)
+	ctxInfo.QuotedMessage = &waE2E.Message{Conversation: proto.String(quoted.PayloadSummary)}
+	if quoted.RecipientJID != to.String() {
+		ctxInfo.RemoteJID = proto.String(quoted.RecipientJID)
+	}
+	return ctxInfo, nil
+}
+
+// targetMessage carrega a mensagem alvo de uma ação e o cliente conectado
+// da sessão dona dela.
+func (s *MultiTenantWhatsAppService) targetMessage(sessionKey, tenantID, messageID string) (*WhatsAppClient, *models.Message, types.JID, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, nil, types.JID{}, err
+	}
+	msg, err := s.messages.GetBySession(session.ID, messageID)
+	if err != nil {
+		return nil, nil, types.JID{}, err
+	}
+	if msg.RevokedAt != nil {
+		return nil, nil, types.JID{}, ErrMessageRevoked
+	}
+	chat, err := types.ParseJID(msg.RecipientJID)
+	if err != nil {
+		return nil, nil, types.JID{}, fmt.Errorf("chat da mensagem inválido: %w", err)
+	}
+	waClient, err := s.getConnectedClient(session.TenantID, session.WhatsAppSessionKey)
+	if err != nil {
+		return nil, nil, types.JID{}, fmt.Errorf("%w: %v", ErrSessionNotConnected, err)
+	}
+	return waClient, msg, chat, nil
+}
+
+func messageSender(msg *models.Message) types.JID {
+	if msg.SenderJID == "" {
+		return types.EmptyJID
+	}
+	jid, err := types.ParseJID(msg.SenderJID)
+	if err != nil {
+		return types.EmptyJID
+	}
+	return jid
+}
+
+func (s *MultiTenantWhatsAppService) sendMessageAction(ctx context.Context, waClient *WhatsAppClient, chat types.JID, target, action string, msg *waE2E.Message) (*models.MessageAction, error) {
+	resp, err := waClient.Client.SendMessage(ctx, chat, msg)
+	observeSend(waClient, action, err)
+	if err != nil {
+		if errors.Is(err, whatsmeow.ErrNotConnected) {
+			return nil, fmt.Errorf("%w: %v", ErrSessionNotConnected, err)
+		}
+		return nil, sendError(chat, err, "falha ao enviar "+action)
+	}
+	sentAt := resp.Timestamp
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+	return &models.MessageAction{
+		MessageID:       resp.ID,
+		TargetMessageID: target,
+		Action:          action,
+		SentAt:          sentAt,
+	}, nil
+}
+
+// ReactToMessage reage com um emoji; emoji vazio remove a reação da sessão.
+func (s *MultiTenantWhatsAppService) ReactToMessage(sessionKey, tenantID, messageID, emoji string) (*models.MessageAction, error) {
+	waClient, target, chat, err := s.targetMessage(sessionKey, tenantID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	action := MessageActionReact
+	if emoji == "" {
+		action = MessageActionUnreact
+	}
+	msg := waClient.Client.BuildReaction(chat, messageSender(target), target.MessageID, emoji)
+	return s.sendMessageAction(ctx, waClient, chat, target.MessageID, action, msg)
+}
+
+// EditMessage substitui o texto de uma mensagem enviada pela sessão, dentro
+// da janela de edição do WhatsApp (whatsmeow.EditWindow).
+func (s *MultiTenantWhatsAppService) EditMessage(sessionKey, tenantID, messageID, text string) (*models.MessageAction, error) {
+	waClient, target, chat, err := s.targetMessage(sessionKey, tenantID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Direction != models.MessageDirectionOutbound || target.Type != "text" {
+		return nil, ErrMessageNotEditable
+	}
+	if time.Since(target.ServerTimestamp) > whatsmeow.EditWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	content := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String(text)}}
+	result, err := s.sendMessageAction(ctx, waClient, chat, target.MessageID, MessageActionEdit,
+		waClient.Client.BuildEdit(chat, target.MessageID, content))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.messages.MarkEdited(target.SessionID, target.MessageID, summarizeMessage(content), result.SentAt); err != nil {
+		s.logger.Errorf("[%s] %v", sessionKey, err)
+	}
+	return result, nil
+}
+
+// RevokeMessage apaga a mensagem para todos. Mensagens recebidas só podem
+// ser apagadas em grupos, e o WhatsApp exige que a sessão seja admin.
+func (s *MultiTenantWhatsAppService) RevokeMessage(sessionKey, tenantID, messageID string) (*models.MessageAction, error) {
+	waClient, target, chat, err := s.targetMessage(sessionKey, tenantID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := types.EmptyJID
+	if target.Direction == models.MessageDirectionInbound {
+		if chat.Server != types.GroupServer {
+			return nil, ErrMessageNotRevocable
+		}
+		sender = messageSender(target)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.sendMessageAction(ctx, waClient, chat, target.MessageID, MessageActionRevoke,
+		waClient.Client.BuildRevoke(chat, sender, target.MessageID))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.messages.MarkRevoked(target.SessionID, target.MessageID, result.SentAt); err != nil {
+		s.logger.Errorf("[%s] %v", sessionKey, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const maxPayloadSummaryLen = 280
+
+var ErrMessageNotFound = repository.ErrMessageNotFound
+
+// recordOutboundMessage persiste a mensagem recém-enviada para que recibos
+// posteriores possam ser correlacionados pelo ID devolvido pelo WhatsApp.
+// Falhas de persistência não invalidam o envio, que já aconteceu.
+func (s *MultiTenantWhatsAppService) recordOutboundMessage(waClient *WhatsAppClient, recipient string, to types.JID, msg *waE2E.Message, resp whatsmeow.SendResponse) *models.MessageSent {
+	session := waClient.Session
+	msgType, _ := messageContentSummary(msg)
+	serverTS := resp.Timestamp
+	if serverTS.IsZero() {
+		serverTS = time.Now()
+	}
+
+	now := time.Now()
+	record := &models.Message{
+		ID:              uuid.New(),
+		MessageID:       resp.ID,
+		SessionID:       session.ID,
+		TenantID:        session.TenantID,
+		Direction:       models.MessageDirectionOutbound,
+		RecipientJID:    to.String(),
+		SenderJID:       waClient.Client.Store.ID.ToNonAD().String(),
+		Type:            msgType,
+		PayloadSummary:  summarizeMessage(msg),
+		Status:          models.MessageStatusSent,
+		ServerTimestamp: serverTS,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.messages.Create(record); err != nil {
+		s.logger.Errorf("[%s] Falha ao registrar mensagem %s: %v", session.WhatsAppSessionKey, resp.ID, err)
+	}
+	observeSend(waClient, msgType, nil)
+
+	return &models.MessageSent{
+		MessageID:    resp.ID,
+		Recipient:    recipient,
+		RecipientJID: to.String(),
+		Type:         msgType,
+		Status:       models.MessageStatusSent,
+		SentAt:       serverTS,
+	}
+}
+
+// recordInboundMessage persiste as mensagens recebidas (e as enviadas pelo
+// próprio celular) para que possam ser citadas, reagidas ou apagadas pela
+// API. Edições e revogações atualizam a mensagem original; reações não são
+// mensagens e seguem apenas como eventos (votos são tratados em polls.go).
+func (s *MultiTenantWhatsAppService) recordInboundMessage(session *models.WhatsAppSession, evt *events.Message) {
+	ts := evt.Info.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	if pm := evt.Message.GetProtocolMessage(); pm != nil {
+		var err error
+		switch pm.GetType() {
+		case waE2E.ProtocolMessage_MESSAGE_EDIT:
+			err = s.messages.MarkEdited(session.ID, pm.GetKey().GetID(), summarizeMessage(pm.GetEditedMessage()), ts)
+		case waE2E.ProtocolMessage_REVOKE:
+			err = s.messages.MarkRevoked(session.ID, pm.GetKey().GetID(), ts)
+		}
+		if err != nil {
+			s.logger.Errorf("[%s] %v", session.WhatsAppSessionKey, err)
+		}
+		return
+	}
+
+	msgType, _ := messageContentSummary(evt.Message)
+	switch msgType {
+	case "reaction", "poll_vote", "unknown":
+		return
+	}
+
+	direction, status := models.MessageDirectionInbound, models.MessageStatusDelivered
+	if evt.Info.IsFromMe {
+		direction, status = models.MessageDirectionOutbound, models.MessageStatusSent
+	}
+	now := time.Now()
+	record := &models.Message{
+		ID:              uuid.New(),
+		MessageID:       evt.Info.ID,
+		SessionID:       session.ID,
+		TenantID:        session.TenantID,
+		Direction:       direction,
+		RecipientJID:    evt.Info.Chat.String(),
+		SenderJID:       evt.Info.Sender.ToNonAD().String(),
+		Type:            msgType,
+		PayloadSummary:  summarizeMessage(evt.Message),
+		Status:          status,
+		ServerTimestamp: ts,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.messages.Create(record); err != nil {
+		s.logger.Errorf("[%s] Falha ao registrar mensagem recebida %s: %v", session.WhatsAppSessionKey, evt.Info.ID, err)
+	}
+	s.recordInboundPoll(session, evt)
+}
+
+func (s *MultiTenantWhatsAppService) applyReceipt(session *models.WhatsAppSession, evt *events.Receipt) {
+	if evt.IsFromMe {
+		return
+	}
+
+	var status string
+	switch evt.Type {
+	case types.ReceiptTypeDelivered:
+		status = models.MessageStatusDelivered
+	case types.ReceiptTypeRead:
+		status = models.MessageStatusRead
+	case types.ReceiptTypePlayed:
+		status = models.MessageStatusPlayed
+	default:
+		return
+	}
+
+	ts := evt.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	if _, err := s.messages.AdvanceStatus(session.ID, evt.MessageIDs, status, ts); err != nil {
+		s.logger.Errorf("[%s] Falha ao aplicar recibo %s: %v", session.WhatsAppSessionKey, status, err)
+	}
+}
+
+func (s *MultiTenantWhatsAppService) GetMessage(tenantID, messageID string) (*models.Message, error) {
+	return s.messages.GetByMessageID(tenantID, messageID)
+}
+
+func (s *MultiTenantWhatsAppService) ListMessages(sessionKey string, filter models.MessageFilter) ([]*models.Message, error) {
+	if sessionKey != "" {
+		session, err := s.resolveSession(sessionKey, filter.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		filter.SessionID = &session.ID
+	}
+
+	if filter.RecipientJID != "" && !strings.Contains(filter.RecipientJID, "@") {
+		jid, err := s.parsePhoneNumber(filter.RecipientJID)
+		if err != nil {
+			return nil, err
+		}
+		filter.RecipientJID = jid.String()
+	}
+
+	return s.messages.List(filter)
+}
+
+func summarizeMessage(msg *waE2E.Message) string {
+	msgType, text := messageContentSummary(msg)
+	summary := text
+	if msgType != "text" {
+		_, filename := messageMediaInfo(msg)
+		parts := []string{"[" + msgType + "]"}
+		if filename != "" {
+			parts = append(parts, filename)
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+		summary = strings.Join(parts, " ")
+	}
+
+	if utf8.RuneCountInString(summary) > maxPayloadSummaryLen {
+		runes := []rune(summary)
+		summary = string(runes[:maxPayloadSummaryLen-1]) + "…"
+	}
+	return summary
+}
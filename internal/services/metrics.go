@@ -0,0 +1,74 @@
+package services
+
+import (
+	"boot-whatsapp-golang/pkg/metrics"
+)
+
+// Métricas de envio e conexão, expostas no /metrics. Os labels tenant e
+// session vêm de sessões cadastradas e type de messageContentSummary ou
+// mediaTypeNames; as séries de uma sessão somem quando ela é removida.
+var (
+	messagesSent = metrics.Default.NewCounterVec("whatsapp_messages_sent_total",
+		"Mensagens enviadas com sucesso, por tenant, sessão e tipo.",
+		"tenant", "session", "type")
+	messagesFailed = metrics.Default.NewCounterVec("whatsapp_messages_failed_total",
+		"Envios recusados pelo WhatsApp, por tenant, sessão e tipo. Envios barrados antes de chegar ao WhatsApp, como os de sessão desconectada, não contam.",
+		"tenant", "session", "type")
+	mediaUploadedBytes = metrics.Default.NewCounterVec("whatsapp_media_uploaded_bytes_total",
+		"Bytes de mídia enviados aos servidores do WhatsApp (uploads reaproveitados não contam).",
+		"tenant", "session", "type")
+	qrCodesGenerated = metrics.Default.NewCounterVec("whatsapp_qr_codes_generated_total",
+		"QR codes de pareamento gerados, por tenant e sessão.",
+		"tenant", "session")
+	reconnectAttempts = metrics.Default.NewCounterVec("whatsapp_reconnect_attempts_total",
+		"Tentativas de reconexão automática, por tenant, sessão e resultado (success ou failure).",
+		"tenant", "session", "result")
+	webhookDeliveries = metrics.Default.NewCounterVec("whatsapp_webhook_deliveries_total",
+		"Tentativas de entrega de webhook, por evento e resultado (delivered, failed ou dead).",
+		"event", "result")
+)
+
+// registerGauges expõe o estado do clientStore, lido a cada scrape.
+func (s *MultiTenantWhatsAppService) registerGauges() {
+	metrics.Default.NewGaugeFunc("whatsapp_session_connected",
+		"1 quando a sessão está conectada e autenticada no WhatsApp, 0 caso contrário.",
+		[]string{"tenant", "session"},
+		func(set func(float64, ...string)) {
+			s.clients.Range(func(_ string, c *WhatsAppClient) {
+				connected := 0.0
+				if c.Client.IsConnected() && c.Client.IsLoggedIn() {
+					connected = 1
+				}
+				set(connected, c.Session.TenantID, c.Session.WhatsAppSessionKey)
+			})
+		})
+	metrics.Default.NewGaugeFunc("whatsapp_clients",
+		"Clientes WhatsApp mantidos em memória (tamanho do clientStore).",
+		nil,
+		func(set func(float64, ...string)) {
+			n := 0
+			s.clients.Range(func(string, *WhatsAppClient) { n++ })
+			set(float64(n))
+		})
+}
+
+func observeSend(waClient *WhatsAppClient, msgType string, err error) {
+	session := waClient.Session
+	if err != nil {
+		messagesFailed.Inc(session.TenantID, session.WhatsAppSessionKey, msgType)
+		return
+	}
+	messagesSent.Inc(session.TenantID, session.WhatsAppSessionKey, msgType)
+}
+
+func observeUpload(waClient *WhatsAppClient, mediaType string, bytes uint64) {
+	session := waClient.Session
+	mediaUploadedBytes.Add(float64(bytes), session.TenantID, session.WhatsAppSessionKey, mediaType)
+}
+
+// forgetSessionMetrics descarta as séries de uma sessão removida.
+func forgetSessionMetrics(tenantID, sessionKey string) {
+	for _, c := range []*metrics.CounterVec{messagesSent, messagesFailed, mediaUploadedBytes, qrCodesGenerated, reconnectAttempts} {
+		c.Delete(tenantID, sessionKey)
+	}
+}
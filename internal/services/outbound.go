@@ -0,0 +1,238 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+)
+
+var (
+	ErrOutboundJobNotFound = repository.ErrOutboundJobNotFound
+
+	// ErrScheduleNotPending indica que o agendamento já saiu da fila (em
+	// envio, enviado, falho ou cancelado) e não pode mais ser alterado.
+	ErrScheduleNotPending = errors.New("envio agendado não está mais pendente")
+
+	// ErrQueuedMediaBase64 recusa media_base64 na fila: o arquivo iria
+	// inteiro para o payload do job e seria relido a cada tentativa.
+	ErrQueuedMediaBase64 = errors.New("media_base64 não é aceito em envio assíncrono ou agendado")
+)
+
+func (s *MultiTenantWhatsAppService) EnqueueTextMessage(sessionKey, tenantID string, req *models.MessageRequest) (*models.OutboundJobAccepted, error) {
+	payload := *req
+	payload.Async = false
+	payload.SendAt = nil
+	return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindText, req.Number, payload, req.SendAt)
+}
+
+func (s *MultiTenantWhatsAppService) EnqueueMediaMessage(sessionKey, tenantID string, req *models.MediaRequest) (*models.OutboundJobAccepted, error) {
+	if req.MediaBase64 != "" {
+		return nil, ErrQueuedMediaBase64
+	}
+	payload := *req
+	payload.Async = false
+	payload.SendAt = nil
+	return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindMedia, req.Number, payload, req.SendAt)
+}
+
+// enqueueOutbound grava o envio na fila da sessão. Com sendAt o job só é
+// processado a partir desse horário; sem ele, assim que chegar a sua vez.
+func (s *MultiTenantWhatsAppService) enqueueOutbound(sessionKey, tenantID, kind, recipient string, payload any, sendAt *time.Time) (*models.OutboundJobAccepted, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao serializar envio: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job := &models.OutboundJob{
+		ID:            uuid.New(),
+		SessionID:     session.ID,
+		TenantID:      session.TenantID,
+		SessionKey:    session.WhatsAppSessionKey,
+		Kind:          kind,
+		Recipient:     recipient,
+		Payload:       body,
+		Status:        models.OutboundJobQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if sendAt != nil {
+		at := sendAt.UTC()
+		job.ScheduledAt = &at
+		if at.After(now) {
+			job.NextAttemptAt = at
+		}
+	}
+	if err := s.queue.Enqueue(job); err != nil {
+		return nil, err
+	}
+
+	return &models.OutboundJobAccepted{
+		JobID:       job.ID,
+		Status:      job.Status,
+		Recipient:   recipient,
+		Type:        kind,
+		QueuedAt:    now,
+		ScheduledAt: job.ScheduledAt,
+	}, nil
+}
+
+// executeOutboundJob é chamado pelo worker da sessão. Sessões desconectadas
+// devolvem errSessionUnavailable para que o job aguarde a reconexão.
+func (s *MultiTenantWhatsAppService) executeOutboundJob(ctx context.Context, job *models.OutboundJob) (string, error) {
+	waClient, err := s.getConnectedClient(job.TenantID, job.SessionKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errSessionUnavailable, err)
+	}
+
+	var sent *models.MessageSent
+	switch job.Kind {
+	case models.OutboundKindText:
+		var req models.MessageRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", permanent(fmt.Errorf("payload do job inválido: %w", err))
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		sent, err = s.sendText(sendCtx, waClient, &req)
+
+	case models.OutboundKindMedia:
+		var req models.MediaRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", permanent(fmt.Errorf("payload do job inválido: %w", err))
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		sent, err = s.sendMedia(sendCtx, waClient, &req, nil)
+
+	case models.OutboundKindLocation:
+		var req models.LocationRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", permanent(fmt.Errorf("payload do job inválido: %w", err))
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		sent, err = s.sendLocation(sendCtx, waClient, &req)
+
+	case models.OutboundKindContacts:
+		var req models.ContactsRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", permanent(fmt.Errorf("payload do job inválido: %w", err))
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		sent, err = s.sendContacts(sendCtx, waClient, &req)
+
+	case models.OutboundKindPoll:
+		var req models.PollRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", permanent(fmt.Errorf("payload do job inválido: %w", err))
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		sent, err = s.sendPoll(sendCtx, waClient, &req)
+
+	default:
+		return "", permanent(fmt.Errorf("tipo de envio desconhecido: %s", job.Kind))
+	}
+
+	if err != nil {
+		if errors.Is(err, whatsmeow.ErrNotConnected) {
+			return "", fmt.Errorf("%w: %v", errSessionUnavailable, err)
+		}
+		return "", err
+	}
+
+	// Envios por template carregam a versão usada junto do payload.
+	var usage models.TemplateUsage
+	if json.Unmarshal(job.Payload, &usage) == nil && usage.TemplateID != uuid.Nil {
+		s.recordTemplateUsage(waClient.Session, sent.MessageID, usage)
+	}
+	return sent.MessageID, nil
+}
+
+func (s *MultiTenantWhatsAppService) GetOutboundJob(tenantID string, jobID uuid.UUID) (*models.OutboundJob, error) {
+	return s.queue.repo.GetByTenant(jobID, tenantID)
+}
+
+func (s *MultiTenantWhatsAppService) GetQueueDepth(sessionKey, tenantID string) (*models.QueueDepth, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := s.queue.repo.DepthBySession(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	depth.SessionKey = session.WhatsAppSessionKey
+	return depth, nil
+}
+
+func (s *MultiTenantWhatsAppService) ListScheduled(sessionKey, tenantID string, filter models.ScheduledFilter) ([]*models.OutboundJob, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.queue.repo.ListScheduled(session.ID, filter)
+}
+
+// CancelScheduled cancela um envio agendado que ainda aguarda na fila.
+func (s *MultiTenantWhatsAppService) CancelScheduled(sessionKey, tenantID string, jobID uuid.UUID) (*models.OutboundJob, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	canceled, err := s.queue.repo.CancelScheduled(jobID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.queue.repo.GetScheduled(jobID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !canceled {
+		return job, ErrScheduleNotPending
+	}
+	// O job cancelado pode ser o que o worker aguardava.
+	s.queue.Notify(session.ID)
+	s.logger.Infof("[%s] Envio agendado %s cancelado", sessionKey, jobID)
+	return job, nil
+}
+
+// RescheduleMessage move um envio agendado ainda pendente para sendAt.
+func (s *MultiTenantWhatsAppService) RescheduleMessage(sessionKey, tenantID string, jobID uuid.UUID, sendAt time.Time) (*models.OutboundJob, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := s.queue.repo.Reschedule(jobID, session.ID, sendAt)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.queue.repo.GetScheduled(jobID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return job, ErrScheduleNotPending
+	}
+	s.queue.Notify(session.ID)
+	s.logger.Infof("[%s] Envio agendado %s remarcado para %s", sessionKey, jobID, sendAt.Format(time.RFC3339))
+	return job, nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrQueueStopped = errors.New("fila de envio encerrada")
+
+	// errSessionUnavailable indica que a sessão está desconectada: o job
+	// aguarda a reconexão sem consumir tentativas.
+	errSessionUnavailable = errors.New("sessão indisponível para envio")
+)
+
+// permanentSendError marca falhas que não se resolvem com retry (número ou
+// mídia inválidos); o job é encerrado na primeira ocorrência.
+type permanentSendError struct {
+	err error
+}
+
+func (e *permanentSendError) Error() string { return e.err.Error() }
+func (e *permanentSendError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentSendError{err: err}
+}
+
+type outboundExecutor func(ctx context.Context, job *models.OutboundJob) (string, error)
+
+// OutboundQueue processa os envios assíncronos com um worker por sessão. Cada
+// worker consome os jobs da sua sessão em ordem de criação; os jobs ficam no
+// banco, então nada se perde entre restarts.
+type OutboundQueue struct {
+	repo    *repository.OutboundRepository
+	cfg     config.QueueConfig
+	logger  *logger.Logger
+	execute outboundExecutor
+
+	mu       sync.Mutex
+	workers  map[uuid.UUID]chan struct{}
+	stopping bool
+	wg       sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+}
+
+func NewOutboundQueue(repo *repository.OutboundRepository, cfg config.QueueConfig, log *logger.Logger, execute outboundExecutor) *OutboundQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboundQueue{
+		repo:    repo,
+		cfg:     cfg,
+		logger:  log,
+		execute: execute,
+		workers: make(map[uuid.UUID]chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start retoma os jobs que ficaram pendentes na execução anterior.
+func (q *OutboundQueue) Start() {
+	if n, err := q.repo.RequeueProcessing(); err != nil {
+		q.logger.Errorf("Falha ao recuperar envios em processamento: %v", err)
+	} else if n > 0 {
+		q.logger.Infof("%d envios interrompidos foram devolvidos à fila", n)
+	}
+
+	sessions, err := q.repo.SessionsWithPending()
+	if err != nil {
+		q.logger.Errorf("Falha ao listar sessões com envios pendentes: %v", err)
+		return
+	}
+	for _, sessionID := range sessions {
+		q.wakeWorker(sessionID)
+	}
+}
+
+// Stop deixa os workers concluírem o job em andamento por até DrainTimeout;
+// depois disso os envios são cancelados e voltam para a fila. Jobs ainda não
+// iniciados permanecem persistidos e são retomados no próximo Start.
+func (q *OutboundQueue) Stop() {
+	q.mu.Lock()
+	if q.stopping {
+		q.mu.Unlock()
+		return
+	}
+	q.stopping = true
+	q.mu.Unlock()
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(q.cfg.DrainTimeout):
+		q.logger.Warn("Tempo de drenagem da fila de envio esgotado, cancelando envios em andamento")
+		q.cancel()
+		<-done
+	}
+	q.cancel()
+
+	if _, err := q.repo.RequeueProcessing(); err != nil {
+		q.logger.Errorf("Falha ao devolver envios em processamento à fila: %v", err)
+	}
+}
+
+func (q *OutboundQueue) Enqueue(job *models.OutboundJob) error {
+	q.mu.Lock()
+	stopping := q.stopping
+	q.mu.Unlock()
+	if stopping {
+		return ErrQueueStopped
+	}
+
+	if err := q.repo.Create(job); err != nil {
+		return err
+	}
+	q.wakeWorker(job.SessionID)
+	return nil
+}
+
+// Notify acorda o worker da sessão, se existir (ex.: após reconectar).
+func (q *OutboundQueue) Notify(sessionID uuid.UUID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if wake, ok := q.workers[sessionID]; ok {
+		wakeUp(wake)
+	}
+}
+
+func (q *OutboundQueue) wakeWorker(sessionID uuid.UUID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stopping {
+		return
+	}
+
+	wake, ok := q.workers[sessionID]
+	if !ok {
+		wake = make(chan struct{}, 1)
+		q.workers[sessionID] = wake
+		q.wg.Add(1)
+		go q.worker(sessionID, wake)
+	}
+	wakeUp(wake)
+}
+
+// retire encerra o worker ocioso, a menos que um Enqueue tenha chegado
+// entre a consulta da fila vazia e este ponto.
+func (q *OutboundQueue) retire(sessionID uuid.UUID, wake chan struct{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-wake:
+		return false
+	default:
+	}
+	delete(q.workers, sessionID)
+	return true
+}
+
+func (q *OutboundQueue) worker(sessionID uuid.UUID, wake chan struct{}) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		job, err := q.repo.NextForSession(sessionID)
+		if err != nil {
+			q.logger.Errorf("Falha ao consultar fila de envio da sessão %s: %v", sessionID, err)
+			if !q.sleep(wake, q.cfg.PollInterval) {
+				return
+			}
+			continue
+		}
+		if job == nil {
+			if q.retire(sessionID, wake) {
+				return
+			}
+			continue
+		}
+
+		if wait := time.Until(job.NextAttemptAt); wait > 0 {
+			if !q.sleep(wake, wait) {
+				return
+			}
+			continue
+		}
+
+		q.process(job)
+	}
+}
+
+func (q *OutboundQueue) sleep(wake chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-q.stop:
+		return false
+	case <-wake:
+	case <-timer.C:
+	}
+	return true
+}
+
+func (q *OutboundQueue) process(job *models.OutboundJob) {
+	claimed, err := q.repo.MarkProcessing(job.ID)
+	if err != nil {
+		q.logger.Errorf("Falha ao iniciar job de envio %s: %v", job.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	attempts := job.Attempts + 1
+	messageID, err := q.execute(q.ctx, job)
+
+	var limited *RateLimitError
+	switch {
+	case err == nil:
+		if markErr := q.repo.MarkSent(job.ID, attempts, messageID); markErr != nil {
+			q.logger.Errorf("Falha ao marcar job de envio %s como enviado: %v", job.ID, markErr)
+		}
+
+	case q.ctx.Err() != nil:
+		// Cancelado pelo desligamento: volta para a fila sem consumir tentativa.
+		if markErr := q.repo.MarkRetry(job.ID, job.Attempts, time.Now(), "envio interrompido pelo desligamento"); markErr != nil {
+			q.logger.Errorf("Falha ao devolver job de envio %s à fila: %v", job.ID, markErr)
+		}
+
+	case errors.As(err, &limited):
+		// Limite de envio atingido: o job espera o próximo token sem consumir tentativa.
+		next := time.Now().Add(limited.RetryAfter)
+		if markErr := q.repo.MarkRetry(job.ID, job.Attempts, next, err.Error()); markErr != nil {
+			q.logger.Errorf("Falha ao reagendar job de envio %s: %v", job.ID, markErr)
+		}
+
+	case errors.Is(err, errSessionUnavailable):
+		next := time.Now().Add(q.cfg.PollInterval)
+		if markErr := q.repo.MarkRetry(job.ID, job.Attempts, next, err.Error()); markErr != nil {
+			q.logger.Errorf("Falha ao reagendar job de envio %s: %v", job.ID, markErr)
+		}
+		q.failOverdueScheduled(job)
+
+	default:
+		var perm *permanentSendError
+		if errors.As(err, &perm) || attempts >= q.cfg.MaxAttempts {
+			q.logger.Warnf("[%s] Job de envio %s falhou definitivamente após %d tentativas: %v", job.SessionKey, job.ID, attempts, err)
+			if markErr := q.repo.MarkFailed(job.ID, attempts, err.Error()); markErr != nil {
+				q.logger.Errorf("Falha ao marcar job de envio %s como falho: %v", job.ID, markErr)
+			}
+			return
+		}
+
+		q.logger.Warnf("[%s] Falha no job de envio %s (tentativa %d): %v", job.SessionKey, job.ID, attempts, err)
+		next := time.Now().Add(retryBackoff(q.cfg.InitialBackoff, q.cfg.MaxBackoff, attempts))
+		if markErr := q.repo.MarkRetry(job.ID, attempts, next, err.Error()); markErr != nil {
+			q.logger.Errorf("Falha ao reagendar job de envio %s: %v", job.ID, markErr)
+		}
+	}
+}
+
+// failOverdueScheduled aplica a política de agendamentos com a sessão
+// desconectada: passada a janela de SCHEDULE_DISCONNECTED_RETRY desde o
+// horário agendado, os envios da sessão (inclusive os que estão atrás do job
+// da frente) são marcados como falhos em vez de saírem atrasados.
+func (q *OutboundQueue) failOverdueScheduled(job *models.OutboundJob) {
+	window := q.cfg.ScheduleRetryWindow
+	reason := fmt.Sprintf("sessão desconectada por mais de %s após o horário agendado", window)
+	n, err := q.repo.FailOverdueScheduled(job.SessionID, time.Now().Add(-window), reason)
+	if err != nil {
+		q.logger.Errorf("Falha ao encerrar agendamentos vencidos da sessão %s: %v", job.SessionKey, err)
+		return
+	}
+	if n > 0 {
+		q.logger.Warnf("[%s] %d envios agendados falharam: %s", job.SessionKey, n, reason)
+	}
+}
+
+func wakeUp(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
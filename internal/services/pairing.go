@@ -0,0 +1,75 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// pairingCodeTTL segue a janela do websocket de login: o WhatsApp encerra a
+// conexão quando os QR codes se esgotam (~160s), invalidando o código.
+const pairingCodeTTL = 160 * time.Second
+
+func (s *MultiTenantWhatsAppService) requestPairingCode(session *models.WhatsAppSession, waClient *WhatsAppClient) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	code, err := waClient.Client.PairPhone(ctx, waClient.pairPhone, true, whatsmeow.PairClientChrome, "Chrome (Linux)")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("falha ao gerar código de pareamento: %w", err)
+	}
+
+	exp := time.Now().Add(pairingCodeTTL)
+	waClient.setPairingCode(code, exp)
+	if err := s.repository.UpdatePairingCode(session.ID, waClient.pairPhone, code, exp); err != nil {
+		s.logger.Errorf("Falha ao atualizar código de pareamento no banco: %v", err)
+	}
+	return code, exp, nil
+}
+
+func (s *MultiTenantWhatsAppService) GetPairingCode(sessionKey string, tenantID string) (string, time.Time, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	waClient, ok := s.clients.Get(clientKey(tenantID, sessionKey))
+	if !ok {
+		if session.PairingCode != nil && session.PairingCodeExpiresAt != nil {
+			if time.Now().Before(*session.PairingCodeExpiresAt) {
+				return *session.PairingCode, *session.PairingCodeExpiresAt, nil
+			}
+			return "", time.Time{}, fmt.Errorf("código de pareamento expirado, gere outro")
+		}
+
+		if session.Status == models.SessionStatusConnected {
+			return "", time.Time{}, ErrSessionAlreadyConnected
+		}
+
+		return "", time.Time{}, fmt.Errorf("código de pareamento ainda não foi gerado")
+	}
+
+	if waClient.Client != nil && waClient.Client.Store != nil && waClient.Client.Store.ID != nil {
+		return "", time.Time{}, ErrSessionAlreadyConnected
+	}
+
+	code, exp, ok := waClient.getPairingCode()
+	if !ok || waClient.pairPhone == "" {
+		return "", time.Time{}, fmt.Errorf("código de pareamento ainda não foi gerado")
+	}
+	if !exp.IsZero() && time.Now().After(exp) {
+		// Um novo código exige um websocket de login ativo, que o refresh do
+		// QR reabre quando necessário.
+		if _, _, regenErr := s.refreshQRCode(session, waClient); regenErr == nil {
+			if newCode, newExp, pairErr := s.requestPairingCode(session, waClient); pairErr == nil {
+				return newCode, newExp, nil
+			}
+		}
+
+		return "", time.Time{}, fmt.Errorf("código de pareamento expirado, gere outro")
+	}
+	return code, exp, nil
+}
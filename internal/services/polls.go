@@ -0,0 +1,169 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+var ErrPollNotFound = repository.ErrPollNotFound
+
+// pollCreation devolve a criação de enquete em qualquer das versões do
+// protocolo (v1 a v3 e v5 compartilham a mesma estrutura).
+func pollCreation(msg *waE2E.Message) *waE2E.PollCreationMessage {
+	switch {
+	case msg.GetPollCreationMessage() != nil:
+		return msg.GetPollCreationMessage()
+	case msg.GetPollCreationMessageV2() != nil:
+		return msg.GetPollCreationMessageV2()
+	case msg.GetPollCreationMessageV3() != nil:
+		return msg.GetPollCreationMessageV3()
+	case msg.GetPollCreationMessageV5() != nil:
+		return msg.GetPollCreationMessageV5()
+	default:
+		return nil
+	}
+}
+
+func (s *MultiTenantWhatsAppService) recordPoll(session *models.WhatsAppSession, messageID, chatJID, creatorJID, question string, options []string, selectable int, at time.Time) {
+	poll := &models.Poll{
+		ID:              uuid.New(),
+		SessionID:       session.ID,
+		TenantID:        session.TenantID,
+		MessageID:       messageID,
+		ChatJID:         chatJID,
+		CreatorJID:      creatorJID,
+		Question:        question,
+		Options:         options,
+		SelectableCount: selectable,
+		CreatedAt:       at,
+	}
+	if err := s.polls.Create(poll); err != nil {
+		s.logger.Errorf("[%s] Falha ao registrar enquete %s: %v", session.WhatsAppSessionKey, messageID, err)
+	}
+}
+
+func (s *MultiTenantWhatsAppService) recordInboundPoll(session *models.WhatsAppSession, evt *events.Message) {
+	pc := pollCreation(evt.Message)
+	if pc == nil {
+		return
+	}
+	options := make([]string, 0, len(pc.GetOptions()))
+	for _, o := range pc.GetOptions() {
+		options = append(options, o.GetOptionName())
+	}
+	s.recordPoll(session, evt.Info.ID, evt.Info.Chat.String(), evt.Info.Sender.ToNonAD().String(),
+		pc.GetName(), options, int(pc.GetSelectableOptionsCount()), evt.Info.Timestamp)
+}
+
+// handlePollVote descriptografa o voto com o segredo da enquete (guardado
+// pelo whatsmeow ao enviar ou receber a enquete), traduz os hashes para os
+// nomes das opções e atualiza a apuração.
+func (s *MultiTenantWhatsAppService) handlePollVote(client *whatsmeow.Client, session *models.WhatsAppSession, evt *events.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	vote, err := client.DecryptPollVote(ctx, evt)
+	if err != nil {
+		s.logger.Warnf("[%s] Falha ao descriptografar voto %s: %v", session.WhatsAppSessionKey, evt.Info.ID, err)
+		return
+	}
+
+	pollID := evt.Message.GetPollUpdateMessage().GetPollCreationMessageKey().GetID()
+	ts := evt.Info.Timestamp
+	if ms := evt.Message.GetPollUpdateMessage().GetSenderTimestampMS(); ms > 0 {
+		ts = time.UnixMilli(ms)
+	}
+	out := &models.PollVoteEvent{
+		PollMessageID:   pollID,
+		Chat:            evt.Info.Chat.String(),
+		Voter:           evt.Info.Sender.ToNonAD().String(),
+		SelectedOptions: []string{},
+		Timestamp:       ts,
+	}
+
+	poll, err := s.polls.GetByMessage(session.ID, pollID)
+	switch {
+	case errors.Is(err, ErrPollNotFound):
+		for _, h := range vote.GetSelectedOptions() {
+			out.OptionHashes = append(out.OptionHashes, hex.EncodeToString(h))
+		}
+	case err != nil:
+		s.logger.Errorf("[%s] %v", session.WhatsAppSessionKey, err)
+		return
+	default:
+		byHash := make(map[[sha256.Size]byte]string, len(poll.Options))
+		for _, o := range poll.Options {
+			byHash[sha256.Sum256([]byte(o))] = o
+		}
+		for _, h := range vote.GetSelectedOptions() {
+			var key [sha256.Size]byte
+			copy(key[:], h)
+			if name, ok := byHash[key]; ok {
+				out.SelectedOptions = append(out.SelectedOptions, name)
+			}
+		}
+		if err := s.polls.UpsertVote(&models.PollVote{
+			PollID:          poll.ID,
+			VoterJID:        out.Voter,
+			SelectedOptions: out.SelectedOptions,
+			VotedAt:         ts,
+		}); err != nil {
+			s.logger.Errorf("[%s] %v", session.WhatsAppSessionKey, err)
+		}
+	}
+
+	s.publishEvent(session, newSessionEvent(session, models.EventTypePollVote, out))
+}
+
+// GetPollResults apura os votos de uma enquete enviada ou recebida pela sessão.
+func (s *MultiTenantWhatsAppService) GetPollResults(sessionKey, tenantID, messageID string) (*models.PollResults, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	poll, err := s.polls.GetByMessage(session.ID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	votes, err := s.polls.ListVotes(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &models.PollResults{
+		MessageID:       poll.MessageID,
+		ChatJID:         poll.ChatJID,
+		Question:        poll.Question,
+		SelectableCount: poll.SelectableCount,
+		Options:         make([]models.PollOptionTally, len(poll.Options)),
+		Votes:           votes,
+	}
+	index := make(map[string]int, len(poll.Options))
+	for i, o := range poll.Options {
+		results.Options[i] = models.PollOptionTally{Name: o, Voters: []string{}}
+		index[o] = i
+	}
+	for _, v := range votes {
+		if len(v.SelectedOptions) == 0 {
+			continue
+		}
+		results.TotalVoters++
+		for _, o := range v.SelectedOptions {
+			if i, ok := index[o]; ok {
+				results.Options[i].Votes++
+				results.Options[i].Voters = append(results.Options[i].Voters, v.VoterJID)
+			}
+		}
+	}
+	return results, nil
+}
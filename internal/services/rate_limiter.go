@@ -0,0 +1,250 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	RateLimitScopeSession      = "session"
+	RateLimitScopeNewRecipient = "new_recipient"
+	RateLimitScopeTenant       = "tenant"
+
+	RateLimitScopeContactCheck       = "contact_check"
+	RateLimitScopeTenantContactCheck = "tenant_contact_check"
+)
+
+// RateLimitError é devolvido quando um envio ou uma consulta de números
+// excede algum dos limites. Na API vira 429 com Retry-After; na fila, o job
+// aguarda RetryAfter.
+type RateLimitError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("limite excedido (%s), tente novamente em %s", e.Scope, e.RetryAfter.Round(time.Second))
+}
+
+type tokenBucket struct {
+	perMinute int
+	burst     int
+	tokens    float64
+	last      time.Time
+}
+
+func newTokenBucket(perMinute, burst int, now time.Time) *tokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{perMinute: perMinute, burst: burst, tokens: float64(burst), last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.burst), b.tokens+elapsed.Minutes()*float64(b.perMinute))
+	b.last = now
+}
+
+// wait devolve quanto falta para haver um token disponível.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	missing := 1 - b.tokens
+	return time.Duration(math.Ceil(missing / float64(b.perMinute) * float64(time.Minute)))
+}
+
+func (b *tokenBucket) state() *models.RateLimitBucket {
+	return &models.RateLimitBucket{
+		PerMinute:    b.perMinute,
+		Burst:        b.burst,
+		Available:    max(0, int(b.tokens)),
+		RetryAfterMs: b.wait().Milliseconds(),
+	}
+}
+
+// RateLimiter mantém os token buckets de envio (por sessão, por sessão para
+// destinatários novos e por tenant) e os de consulta de números no WhatsApp
+// (por sessão e por tenant). Uma operação só consome tokens quando todos os
+// buckets aplicáveis têm saldo, então uma recusa não penaliza os demais.
+type RateLimiter struct {
+	cfg config.RateLimitConfig
+	now func() time.Time // relógio substituível nos testes
+
+	mu             sync.Mutex
+	sessions       map[string]*tokenBucket
+	newRecipients  map[string]*tokenBucket
+	tenants        map[string]*tokenBucket
+	lookupSessions map[string]*tokenBucket
+	lookupTenants  map[string]*tokenBucket
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:            cfg,
+		now:            time.Now,
+		sessions:       make(map[string]*tokenBucket),
+		newRecipients:  make(map[string]*tokenBucket),
+		tenants:        make(map[string]*tokenBucket),
+		lookupSessions: make(map[string]*tokenBucket),
+		lookupTenants:  make(map[string]*tokenBucket),
+	}
+}
+
+func (l *RateLimiter) bucket(m map[string]*tokenBucket, key string, perMinute, burst int, now time.Time) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	b, ok := m[key]
+	if !ok {
+		b = newTokenBucket(perMinute, burst, now)
+		m[key] = b
+	}
+	b.refill(now)
+	return b
+}
+
+type scopedBucket struct {
+	scope string
+	b     *tokenBucket
+}
+
+// take consome cost tokens de cada bucket aplicável ou devolve um
+// *RateLimitError com a maior espera necessária, sem consumir nada. Basta um
+// token de saldo: um custo maior que a rajada deixa o bucket negativo e a
+// operação seguinte espera a recarga, em vez de nunca caber. Deve ser
+// chamado com mu travado.
+func take(buckets []scopedBucket, cost int) error {
+	var limited *RateLimitError
+	for _, sb := range buckets {
+		if sb.b == nil {
+			continue
+		}
+		if wait := sb.b.wait(); wait > 0 && (limited == nil || wait > limited.RetryAfter) {
+			limited = &RateLimitError{Scope: sb.scope, RetryAfter: wait}
+		}
+	}
+	if limited != nil {
+		return limited
+	}
+
+	for _, sb := range buckets {
+		if sb.b != nil {
+			sb.b.tokens -= float64(cost)
+		}
+	}
+	return nil
+}
+
+// Allow consome um token de envio de cada bucket aplicável.
+func (l *RateLimiter) Allow(sessionKey, tenantID string, newRecipient bool) error {
+	now := l.now()
+	key := clientKey(tenantID, sessionKey)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := []scopedBucket{
+		{RateLimitScopeSession, l.bucket(l.sessions, key, l.cfg.SessionPerMinute, l.cfg.SessionBurst, now)},
+		{RateLimitScopeTenant, l.bucket(l.tenants, tenantID, l.cfg.TenantPerMinute, l.cfg.TenantBurst, now)},
+	}
+	if newRecipient {
+		buckets = append(buckets, scopedBucket{RateLimitScopeNewRecipient, l.bucket(l.newRecipients, key, l.cfg.NewRecipientPerMinute, l.cfg.NewRecipientBurst, now)})
+	}
+	return take(buckets, 1)
+}
+
+// AllowLookup consome um token por número consultado no WhatsApp (IsOnWhatsApp).
+// Consultas em massa são o outro padrão que leva a banimentos, além de
+// permitir enumerar quem tem conta.
+func (l *RateLimiter) AllowLookup(sessionKey, tenantID string, numbers int) error {
+	now := l.now()
+	key := clientKey(tenantID, sessionKey)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return take([]scopedBucket{
+		{RateLimitScopeContactCheck, l.bucket(l.lookupSessions, key, l.cfg.ContactCheckSessionPerMinute, l.cfg.ContactCheckSessionBurst, now)},
+		{RateLimitScopeTenantContactCheck, l.bucket(l.lookupTenants, tenantID, l.cfg.ContactCheckTenantPerMinute, l.cfg.ContactCheckTenantBurst, now)},
+	}, numbers)
+}
+
+func (l *RateLimiter) State(sessionKey, tenantID string) *models.RateLimitState {
+	now := l.now()
+	key := clientKey(tenantID, sessionKey)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := &models.RateLimitState{}
+	if b := l.bucket(l.sessions, key, l.cfg.SessionPerMinute, l.cfg.SessionBurst, now); b != nil {
+		state.Session = b.state()
+	}
+	if b := l.bucket(l.newRecipients, key, l.cfg.NewRecipientPerMinute, l.cfg.NewRecipientBurst, now); b != nil {
+		state.NewRecipients = b.state()
+	}
+	if b := l.bucket(l.tenants, tenantID, l.cfg.TenantPerMinute, l.cfg.TenantBurst, now); b != nil {
+		state.Tenant = b.state()
+	}
+	if b := l.bucket(l.lookupSessions, key, l.cfg.ContactCheckSessionPerMinute, l.cfg.ContactCheckSessionBurst, now); b != nil {
+		state.ContactChecks = b.state()
+	}
+	if b := l.bucket(l.lookupTenants, tenantID, l.cfg.ContactCheckTenantPerMinute, l.cfg.ContactCheckTenantBurst, now); b != nil {
+		state.TenantContactChecks = b.state()
+	}
+	return state
+}
+
+func (l *RateLimiter) Remove(sessionKey, tenantID string) {
+	key := clientKey(tenantID, sessionKey)
+	l.mu.Lock()
+	delete(l.sessions, key)
+	delete(l.newRecipients, key)
+	delete(l.lookupSessions, key)
+	l.mu.Unlock()
+}
+
+// acquireSendSlot aplica os limites antes do envio. Destinatários sem
+// histórico na sessão contam também no bucket de novos contatos, que é o
+// padrão que mais leva a banimentos.
+func (s *MultiTenantWhatsAppService) acquireSendSlot(session *models.WhatsAppSession, to types.JID) error {
+	known, err := s.messages.HasRecipient(session.ID, to.String())
+	if err != nil {
+		s.logger.Warnf("[%s] Falha ao verificar histórico de %s, tratando como conhecido: %v", session.WhatsAppSessionKey, to, err)
+		known = true
+	}
+	return s.limiter.Allow(session.WhatsAppSessionKey, session.TenantID, !known)
+}
+
+func (s *MultiTenantWhatsAppService) GetSessionDetails(sessionKey, tenantID string) (*models.SessionDetails, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &models.SessionDetails{
+		WhatsAppSession: session,
+		RateLimit:       s.limiter.State(session.WhatsAppSessionKey, session.TenantID),
+	}
+	if _, err := s.getConnectedClient(tenantID, sessionKey); err == nil {
+		details.Connected = true
+	}
+	if depth, err := s.queue.repo.DepthBySession(session.ID); err != nil {
+		s.logger.Warnf("[%s] Falha ao consultar fila de envio: %v", sessionKey, err)
+	} else {
+		depth.SessionKey = session.WhatsAppSessionKey
+		details.Queue = depth
+	}
+	return details, nil
+}
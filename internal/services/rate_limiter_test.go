@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"boot-whatsapp-golang/internal/config"
+)
+
+type limiterStep struct {
+	advance time.Duration
+	call    func(l *RateLimiter) error
+	scope   string        // vazio espera sucesso
+	wait    time.Duration // RetryAfter esperado na recusa
+}
+
+func allowSend(sessionKey, tenantID string, newRecipient bool) func(*RateLimiter) error {
+	return func(l *RateLimiter) error { return l.Allow(sessionKey, tenantID, newRecipient) }
+}
+
+func allowLookup(sessionKey, tenantID string, numbers int) func(*RateLimiter) error {
+	return func(l *RateLimiter) error { return l.AllowLookup(sessionKey, tenantID, numbers) }
+}
+
+func TestRateLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.RateLimitConfig
+		steps []limiterStep
+	}{
+		{
+			name: "rajada e recarga",
+			cfg:  config.RateLimitConfig{SessionPerMinute: 60, SessionBurst: 2},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false), scope: RateLimitScopeSession, wait: time.Second},
+				{advance: 500 * time.Millisecond, call: allowSend("s1", "t1", false), scope: RateLimitScopeSession, wait: 500 * time.Millisecond},
+				{advance: 500 * time.Millisecond, call: allowSend("s1", "t1", false)},
+				// A recarga não passa da rajada.
+				{advance: time.Hour, call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false), scope: RateLimitScopeSession, wait: time.Second},
+			},
+		},
+		{
+			name: "recusa devolve a maior espera entre os buckets",
+			cfg: config.RateLimitConfig{
+				SessionPerMinute: 60, SessionBurst: 1,
+				NewRecipientPerMinute: 1, NewRecipientBurst: 1,
+				TenantPerMinute: 6, TenantBurst: 1,
+			},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", true)},
+				{call: allowSend("s1", "t1", true), scope: RateLimitScopeNewRecipient, wait: time.Minute},
+				{call: allowSend("s1", "t1", false), scope: RateLimitScopeTenant, wait: 10 * time.Second},
+				{advance: 10 * time.Second, call: allowSend("s1", "t1", true), scope: RateLimitScopeNewRecipient, wait: 50 * time.Second},
+			},
+		},
+		{
+			name: "recusa não consome dos outros buckets",
+			cfg: config.RateLimitConfig{
+				SessionPerMinute: 60, SessionBurst: 3,
+				NewRecipientPerMinute: 1, NewRecipientBurst: 1,
+				TenantPerMinute: 60, TenantBurst: 3,
+			},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", true)},
+				// Recusadas pelo bucket de novos contatos: sessão e tenant
+				// continuam com 2 tokens.
+				{call: allowSend("s1", "t1", true), scope: RateLimitScopeNewRecipient, wait: time.Minute},
+				{call: allowSend("s1", "t1", true), scope: RateLimitScopeNewRecipient, wait: time.Minute},
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s1", "t1", false), scope: RateLimitScopeSession, wait: time.Second},
+			},
+		},
+		{
+			name: "tenant compartilhado entre sessões",
+			cfg:  config.RateLimitConfig{SessionPerMinute: 60, SessionBurst: 5, TenantPerMinute: 60, TenantBurst: 2},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", false)},
+				{call: allowSend("s2", "t1", false)},
+				{call: allowSend("s3", "t1", false), scope: RateLimitScopeTenant, wait: time.Second},
+				{call: allowSend("s1", "t2", false)},
+			},
+		},
+		{
+			name: "bucket desativado é ignorado",
+			cfg:  config.RateLimitConfig{SessionPerMinute: 60, SessionBurst: 1, NewRecipientBurst: 1, TenantBurst: 1},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", true)},
+				{call: allowSend("s1", "t1", true), scope: RateLimitScopeSession, wait: time.Second},
+				{advance: time.Second, call: allowSend("s1", "t1", true)},
+				{advance: time.Second, call: allowSend("s2", "t1", true)},
+			},
+		},
+		{
+			name: "sem limites",
+			cfg:  config.RateLimitConfig{},
+			steps: []limiterStep{
+				{call: allowSend("s1", "t1", true)},
+				{call: allowSend("s1", "t1", true)},
+				{call: allowLookup("s1", "t1", 1000)},
+			},
+		},
+		{
+			name: "custo acima da rajada deixa o bucket negativo",
+			cfg:  config.RateLimitConfig{ContactCheckSessionPerMinute: 60, ContactCheckSessionBurst: 10},
+			steps: []limiterStep{
+				{call: allowLookup("s1", "t1", 25)},
+				// Saldo -15: faltam 16 tokens para o próximo.
+				{call: allowLookup("s1", "t1", 1), scope: RateLimitScopeContactCheck, wait: 16 * time.Second},
+				{advance: 15 * time.Second, call: allowLookup("s1", "t1", 1), scope: RateLimitScopeContactCheck, wait: time.Second},
+				{advance: time.Second, call: allowLookup("s1", "t1", 1)},
+				// Os envios usam buckets próprios.
+				{call: allowSend("s1", "t1", true)},
+			},
+		},
+		{
+			name: "consulta de números por tenant",
+			cfg: config.RateLimitConfig{
+				ContactCheckSessionPerMinute: 600, ContactCheckSessionBurst: 100,
+				ContactCheckTenantPerMinute: 60, ContactCheckTenantBurst: 10,
+			},
+			steps: []limiterStep{
+				{call: allowLookup("s1", "t1", 8)},
+				{call: allowLookup("s2", "t1", 5)},
+				// Tenant em -3 (espera 4s); a sessão s1 ainda tem saldo.
+				{call: allowLookup("s1", "t1", 1), scope: RateLimitScopeTenantContactCheck, wait: 4 * time.Second},
+				{advance: 4 * time.Second, call: allowLookup("s1", "t1", 1)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, clock := newTestLimiter(tt.cfg)
+			for i, step := range tt.steps {
+				*clock = clock.Add(step.advance)
+				err := step.call(l)
+				if step.scope == "" {
+					if err != nil {
+						t.Fatalf("passo %d: %v", i, err)
+					}
+					continue
+				}
+				var limited *RateLimitError
+				if !errors.As(err, &limited) {
+					t.Fatalf("passo %d: erro = %v, esperado *RateLimitError", i, err)
+				}
+				if limited.Scope != step.scope || limited.RetryAfter != step.wait {
+					t.Fatalf("passo %d: %s em %s, esperado %s em %s", i, limited.Scope, limited.RetryAfter, step.scope, step.wait)
+				}
+			}
+		})
+	}
+}
+
+func newTestLimiter(cfg config.RateLimitConfig) (*RateLimiter, *time.Time) {
+	l := NewRateLimiter(cfg)
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return clock }
+	return l, &clock
+}
+
+func TestRateLimiterState(t *testing.T) {
+	l, clock := newTestLimiter(config.RateLimitConfig{
+		SessionPerMinute: 60, SessionBurst: 5,
+		ContactCheckSessionPerMinute: 60, ContactCheckSessionBurst: 10,
+	})
+	if err := l.Allow("s1", "t1", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AllowLookup("s1", "t1", 12); err != nil {
+		t.Fatal(err)
+	}
+	*clock = clock.Add(500 * time.Millisecond)
+
+	state := l.State("s1", "t1")
+	if state.Tenant != nil || state.NewRecipients != nil || state.TenantContactChecks != nil {
+		t.Errorf("buckets desativados no estado: %+v", state)
+	}
+	if s := state.Session; s == nil || s.Available != 4 || s.RetryAfterMs != 0 {
+		t.Errorf("Session = %+v, esperado 4 disponíveis", s)
+	}
+	// Saldo -1.5: nada disponível e 2.5s até o próximo token.
+	if s := state.ContactChecks; s == nil || s.Available != 0 || s.RetryAfterMs != 2500 {
+		t.Errorf("ContactChecks = %+v, esperado 0 disponíveis e 2500ms", s)
+	}
+
+	l.Remove("s1", "t1")
+	if s := l.State("s1", "t1").ContactChecks; s.Available != 10 {
+		t.Errorf("ContactChecks depois de Remove = %+v, esperado rajada cheia", s)
+	}
+}
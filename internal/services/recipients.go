@@ -0,0 +1,106 @@
+package services
+
+import (
+	"boot-whatsapp-golang/pkg/validator"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var ErrInvalidRecipient = errors.New("destinatário inválido")
+
+// resolveRecipient converte o campo number do envio no JID de destino. Links
+// de convite são resolvidos no servidor (sem entrar no grupo) e números com
+// grafias alternativas são consultados no WhatsApp; os demais formatos são
+// convertidos localmente.
+func (s *MultiTenantWhatsAppService) resolveRecipient(ctx context.Context, waClient *WhatsAppClient, raw string) (types.JID, error) {
+	kind, err := validator.ValidateRecipient(raw)
+	if err != nil {
+		return types.JID{}, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	raw = strings.TrimSpace(raw)
+
+	switch kind {
+	case validator.RecipientPhone:
+		return s.resolvePhone(ctx, waClient, raw)
+	case validator.RecipientGroup:
+		return parseGroupJID(raw)
+	case validator.RecipientInvite:
+		code, _ := validator.InviteCode(raw)
+		info, err := waClient.Client.GetGroupInfoFromLink(ctx, code)
+		if err != nil {
+			return types.JID{}, classifyGroupError(err)
+		}
+		return info.JID, nil
+	default:
+		jid, err := types.ParseJID(raw)
+		if err != nil {
+			return types.JID{}, fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+		}
+		if jid.Server == types.LegacyUserServer {
+			jid.Server = types.DefaultUserServer
+		}
+		return jid.ToNonAD(), nil
+	}
+}
+
+// resolveMentions converte números/JIDs mencionados em JIDs de usuário.
+func (s *MultiTenantWhatsAppService) resolveMentions(mentions []string) ([]string, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	jids := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		if err := validator.ValidateMention(m); err != nil {
+			return nil, fmt.Errorf("%w: menção %q: %v", ErrInvalidRecipient, m, err)
+		}
+		var jid types.JID
+		var err error
+		if strings.Contains(m, "@") {
+			jid, err = types.ParseJID(strings.TrimSpace(m))
+			if jid.Server == types.LegacyUserServer {
+				jid.Server = types.DefaultUserServer
+			}
+		} else {
+			jid, err = s.parsePhoneNumber(m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: menção %q: %v", ErrInvalidRecipient, m, err)
+		}
+		jids = append(jids, jid.ToNonAD().String())
+	}
+	return jids, nil
+}
+
+// recipientError marca como permanentes as falhas que não se resolvem com
+// retry, para que jobs assíncronos não fiquem tentando um destino inválido.
+func recipientError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidRecipient),
+		errors.Is(err, ErrNotOnWhatsApp),
+		errors.Is(err, ErrInvalidGroupJID),
+		errors.Is(err, ErrGroupNotFound),
+		errors.Is(err, ErrNotInGroup),
+		errors.Is(err, ErrGroupForbidden),
+		errors.Is(err, ErrInviteLinkInvalid),
+		errors.Is(err, ErrInviteLinkRevoked),
+		errors.Is(err, ErrQuotedMessageNotFound):
+		return permanent(err)
+	}
+	return err
+}
+
+// sendError classifica falhas de envio para grupos (sessão fora do grupo,
+// grupo inexistente) com os mesmos erros da API de grupos.
+func sendError(to types.JID, err error, message string) error {
+	if to.Server == types.GroupServer && !errors.Is(err, whatsmeow.ErrNotConnected) {
+		if classified := classifyGroupError(err); classified != err {
+			return recipientError(classified)
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+func (s *MultiTenantWhatsAppService) SendLocationMessage(sessionKey, tenantID string, req *models.LocationRequest) (*models.MessageSent, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return s.sendLocation(ctx, waClient, req)
+}
+
+func (s *MultiTenantWhatsAppService) sendLocation(ctx context.Context, waClient *WhatsAppClient, req *models.LocationRequest) (*models.MessageSent, error) {
+	return s.sendBuilt(ctx, waClient, req.Number, nil, req.QuotedMessageID, func(ctxInfo *waE2E.ContextInfo) *waE2E.Message {
+		loc := &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(*req.Latitude),
+			DegreesLongitude: proto.Float64(*req.Longitude),
+			ContextInfo:      ctxInfo,
+		}
+		if req.Name != "" {
+			loc.Name = proto.String(req.Name)
+		}
+		if req.Address != "" {
+			loc.Address = proto.String(req.Address)
+		}
+		return &waE2E.Message{LocationMessage: loc}
+	})
+}
+
+func (s *MultiTenantWhatsAppService) SendContactsMessage(sessionKey, tenantID string, req *models.ContactsRequest) (*models.MessageSent, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return s.sendContacts(ctx, waClient, req)
+}
+
+// sendContacts envia um ContactMessage para um único cartão e um
+// ContactsArrayMessage para vários, como faz o próprio app.
+func (s *MultiTenantWhatsAppService) sendContacts(ctx context.Context, waClient *WhatsAppClient, req *models.ContactsRequest) (*models.MessageSent, error) {
+	cards := make([]*waE2E.ContactMessage, 0, len(req.Contacts))
+	for _, c := range req.Contacts {
+		cards = append(cards, &waE2E.ContactMessage{
+			DisplayName: proto.String(c.FullName),
+			Vcard:       proto.String(s.renderVCard(c)),
+		})
+	}
+
+	return s.sendBuilt(ctx, waClient, req.Number, nil, req.QuotedMessageID, func(ctxInfo *waE2E.ContextInfo) *waE2E.Message {
+		if len(cards) == 1 {
+			cards[0].ContextInfo = ctxInfo
+			return &waE2E.Message{ContactMessage: cards[0]}
+		}
+		return &waE2E.Message{ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+			DisplayName: proto.String(fmt.Sprintf("%d contatos", len(cards))),
+			Contacts:    cards,
+			ContextInfo: ctxInfo,
+		}}
+	})
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+
+// renderVCard gera um vCard 3.0. O parâmetro waid leva o número normalizado
+// (com DDI) para que o WhatsApp associe o cartão à conta.
+func (s *MultiTenantWhatsAppService) renderVCard(c models.ContactCard) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+
+	line("BEGIN:VCARD")
+	line("VERSION:3.0")
+	line("N:%s;%s;;;", vcardEscaper.Replace(c.LastName), vcardEscaper.Replace(c.FirstName))
+	line("FN:%s", vcardEscaper.Replace(c.FullName))
+	if c.Organization != "" {
+		line("ORG:%s;", vcardEscaper.Replace(c.Organization))
+	}
+	if c.Title != "" {
+		line("TITLE:%s", vcardEscaper.Replace(c.Title))
+	}
+	for _, p := range c.Phones {
+		phoneType := strings.ToUpper(p.Type)
+		if phoneType == "" {
+			phoneType = "CELL"
+		}
+		waid := ""
+		if jid, err := s.parsePhoneNumber(p.Number); err == nil {
+			waid = jid.User
+		}
+		if waid != "" {
+			line("TEL;type=%s;type=VOICE;waid=%s:+%s", phoneType, waid, waid)
+		} else {
+			line("TEL;type=%s;type=VOICE:%s", phoneType, vcardEscaper.Replace(p.Number))
+		}
+	}
+	for _, e := range c.Emails {
+		line("EMAIL;type=INTERNET:%s", vcardEscaper.Replace(e))
+	}
+	if c.URL != "" {
+		line("URL:%s", vcardEscaper.Replace(c.URL))
+	}
+	line("END:VCARD")
+	return b.String()
+}
+
+func (s *MultiTenantWhatsAppService) SendPollMessage(sessionKey, tenantID string, req *models.PollRequest) (*models.MessageSent, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return s.sendPoll(ctx, waClient, req)
+}
+
+// sendPoll envia a enquete e a registra para apuração. O segredo usado para
+// descriptografar os votos é guardado pelo próprio whatsmeow no envio.
+func (s *MultiTenantWhatsAppService) sendPoll(ctx context.Context, waClient *WhatsAppClient, req *models.PollRequest) (*models.MessageSent, error) {
+	selectable := 1
+	if req.SelectableCount != nil {
+		selectable = *req.SelectableCount
+	}
+
+	sent, err := s.sendBuilt(ctx, waClient, req.Number, nil, req.QuotedMessageID, func(ctxInfo *waE2E.ContextInfo) *waE2E.Message {
+		msg := waClient.Client.BuildPollCreation(req.Question, req.Options, selectable)
+		msg.PollCreationMessage.ContextInfo = ctxInfo
+		return msg
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordPoll(waClient.Session, sent.MessageID, sent.RecipientJID, waClient.Client.Store.ID.ToNonAD().String(),
+		req.Question, req.Options, selectable, sent.SentAt)
+	return sent, nil
+}
+
+func (s *MultiTenantWhatsAppService) EnqueueLocationMessage(sessionKey, tenantID string, req *models.LocationRequest) (*models.OutboundJobAccepted, error) {
+	payload := *req
+	payload.Async = false
+	return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindLocation, req.Number, payload, nil)
+}
+
+func (s *MultiTenantWhatsAppService) EnqueueContactsMessage(sessionKey, tenantID string, req *models.ContactsRequest) (*models.OutboundJobAccepted, error) {
+	payload := *req
+	payload.Async = false
+	return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindContacts, req.Number, payload, nil)
+}
+
+func (s *MultiTenantWhatsAppService) EnqueuePollMessage(sessionKey, tenantID string, req *models.PollRequest) (*models.OutboundJobAccepted, error) {
+	payload := *req
+	payload.Async = false
+	return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindPoll, req.Number, payload, nil)
+}
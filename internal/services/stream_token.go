@@ -0,0 +1,97 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidStreamToken = errors.New("token de stream inválido ou expirado")
+
+// StreamTokens emite e confere os tokens curtos que autenticam o stream de
+// eventos pela query string (?token=). Cada token vale para uma única sessão
+// de um tenant e só é conferido na abertura da conexão.
+type StreamTokens struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time // relógio substituível nos testes
+}
+
+type streamTokenClaims struct {
+	TenantID   string `json:"t"`
+	SessionKey string `json:"s"`
+	ExpiresAt  int64  `json:"e"`
+}
+
+// NewStreamTokens usa secret como chave HMAC; vazio gera uma chave
+// aleatória para o processo.
+func NewStreamTokens(secret string, ttl time.Duration) (*StreamTokens, error) {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("falha ao gerar chave dos tokens de stream: %w", err)
+		}
+	}
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &StreamTokens{key: key, ttl: ttl, now: time.Now}, nil
+}
+
+func (t *StreamTokens) sign(payload string) string {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (t *StreamTokens) issue(tenantID, sessionKey string) (*models.StreamTokenResponse, error) {
+	exp := t.now().Add(t.ttl).Truncate(time.Second)
+	raw, err := json.Marshal(streamTokenClaims{TenantID: tenantID, SessionKey: sessionKey, ExpiresAt: exp.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return &models.StreamTokenResponse{Token: payload + "." + t.sign(payload), ExpiresAt: exp}, nil
+}
+
+// Verify devolve o tenant e a sessão do token.
+func (t *StreamTokens) Verify(token string) (tenantID, sessionKey string, err error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(t.sign(payload))) {
+		return "", "", ErrInvalidStreamToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrInvalidStreamToken
+	}
+	var claims streamTokenClaims
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.TenantID == "" || claims.SessionKey == "" {
+		return "", "", ErrInvalidStreamToken
+	}
+	if t.now().Unix() >= claims.ExpiresAt {
+		return "", "", ErrInvalidStreamToken
+	}
+	return claims.TenantID, claims.SessionKey, nil
+}
+
+// IssueStreamToken emite um token de stream para uma sessão do tenant.
+func (s *MultiTenantWhatsAppService) IssueStreamToken(sessionKey, tenantID string) (*models.StreamTokenResponse, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.streamTokens.issue(session.TenantID, session.WhatsAppSessionKey)
+}
+
+// StreamTokens expõe o verificador para o middleware das rotas de stream.
+func (s *MultiTenantWhatsAppService) StreamTokens() *StreamTokens {
+	return s.streamTokens
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamTokens(t *testing.T) {
+	tokens, err := NewStreamTokens("segredo", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens.now = func() time.Time { return clock }
+
+	issued, err := tokens.issue("t1", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !issued.ExpiresAt.Equal(clock.Add(time.Minute)) {
+		t.Errorf("ExpiresAt = %s, esperado %s", issued.ExpiresAt, clock.Add(time.Minute))
+	}
+
+	tenantID, sessionKey, err := tokens.Verify(issued.Token)
+	if err != nil || tenantID != "t1" || sessionKey != "s1" {
+		t.Fatalf("Verify = %q, %q, %v", tenantID, sessionKey, err)
+	}
+
+	payload, sig, _ := strings.Cut(issued.Token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"t2","s":"s1","e":4102444800}`))
+	other, err := NewStreamTokens("outro segredo", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := other.issue("t1", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := []byte(sig)
+	flipped[0] ^= 1
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"vazio", ""},
+		{"sem assinatura", payload},
+		{"assinatura vazia", payload + "."},
+		{"assinatura alterada", payload + "." + string(flipped)},
+		{"payload trocado", forged + "." + sig},
+		{"outra chave", otherToken.Token},
+		{"payload que não é base64", "@@@." + tokens.sign("@@@")},
+		{"payload que não é JSON", "bm9wZQ." + tokens.sign("bm9wZQ")},
+		{"sem tenant", signed(tokens, `{"s":"s1","e":4102444800}`)},
+		{"sem sessão", signed(tokens, `{"t":"t1","e":4102444800}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tokens.Verify(tt.token); !errors.Is(err, ErrInvalidStreamToken) {
+				t.Errorf("Verify = %v, esperado ErrInvalidStreamToken", err)
+			}
+		})
+	}
+
+	t.Run("expiração", func(t *testing.T) {
+		clock = issued.ExpiresAt.Add(-time.Second)
+		if _, _, err := tokens.Verify(issued.Token); err != nil {
+			t.Fatalf("Verify um segundo antes de expirar = %v", err)
+		}
+		clock = issued.ExpiresAt
+		if _, _, err := tokens.Verify(issued.Token); !errors.Is(err, ErrInvalidStreamToken) {
+			t.Errorf("Verify na expiração = %v, esperado ErrInvalidStreamToken", err)
+		}
+	})
+}
+
+func TestStreamTokensRandomKey(t *testing.T) {
+	a, err := NewStreamTokens("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewStreamTokens("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ttl != 5*time.Minute {
+		t.Errorf("ttl padrão = %s, esperado 5m", a.ttl)
+	}
+	issued, err := a.issue("t1", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.Verify(issued.Token); !errors.Is(err, ErrInvalidStreamToken) {
+		t.Errorf("token de outro processo aceito: %v", err)
+	}
+}
+
+func signed(tokens *StreamTokens, claims string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return payload + "." + tokens.sign(payload)
+}
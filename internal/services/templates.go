@@ -0,0 +1,414 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/msgtemplate"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTemplateNotFound        = repository.ErrTemplateNotFound
+	ErrTemplateVersionNotFound = repository.ErrTemplateVersionNotFound
+	ErrTemplateExists          = errors.New("já existe um template com esse nome e idioma")
+	ErrInvalidTemplate         = errors.New("template inválido")
+)
+
+// TemplateValidationError descreve os campos recusados do template ou do
+// envio por template.
+type TemplateValidationError struct {
+	Details map[string]string
+}
+
+func (e *TemplateValidationError) Error() string {
+	keys := make([]string, 0, len(e.Details))
+	for k := range e.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+e.Details[k])
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidTemplate, strings.Join(parts, "; "))
+}
+
+func (e *TemplateValidationError) Unwrap() error { return ErrInvalidTemplate }
+
+var languageTag = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[_-]([a-zA-Z]{2}))?$`)
+
+// normalizeLanguage padroniza o idioma como ll ou ll_RR (pt, pt_BR).
+func normalizeLanguage(raw string) (string, bool) {
+	m := languageTag.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return "", false
+	}
+	lang := strings.ToLower(m[1])
+	if m[2] != "" {
+		lang += "_" + strings.ToUpper(m[2])
+	}
+	return lang, true
+}
+
+func (s *MultiTenantWhatsAppService) CreateTemplate(tenantID string, req *models.TemplateRequest) (*models.MessageTemplate, error) {
+	details := s.validateTemplate(tenantID, req, nil)
+	if len(details) > 0 {
+		return nil, &TemplateValidationError{Details: details}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	language, _ := normalizeLanguage(req.Language)
+	existing, err := s.templates.List(models.TemplateFilter{TenantID: tenantID, Name: name, Language: language})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return existing[0], ErrTemplateExists
+	}
+
+	now := time.Now().UTC()
+	t := &models.MessageTemplate{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      name,
+		Language:  language,
+		Version:   1,
+		Body:      req.Body,
+		Media:     req.Media,
+		Variables: msgtemplate.Variables(req.Body),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.templates.Create(t); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Template %s (%s) criado para o tenant %s", t.Name, t.Language, tenantID)
+	return t, nil
+}
+
+// UpdateTemplate grava o corpo e a mídia enviados como uma nova versão. Um
+// conteúdo idêntico ao da versão atual não cria versão.
+func (s *MultiTenantWhatsAppService) UpdateTemplate(tenantID, templateID string, req *models.TemplateRequest) (*models.MessageTemplate, error) {
+	t, err := s.GetTemplate(tenantID, templateID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	details := s.validateTemplate(tenantID, req, t)
+	if len(details) > 0 {
+		return nil, &TemplateValidationError{Details: details}
+	}
+	if req.Body == t.Body && sameTemplateMedia(req.Media, t.Media) {
+		return t, nil
+	}
+
+	t.Body = req.Body
+	t.Media = req.Media
+	t.Variables = msgtemplate.Variables(req.Body)
+	if err := s.templates.AddVersion(t); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Template %s (%s) atualizado para a versão %d", t.Name, t.Language, t.Version)
+	return t, nil
+}
+
+func sameTemplateMedia(a, b *models.TemplateMedia) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateTemplate confere o que depende do estado do template; tamanhos e
+// formatos ficam nas tags de models.TemplateRequest. Com current, valida uma
+// nova versão, em que nome e idioma não mudam.
+func (s *MultiTenantWhatsAppService) validateTemplate(tenantID string, req *models.TemplateRequest, current *models.MessageTemplate) map[string]string {
+	details := map[string]string{}
+	name := strings.TrimSpace(req.Name)
+	language, validLanguage := normalizeLanguage(req.Language)
+	if current == nil {
+		if name == "" {
+			details["name"] = "obrigatório"
+		}
+		switch {
+		case req.Language == "":
+			details["language"] = "obrigatório"
+		case !validLanguage:
+			details["language"] = "use o código do idioma, com região opcional (ex.: pt_BR, en, es)"
+		}
+	} else {
+		if name != "" && name != current.Name {
+			details["name"] = "não pode ser alterado; crie outro template"
+		}
+		if req.Language != "" && language != current.Language {
+			details["language"] = "não pode ser alterado; crie o template no outro idioma"
+		}
+	}
+
+	switch {
+	case req.Media == nil && strings.TrimSpace(req.Body) == "":
+		details["body"] = "obrigatório em templates sem mídia"
+	default:
+		if err := msgtemplate.CheckFormat(req.Body); err != nil {
+			details["body"] = err.Error()
+		}
+	}
+
+	if m := req.Media; m != nil {
+		if m.Mode != "" && strings.TrimSpace(req.Body) != "" {
+			details["body"] = "não permitido com mode " + m.Mode + " (sem legenda)"
+		}
+		if m.MediaID != "" {
+			if _, err := s.GetMedia(tenantID, m.MediaID); err != nil {
+				details["media.media_id"] = err.Error()
+			}
+		}
+	}
+	return details
+}
+
+// GetTemplate devolve o template com o conteúdo da versão pedida (0 para a
+// atual).
+func (s *MultiTenantWhatsAppService) GetTemplate(tenantID, templateID string, version int) (*models.MessageTemplate, error) {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return nil, ErrTemplateNotFound
+	}
+	t, err := s.templates.GetByIDAndTenant(id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 || version == t.Version {
+		return t, nil
+	}
+
+	v, err := s.templates.GetVersion(t.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	t.Version, t.Body, t.Media, t.Variables = v.Version, v.Body, v.Media, v.Variables
+	return t, nil
+}
+
+func (s *MultiTenantWhatsAppService) ListTemplates(filter models.TemplateFilter) ([]*models.MessageTemplate, error) {
+	if filter.Language != "" {
+		if lang, ok := normalizeLanguage(filter.Language); ok {
+			filter.Language = lang
+		}
+	}
+	return s.templates.List(filter)
+}
+
+func (s *MultiTenantWhatsAppService) TemplateVersions(tenantID, templateID string) ([]*models.TemplateVersion, error) {
+	t, err := s.GetTemplate(tenantID, templateID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.templates.Versions(t.ID)
+}
+
+func (s *MultiTenantWhatsAppService) DeleteTemplate(tenantID, templateID string) error {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+	if err := s.templates.Delete(id, tenantID); err != nil {
+		return err
+	}
+	s.logger.Infof("Template %s removido do tenant %s", id, tenantID)
+	return nil
+}
+
+// RenderTemplate preenche o template sem enviar, para pré-visualização.
+func (s *MultiTenantWhatsAppService) RenderTemplate(tenantID, templateID string, req *models.TemplateRenderRequest) (*models.TemplateRendered, error) {
+	t, err := s.GetTemplate(tenantID, templateID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(t, req.Variables)
+}
+
+func renderTemplate(t *models.MessageTemplate, vars map[string]string) (*models.TemplateRendered, error) {
+	text, err := msgtemplate.Render(t.Body, vars)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TemplateRendered{
+		TemplateUsage: models.TemplateUsage{TemplateID: t.ID, Version: t.Version},
+		Name:          t.Name,
+		Language:      t.Language,
+		Text:          text,
+		Media:         t.Media,
+	}, nil
+}
+
+// resolveTemplate localiza o template do envio por ID ou por nome e
+// idioma, com recuo do idioma regional para o idioma base.
+func (s *MultiTenantWhatsAppService) resolveTemplate(tenantID string, req *models.TemplateSendRequest) (*models.MessageTemplate, error) {
+	if req.TemplateID != "" {
+		return s.GetTemplate(tenantID, req.TemplateID, req.Version)
+	}
+
+	candidates, err := s.templates.List(models.TemplateFilter{TenantID: tenantID, Name: strings.TrimSpace(req.Name)})
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	var chosen *models.MessageTemplate
+	if req.Language == "" {
+		if len(candidates) > 1 {
+			languages := make([]string, 0, len(candidates))
+			for _, c := range candidates {
+				languages = append(languages, c.Language)
+			}
+			return nil, &TemplateValidationError{Details: map[string]string{
+				"language": "obrigatório: template disponível em " + strings.Join(languages, ", "),
+			}}
+		}
+		chosen = candidates[0]
+	} else {
+		lang, ok := normalizeLanguage(req.Language)
+		if !ok {
+			return nil, &TemplateValidationError{Details: map[string]string{"language": "código de idioma inválido"}}
+		}
+		base, _, _ := strings.Cut(lang, "_")
+		for _, c := range candidates {
+			if c.Language == lang {
+				chosen = c
+				break
+			}
+			if c.Language == base {
+				chosen = c
+			}
+		}
+		if chosen == nil {
+			return nil, ErrTemplateNotFound
+		}
+	}
+
+	if req.Version == 0 || req.Version == chosen.Version {
+		return chosen, nil
+	}
+	return s.GetTemplate(tenantID, chosen.ID.String(), req.Version)
+}
+
+// Payloads gravados na fila para envios por template: a requisição de texto
+// ou mídia montada a partir do template, mais a versão usada, registrada na
+// mensagem quando o job for enviado.
+type templateTextPayload struct {
+	models.MessageRequest
+	models.TemplateUsage
+}
+
+type templateMediaPayload struct {
+	models.MediaRequest
+	models.TemplateUsage
+}
+
+// prepareTemplateSend resolve e renderiza o template e monta a requisição de
+// texto ou mídia equivalente.
+func (s *MultiTenantWhatsAppService) prepareTemplateSend(tenantID string, req *models.TemplateSendRequest) (*models.MessageTemplate, any, error) {
+	t, err := s.resolveTemplate(tenantID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	rendered, err := renderTemplate(t, req.Variables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t.Media == nil {
+		return t, &models.MessageRequest{
+			Number:          req.Number,
+			Text:            rendered.Text,
+			Mentions:        req.Mentions,
+			QuotedMessageID: req.QuotedMessageID,
+			LinkPreview:     req.LinkPreview,
+		}, nil
+	}
+	if req.LinkPreview != nil {
+		return nil, nil, &TemplateValidationError{Details: map[string]string{"link_preview": "só vale para templates de texto"}}
+	}
+	return t, &models.MediaRequest{
+		Number:          req.Number,
+		Caption:         rendered.Text,
+		MediaURL:        t.Media.MediaURL,
+		MediaID:         t.Media.MediaID,
+		MimeType:        t.Media.MimeType,
+		FileName:        t.Media.FileName,
+		Mode:            t.Media.Mode,
+		Mentions:        req.Mentions,
+		QuotedMessageID: req.QuotedMessageID,
+	}, nil
+}
+
+// SendTemplateMessage envia o template renderizado e registra na mensagem a
+// versão usada.
+func (s *MultiTenantWhatsAppService) SendTemplateMessage(sessionKey, tenantID string, req *models.TemplateSendRequest) (*models.TemplateSent, error) {
+	t, send, err := s.prepareTemplateSend(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent *models.MessageSent
+	switch r := send.(type) {
+	case *models.MessageRequest:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		sent, err = s.sendText(ctx, waClient, r)
+	case *models.MediaRequest:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		sent, err = s.sendMedia(ctx, waClient, r, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	usage := models.TemplateUsage{TemplateID: t.ID, Version: t.Version}
+	s.recordTemplateUsage(waClient.Session, sent.MessageID, usage)
+	return &models.TemplateSent{MessageSent: sent, TemplateUsage: usage}, nil
+}
+
+// EnqueueTemplateMessage renderiza o template agora e coloca o envio na
+// fila da sessão, agendado se houver send_at.
+func (s *MultiTenantWhatsAppService) EnqueueTemplateMessage(sessionKey, tenantID string, req *models.TemplateSendRequest) (*models.OutboundJobAccepted, error) {
+	t, send, err := s.prepareTemplateSend(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := models.TemplateUsage{TemplateID: t.ID, Version: t.Version}
+	switch r := send.(type) {
+	case *models.MediaRequest:
+		payload := templateMediaPayload{MediaRequest: *r, TemplateUsage: usage}
+		return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindMedia, req.Number, payload, req.SendAt)
+	default:
+		payload := templateTextPayload{MessageRequest: *send.(*models.MessageRequest), TemplateUsage: usage}
+		return s.enqueueOutbound(sessionKey, tenantID, models.OutboundKindText, req.Number, payload, req.SendAt)
+	}
+}
+
+// recordTemplateUsage grava a versão do template na mensagem enviada. Como
+// no registro da mensagem, uma falha aqui não invalida o envio.
+func (s *MultiTenantWhatsAppService) recordTemplateUsage(session *models.WhatsAppSession, messageID string, usage models.TemplateUsage) {
+	if err := s.messages.SetTemplate(session.ID, messageID, usage.TemplateID, usage.Version); err != nil {
+		s.logger.Errorf("[%s] Falha ao registrar template da mensagem %s: %v", session.WhatsAppSessionKey, messageID, err)
+	}
+}
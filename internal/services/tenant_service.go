@@ -0,0 +1,177 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/logger"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix       = "wak_"
+	apiKeyDisplayChars = 12
+
+	// apiKeyTouchInterval evita uma escrita no banco a cada requisição só
+	// para atualizar last_used_at.
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrTenantNotFound      = repository.ErrTenantNotFound
+	ErrTenantAlreadyExists = repository.ErrTenantAlreadyExists
+	ErrAPIKeyNotFound      = repository.ErrAPIKeyNotFound
+	ErrInvalidAPIKey       = errors.New("chave de API inválida ou revogada")
+)
+
+// TenantService administra o registro de tenants e resolve o tenant de cada
+// requisição a partir da chave de API apresentada.
+type TenantService struct {
+	repo   *repository.TenantRepository
+	logger *logger.Logger
+}
+
+func NewTenantService(repo *repository.TenantRepository, log *logger.Logger) *TenantService {
+	return &TenantService{repo: repo, logger: log}
+}
+
+// HashAPIKey devolve o SHA-256 em hex da chave. As chaves têm 192 bits de
+// entropia, então um hash rápido basta e permite a busca por índice.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("falha ao gerar chave de API: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func newTenantAPIKey(tenantID, name string) (*models.TenantAPIKey, string, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	return &models.TenantAPIKey{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayChars],
+		KeyHash:   HashAPIKey(key),
+		CreatedAt: time.Now(),
+	}, key, nil
+}
+
+func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.Tenant, error) {
+	now := time.Now()
+	tenant := &models.Tenant{
+		ID:        req.ID,
+		Name:      req.Name,
+		Status:    models.TenantStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) ListTenants() ([]*models.Tenant, error) {
+	return s.repo.List()
+}
+
+func (s *TenantService) GetTenant(id string) (*models.Tenant, error) {
+	return s.repo.Get(id)
+}
+
+func (s *TenantService) UpdateTenantStatus(id, status string) (*models.Tenant, error) {
+	if err := s.repo.UpdateStatus(id, status); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(id)
+}
+
+func (s *TenantService) CreateAPIKey(tenantID, name string) (*models.APIKeyCreatedResponse, error) {
+	if _, err := s.repo.Get(tenantID); err != nil {
+		return nil, err
+	}
+
+	apiKey, key, err := newTenantAPIKey(tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAPIKey(apiKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Chave de API %s criada para o tenant %s", apiKey.Prefix, tenantID)
+	return &models.APIKeyCreatedResponse{TenantAPIKey: apiKey, Key: key}, nil
+}
+
+func (s *TenantService) ListAPIKeys(tenantID string) ([]*models.TenantAPIKey, error) {
+	if _, err := s.repo.Get(tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIKeys(tenantID)
+}
+
+// RotateAPIKey substitui a chave por uma nova com o mesmo nome; a antiga
+// deixa de funcionar imediatamente.
+func (s *TenantService) RotateAPIKey(tenantID string, keyID uuid.UUID) (*models.APIKeyCreatedResponse, error) {
+	old, err := s.repo.GetAPIKey(tenantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if old.RevokedAt != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	apiKey, key, err := newTenantAPIKey(tenantID, old.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RotateAPIKey(tenantID, keyID, apiKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Chave de API %s do tenant %s rotacionada para %s", old.Prefix, tenantID, apiKey.Prefix)
+	return &models.APIKeyCreatedResponse{TenantAPIKey: apiKey, Key: key}, nil
+}
+
+func (s *TenantService) RevokeAPIKey(tenantID string, keyID uuid.UUID) error {
+	if err := s.repo.RevokeAPIKey(tenantID, keyID); err != nil {
+		return err
+	}
+	s.logger.Infof("Chave de API %s do tenant %s revogada", keyID, tenantID)
+	return nil
+}
+
+// ResolveTenant devolve o tenant dono da chave, ou ErrInvalidAPIKey se ela
+// não existir, estiver revogada ou pertencer a um tenant desativado.
+func (s *TenantService) ResolveTenant(key string) (string, error) {
+	apiKey, err := s.repo.FindActiveAPIKey(HashAPIKey(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return "", ErrInvalidAPIKey
+	}
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(apiKey.ID, now); err != nil {
+			s.logger.Warnf("Falha ao registrar uso da chave %s: %v", apiKey.Prefix, err)
+		}
+	}
+	return apiKey.TenantID, nil
+}
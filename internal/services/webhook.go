@@ -0,0 +1,415 @@
+package services
+
+import (
+	"boot-whatsapp-golang/internal/config"
+	"boot-whatsapp-golang/internal/models"
+	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/fetcher"
+	"boot-whatsapp-golang/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+	ErrInvalidWebhookURL       = errors.New("URL de webhook não permitida")
+	ErrWebhookDeliveryNotFound = repository.ErrWebhookDeliveryNotFound
+)
+
+type WebhookDispatcher struct {
+	repo       *repository.WebhookRepository
+	cfg        config.WebhookConfig
+	logger     *logger.Logger
+	policy     fetcher.Policy
+	httpClient *http.Client
+
+	// targets guarda quais sessões têm webhook configurado, evitando uma
+	// consulta ao banco a cada evento recebido do WhatsApp.
+	targets sync.Map
+
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWebhookDispatcher entrega pela mesma política de rede dos downloads
+// (policy), já que a URL também é escolhida pelo tenant: sem ela, status e
+// erro das entregas serviriam para sondar a rede interna. Redirecionamentos
+// não são seguidos.
+func NewWebhookDispatcher(repo *repository.WebhookRepository, cfg config.WebhookConfig, policy fetcher.Policy, log *logger.Logger) *WebhookDispatcher {
+	httpClient := fetcher.NewClient(policy, cfg.Timeout)
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &WebhookDispatcher{
+		repo:       repo,
+		cfg:        cfg,
+		logger:     log,
+		policy:     policy,
+		httpClient: httpClient,
+		wake:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// CheckURL recusa, ao configurar o webhook, URLs que a entrega nunca
+// alcançaria: esquema ou host fora da política, IP literal e hosts que
+// resolvem para a rede interna. A conexão confere o endereço de novo a
+// cada entrega.
+func (d *WebhookDispatcher) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: URL inválida", ErrInvalidWebhookURL)
+	}
+	host := u.Hostname()
+	if err := d.policy.Check(u.Scheme, host); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	if d.policy.AllowPrivate {
+		return nil
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return fmt.Errorf("%w: use um nome de host, não um IP", ErrInvalidWebhookURL)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: host %s não resolve", ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if !fetcher.IsPublic(addr) {
+			return fmt.Errorf("%w: %s resolve para a rede interna", ErrInvalidWebhookURL, host)
+		}
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) Start() {
+	go d.run()
+}
+
+func (d *WebhookDispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+	})
+}
+
+func (d *WebhookDispatcher) SetTarget(sessionID uuid.UUID, enabled bool) {
+	if enabled {
+		d.targets.Store(sessionID, struct{}{})
+		return
+	}
+	d.targets.Delete(sessionID)
+}
+
+func (d *WebhookDispatcher) HasTarget(sessionID uuid.UUID) bool {
+	_, ok := d.targets.Load(sessionID)
+	return ok
+}
+
+func (d *WebhookDispatcher) Enqueue(session *models.WhatsAppSession, evt *models.SessionEvent) error {
+	if !d.HasTarget(session.ID) {
+		return nil
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar evento: %w", err)
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		SessionID:     session.ID,
+		TenantID:      session.TenantID,
+		EventID:       evt.ID,
+		EventType:     evt.Type,
+		Payload:       payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := d.repo.Enqueue(delivery); err != nil {
+		return err
+	}
+
+	d.Notify()
+	return nil
+}
+
+// Notify acorda o loop de entrega sem esperar o próximo ciclo de polling.
+func (d *WebhookDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.processDue()
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *WebhookDispatcher) processDue() {
+	due, err := d.repo.ListDue(time.Now(), d.cfg.BatchSize)
+	if err != nil {
+		d.logger.Errorf("Falha ao buscar webhooks pendentes: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range due {
+		wg.Add(1)
+		go func(delivery *repository.DueWebhookDelivery) {
+			defer wg.Done()
+			d.deliver(delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+func (d *WebhookDispatcher) deliver(delivery *repository.DueWebhookDelivery) {
+	attempts := delivery.Attempts + 1
+
+	if delivery.URL == "" {
+		d.fail(delivery, attempts, "webhook não configurado na sessão", nil, true)
+		return
+	}
+
+	statusCode, err := d.post(delivery)
+	if err == nil {
+		webhookDeliveries.Inc(delivery.EventType, "delivered")
+		if markErr := d.repo.MarkDelivered(delivery.ID, attempts, statusCode); markErr != nil {
+			d.logger.Errorf("Falha ao marcar webhook %s como entregue: %v", delivery.ID, markErr)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+	d.fail(delivery, attempts, err.Error(), code, attempts >= d.cfg.MaxAttempts)
+}
+
+func (d *WebhookDispatcher) fail(delivery *repository.DueWebhookDelivery, attempts int, reason string, statusCode *int, dead bool) {
+	next := time.Now().Add(d.backoff(attempts))
+	if dead {
+		webhookDeliveries.Inc(delivery.EventType, "dead")
+		d.logger.Warnf("Webhook %s (%s) movido para dead-letter após %d tentativas: %s", delivery.ID, delivery.EventType, attempts, reason)
+	} else {
+		webhookDeliveries.Inc(delivery.EventType, "failed")
+		d.logger.Warnf("Falha ao entregar webhook %s (tentativa %d): %s", delivery.ID, attempts, reason)
+	}
+
+	if err := d.repo.MarkFailed(delivery.ID, attempts, next, reason, statusCode, dead); err != nil {
+		d.logger.Errorf("Falha ao registrar erro do webhook %s: %v", delivery.ID, err)
+	}
+}
+
+func (d *WebhookDispatcher) post(delivery *repository.DueWebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("falha ao criar requisição: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "boot-whatsapp-golang-webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if delivery.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao enviar webhook: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		_, _ = io.Copy(io.Discard, io.LimitReader(Body, 64<<10))
+		if err := Body.Close(); err != nil {
+			d.logger.Errorf("falha ao fechar corpo da resposta do webhook: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receptor respondeu com status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	return retryBackoff(d.cfg.InitialBackoff, d.cfg.MaxBackoff, attempts)
+}
+
+// retryBackoff dobra a espera a cada tentativa, limitada a max.
+func retryBackoff(initial, max time.Duration, attempts int) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Jitter de até 10% para não sincronizar retries de várias sessões.
+	return delay + time.Duration(mrand.Int64N(int64(delay)/10+1))
+}
+
+// SignWebhookPayload calcula a assinatura enviada em X-Webhook-Signature:
+// HMAC-SHA256 de "<timestamp>.<corpo>" com o segredo da sessão.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("falha ao gerar segredo do webhook: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+func (s *MultiTenantWhatsAppService) applyWebhook(session *models.WhatsAppSession, url, secret string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.webhooks.CheckURL(ctx, url); err != nil {
+		return "", err
+	}
+
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return "", err
+		}
+		secret = generated
+	}
+
+	if err := s.repository.UpdateWebhook(session.ID, &url, &secret); err != nil {
+		return "", err
+	}
+	session.WebhookURL = &url
+	session.WebhookSecret = &secret
+	s.webhooks.SetTarget(session.ID, true)
+	return secret, nil
+}
+
+func (s *MultiTenantWhatsAppService) SetWebhook(sessionKey, tenantID string, req *models.WebhookConfigRequest) (*models.WebhookConfigResponse, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.applyWebhook(session, req.URL, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookConfigResponse{
+		SessionKey: sessionKey,
+		URL:        req.URL,
+		Secret:     secret,
+		Enabled:    true,
+	}, nil
+}
+
+func (s *MultiTenantWhatsAppService) GetWebhook(sessionKey, tenantID string) (*models.WebhookConfigResponse, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.WebhookConfigResponse{SessionKey: sessionKey}
+	if session.WebhookURL != nil && *session.WebhookURL != "" {
+		resp.URL = *session.WebhookURL
+		resp.Enabled = true
+	}
+	return resp, nil
+}
+
+func (s *MultiTenantWhatsAppService) DeleteWebhook(sessionKey, tenantID string) error {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.UpdateWebhook(session.ID, nil, nil); err != nil {
+		return err
+	}
+	s.webhooks.SetTarget(session.ID, false)
+	return nil
+}
+
+func (s *MultiTenantWhatsAppService) ListWebhookDeliveries(sessionKey, tenantID, status string, limit int) ([]*models.WebhookDelivery, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhooks.repo.ListBySession(session.ID, status, limit)
+}
+
+func (s *MultiTenantWhatsAppService) RetryWebhookDelivery(sessionKey, tenantID string, deliveryID uuid.UUID) error {
+	session, err := s.resolveSession(sessionKey, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhooks.repo.Requeue(deliveryID, session.ID); err != nil {
+		return err
+	}
+	s.webhooks.Notify()
+	return nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"boot-whatsapp-golang/internal/config"
 	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/validator"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -139,24 +140,20 @@ func (s *WhatsAppService) SendMediaMessage(number, caption, mediaURL, mediaBase6
 
 func (s *WhatsAppService) parsePhoneNumber(number string) (types.JID, error) {
 	number = strings.TrimSpace(number)
-	number = strings.ReplaceAll(number, " ", "")
-	number = strings.ReplaceAll(number, "-", "")
-	number = strings.ReplaceAll(number, "(", "")
-	number = strings.ReplaceAll(number, ")", "")
-
-	if !strings.HasSuffix(number, "@s.whatsapp.net") {
-		if !strings.HasPrefix(number, s.config.WhatsApp.DefaultCountry) {
-			number = s.config.WhatsApp.DefaultCountry + number
+	if strings.HasSuffix(number, "@s.whatsapp.net") {
+		jid, err := types.ParseJID(number)
+		if err != nil {
+			return types.JID{}, fmt.Errorf("número de telefone inválido: %w", err)
 		}
-		number = number + "@s.whatsapp.net"
+		return jid, nil
 	}
 
-	jid, err := types.ParseJID(number)
+	phone, err := validator.NormalizePhone(number, s.config.WhatsApp.DefaultCountry)
 	if err != nil {
 		return types.JID{}, fmt.Errorf("número de telefone inválido: %w", err)
 	}
 
-	return jid, nil
+	return types.NewJID(phone.Digits(), types.DefaultUserServer), nil
 }
 
 func (s *WhatsAppService) prepareMediaData(mediaURL, mediaBase64, mimeType string) ([]byte, string, string, error) {
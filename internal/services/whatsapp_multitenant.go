@@ -4,18 +4,22 @@ import (
 	"boot-whatsapp-golang/internal/config"
 	"boot-whatsapp-golang/internal/models"
 	"boot-whatsapp-golang/internal/repository"
+	"boot-whatsapp-golang/pkg/fetcher"
 	"boot-whatsapp-golang/pkg/logger"
+	"boot-whatsapp-golang/pkg/media"
+	"boot-whatsapp-golang/pkg/validator"
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
-	"net"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,6 +43,11 @@ type WhatsAppClient struct {
 	lastQRCode  string
 	lastQRTime  time.Time
 	lastQRExpAt time.Time
+
+	// pairPhone é preenchido quando o login usa código de pareamento.
+	pairPhone     string
+	lastPairCode  string
+	lastPairExpAt time.Time
 }
 
 func (c *WhatsAppClient) setQR(codeBase64 string, exp time.Time) {
@@ -60,6 +69,24 @@ func (c *WhatsAppClient) getQR() (string, time.Time, bool) {
 	return qr, exp, true
 }
 
+func (c *WhatsAppClient) setPairingCode(code string, exp time.Time) {
+	c.qrMu.Lock()
+	c.lastPairCode = code
+	c.lastPairExpAt = exp
+	c.qrMu.Unlock()
+}
+
+func (c *WhatsAppClient) getPairingCode() (string, time.Time, bool) {
+	c.qrMu.RLock()
+	code := c.lastPairCode
+	exp := c.lastPairExpAt
+	c.qrMu.RUnlock()
+	if code == "" {
+		return "", time.Time{}, false
+	}
+	return code, exp, true
+}
+
 const clientShards = 64
 
 type clientShard struct {
@@ -67,6 +94,12 @@ type clientShard struct {
 	m  map[string]*WhatsAppClient
 }
 
+// clientKey identifica o cliente em memória. A chave de sessão só é única
+// dentro do tenant, então dois tenants podem usar o mesmo nome.
+func clientKey(tenantID, sessionKey string) string {
+	return tenantID + ":" + sessionKey
+}
+
 type clientStore struct {
 	shards [clientShards]clientShard
 }
@@ -128,10 +161,23 @@ func (cs *clientStore) Range(fn func(key string, v *WhatsAppClient)) {
 type MultiTenantWhatsAppService struct {
 	clients *clientStore
 
-	config     *config.Config
-	logger     *logger.Logger
-	repository *repository.SessionRepository
-	container  *sqlstore.Container
+	config       *config.Config
+	logger       *logger.Logger
+	repository   *repository.SessionRepository
+	messages     *repository.MessageRepository
+	polls        *repository.PollRepository
+	uploads      *repository.MediaRepository
+	campaigns    *repository.CampaignRepository
+	templates    *repository.TemplateRepository
+	container    *sqlstore.Container
+	webhooks     *WebhookDispatcher
+	events       *EventHub
+	streamTokens *StreamTokens
+	queue        *OutboundQueue
+	runner       *CampaignRunner
+	limiter      *RateLimiter
+	previews     *LinkPreviewer
+	contacts     *contactCache
 
 	httpClient *http.Client
 }
@@ -147,35 +193,58 @@ func NewMultiTenantWhatsAppService(cfg *config.Config, db *sql.DB, log *logger.L
 
 	repo := repository.NewSessionRepository(db, log)
 
-	tr := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   10 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          1024,
-		MaxIdleConnsPerHost:   256,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
-	httpClient := &http.Client{
-		Transport: tr,
-		Timeout:   2 * time.Minute,
+	// Todo download feito em nome do cliente (media_url, prévias) passa
+	// pela política de rede de FETCH_*.
+	httpClient := fetcher.NewClient(fetcher.Policy{
+		AllowedSchemes: cfg.Fetch.AllowedSchemes,
+		AllowedHosts:   cfg.Fetch.AllowedHosts,
+		DeniedHosts:    cfg.Fetch.DeniedHosts,
+		AllowPrivate:   cfg.Fetch.AllowPrivate,
+		MaxRedirects:   cfg.Fetch.MaxRedirects,
+	}, cfg.Fetch.Timeout)
+
+	// Webhooks seguem a mesma proteção de rede; FETCH_ALLOWED_HOSTS restringe
+	// de onde vem a mídia e não vale para eles.
+	webhooks := NewWebhookDispatcher(repository.NewWebhookRepository(db, log), cfg.Webhook, fetcher.Policy{
+		AllowedSchemes: []string{"https", "http"},
+		DeniedHosts:    cfg.Fetch.DeniedHosts,
+		AllowPrivate:   cfg.Fetch.AllowPrivate,
+	}, log)
+
+	streamTokens, err := NewStreamTokens(cfg.Events.TokenSecret, cfg.Events.TokenTTL)
+	if err != nil {
+		return nil, err
 	}
 
 	service := &MultiTenantWhatsAppService{
-		clients:    newClientStore(),
-		config:     cfg,
-		logger:     log,
-		repository: repo,
-		container:  container,
-		httpClient: httpClient,
-	}
+		clients:      newClientStore(),
+		config:       cfg,
+		logger:       log,
+		repository:   repo,
+		messages:     repository.NewMessageRepository(db, log),
+		polls:        repository.NewPollRepository(db, log),
+		container:    container,
+		webhooks:     webhooks,
+		events:       NewEventHub(cfg.Events.BufferSize),
+		streamTokens: streamTokens,
+		limiter:      NewRateLimiter(cfg.Limits),
+		uploads:      repository.NewMediaRepository(db, log),
+		campaigns:    repository.NewCampaignRepository(db, log),
+		templates:    repository.NewTemplateRepository(db, log),
+		previews:     NewLinkPreviewer(cfg.Preview, httpClient, log),
+		contacts:     newContactCache(cfg.WhatsApp.ContactCacheTTL),
+		httpClient:   httpClient,
+	}
+	service.queue = NewOutboundQueue(repository.NewOutboundRepository(db, log), cfg.Queue, log, service.executeOutboundJob)
+	service.runner = NewCampaignRunner(service.campaigns, cfg.Queue, log, service.executeCampaignSend)
+	service.registerGauges()
 
 	if err := service.LoadExistingSessions(); err != nil {
 		log.Warnf("Falha ao carregar sessões existentes: %v", err)
 	}
+	webhooks.Start()
+	service.queue.Start()
+	service.runner.Start()
 
 	return service, nil
 }
@@ -203,6 +272,8 @@ func (s *MultiTenantWhatsAppService) LoadExistingSessions() error {
 	}
 
 	for _, session := range sessions {
+		s.webhooks.SetTarget(session.ID, session.WebhookURL != nil && *session.WebhookURL != "")
+
 		phone := ""
 		if session.PhoneNumber != nil {
 			phone = *session.PhoneNumber
@@ -233,7 +304,26 @@ func (s *MultiTenantWhatsAppService) LoadExistingSessions() error {
 }
 
 func (s *MultiTenantWhatsAppService) RegisterSession(req *models.RegisterSessionRequest, tenantID string) (*models.RegisterSessionResponse, error) {
-	if old, ok := s.clients.Delete(req.WhatsAppSessionKey); ok {
+	pairPhone := ""
+	if req.PairingMethod == models.PairingMethodCode {
+		jid, err := s.parsePhoneNumber(req.PhoneNumber)
+		if err != nil {
+			return nil, err
+		}
+		pairPhone = jid.User
+	}
+	// A URL é conferida antes de mexer na sessão para que um webhook
+	// recusado não derrube um cliente já conectado.
+	if req.WebhookURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := s.webhooks.CheckURL(ctx, req.WebhookURL)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if old, ok := s.clients.Delete(clientKey(tenantID, req.WhatsAppSessionKey)); ok {
 		if old.cancelQR != nil {
 			old.cancelQR()
 		}
@@ -262,6 +352,9 @@ func (s *MultiTenantWhatsAppService) RegisterSession(req *models.RegisterSession
 		session.DeviceJID = nil
 		session.QRCode = nil
 		session.QRCodeExpiresAt = nil
+		session.PairingPhone = nil
+		session.PairingCode = nil
+		session.PairingCodeExpiresAt = nil
 		session.LastConnectedAt = nil
 		session.UpdatedAt = time.Now()
 	} else {
@@ -280,6 +373,14 @@ func (s *MultiTenantWhatsAppService) RegisterSession(req *models.RegisterSession
 		}
 	}
 
+	webhookSecret := ""
+	if req.WebhookURL != "" {
+		webhookSecret, err = s.applyWebhook(session, req.WebhookURL, req.WebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	deviceStore := s.container.NewDevice()
 
 	waLogger := logger.NewWhatsAppLogger(fmt.Sprintf("[WA:%s] ", session.WhatsAppSessionKey), logger.INFO)
@@ -294,15 +395,16 @@ func (s *MultiTenantWhatsAppService) RegisterSession(req *models.RegisterSession
 	}
 
 	waClient := &WhatsAppClient{
-		Client:   client,
-		Session:  session,
-		cancelQR: cancelQR,
+		Client:    client,
+		Session:   session,
+		cancelQR:  cancelQR,
+		pairPhone: pairPhone,
 	}
-	s.clients.Set(session.WhatsAppSessionKey, waClient)
+	s.clients.Set(clientKey(session.TenantID, session.WhatsAppSessionKey), waClient)
 
 	if err := client.Connect(); err != nil {
 		cancelQR()
-		s.clients.Delete(session.WhatsAppSessionKey)
+		s.clients.Delete(clientKey(session.TenantID, session.WhatsAppSessionKey))
 		return nil, fmt.Errorf("falha ao conectar: %w", err)
 	}
 
@@ -329,15 +431,36 @@ func (s *MultiTenantWhatsAppService) RegisterSession(req *models.RegisterSession
 					QRCodeBase64:       "",
 					Status:             models.SessionStatusConnected,
 					ExpiresAt:          time.Time{},
+					WebhookSecret:      webhookSecret,
 				}, nil
 			}
 			if qr, exp, ok := waClient.getQR(); ok {
+				// O primeiro QR indica que o websocket de login está pronto,
+				// pré-requisito do PairPhone.
+				if pairPhone != "" {
+					code, codeExp, err := s.requestPairingCode(session, waClient)
+					if err != nil {
+						cancelQR()
+						s.clients.Delete(clientKey(session.TenantID, session.WhatsAppSessionKey))
+						client.Disconnect()
+						return nil, err
+					}
+					return &models.RegisterSessionResponse{
+						ID:                 session.ID,
+						WhatsAppSessionKey: session.WhatsAppSessionKey,
+						Status:             models.SessionStatusPending,
+						ExpiresAt:          codeExp,
+						WebhookSecret:      webhookSecret,
+						PairingCode:        code,
+					}, nil
+				}
 				return &models.RegisterSessionResponse{
 					ID:                 session.ID,
 					WhatsAppSessionKey: session.WhatsAppSessionKey,
 					QRCodeBase64:       qr,
 					Status:             models.SessionStatusPending,
 					ExpiresAt:          exp,
+					WebhookSecret:      webhookSecret,
 				}, nil
 			}
 		}
@@ -361,9 +484,14 @@ func (s *MultiTenantWhatsAppService) monitorQRCode(session *models.WhatsAppSessi
 			}
 
 			waClient.setQR(qrCodeBase64, exp)
+			qrCodesGenerated.Inc(session.TenantID, session.WhatsAppSessionKey)
 			if err := s.repository.UpdateQRCode(session.ID, qrCodeBase64, exp); err != nil {
 				s.logger.Errorf("Falha ao atualizar QR code no banco: %v", err)
 			}
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeQRCode, &models.QRCodeEvent{
+				QRCodeBase64: qrCodeBase64,
+				ExpiresAt:    exp,
+			}))
 
 		case "success":
 			if waClient.cancelQR != nil {
@@ -382,9 +510,20 @@ func (s *MultiTenantWhatsAppService) monitorQRCode(session *models.WhatsAppSessi
 }
 
 func (s *MultiTenantWhatsAppService) registerEventHandlers(client *whatsmeow.Client, session *models.WhatsAppSession) {
+	// A reconexão automática do whatsmeow só avisa as falhas; o sucesso é o
+	// Connected que segue um Disconnected.
+	var reconnecting atomic.Bool
+	client.AutoReconnectHook = func(error) bool {
+		reconnectAttempts.Inc(session.TenantID, session.WhatsAppSessionKey, "failure")
+		return true
+	}
+
 	client.AddEventHandler(func(evt interface{}) {
-		switch evt.(type) {
+		switch e := evt.(type) {
 		case *events.Connected:
+			if reconnecting.Swap(false) {
+				reconnectAttempts.Inc(session.TenantID, session.WhatsAppSessionKey, "success")
+			}
 			phoneNumber := ""
 			deviceJID := ""
 			if client.Store != nil && client.Store.ID != nil {
@@ -392,15 +531,47 @@ func (s *MultiTenantWhatsAppService) registerEventHandlers(client *whatsmeow.Cli
 				deviceJID = client.Store.ID.String()
 			}
 			_ = s.repository.UpdateStatus(session.ID, models.SessionStatusConnected, phoneNumber, deviceJID)
+			s.queue.Notify(session.ID)
+			s.runner.NotifySession(session.ID)
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeConnection, &models.ConnectionEvent{
+				Status:      models.SessionStatusConnected,
+				PhoneNumber: phoneNumber,
+			}))
 
 		case *events.Disconnected:
+			reconnecting.Store(true)
 			_ = s.repository.UpdateStatus(session.ID, models.SessionStatusDisconnected, "", "")
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeConnection, &models.ConnectionEvent{
+				Status: models.SessionStatusDisconnected,
+			}))
 
 		case *events.LoggedOut:
 			if client.Store != nil {
 				_ = client.Store.Delete(context.Background())
 			}
 			_ = s.repository.MarkLoggedOut(session.ID)
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeConnection, &models.ConnectionEvent{
+				Status: "logged_out",
+				Reason: e.Reason.String(),
+			}))
+
+		case *events.Message:
+			if e.Message.GetPollUpdateMessage() != nil {
+				s.handlePollVote(client, session, e)
+				return
+			}
+			s.recordInboundMessage(session, e)
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeMessage, normalizeMessageEvent(e)))
+
+		case *events.Receipt:
+			s.applyReceipt(session, e)
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeReceipt, normalizeReceiptEvent(e)))
+
+		case *events.Presence:
+			s.publishEvent(session, newSessionEvent(session, models.EventTypePresence, normalizePresenceEvent(e)))
+
+		case *events.ChatPresence:
+			s.publishEvent(session, newSessionEvent(session, models.EventTypeChatPresence, normalizeChatPresenceEvent(e)))
 		}
 	})
 }
@@ -445,7 +616,7 @@ func (s *MultiTenantWhatsAppService) reconnectSession(session *models.WhatsAppSe
 	s.registerEventHandlers(client, session)
 
 	waClient := &WhatsAppClient{Client: client, Session: session}
-	s.clients.Set(session.WhatsAppSessionKey, waClient)
+	s.clients.Set(clientKey(session.TenantID, session.WhatsAppSessionKey), waClient)
 
 	go func() {
 		if err := client.Connect(); err != nil {
@@ -459,15 +630,41 @@ func (s *MultiTenantWhatsAppService) reconnectSession(session *models.WhatsAppSe
 
 var (
 	ErrSessionAlreadyConnected = fmt.Errorf("SESSION_ALREADY_CONNECTED")
+	ErrSessionNotFound         = fmt.Errorf("sessão não encontrada ou não pertence a este tenant")
+	ErrSessionForbidden        = fmt.Errorf("sessão pertence a outro tenant")
 )
 
-func (s *MultiTenantWhatsAppService) GetQRCode(sessionKey string, tenantID string) (string, error) {
+// resolveSession é o único caminho para transformar uma chave de sessão
+// recebida na requisição em sessão: a busca é sempre feita dentro do tenant
+// autenticado. Chaves que existem apenas em outro tenant devolvem
+// ErrSessionForbidden, para diferenciar de chaves inexistentes.
+func (s *MultiTenantWhatsAppService) resolveSession(sessionKey, tenantID string) (*models.WhatsAppSession, error) {
 	session, err := s.repository.GetBySessionKeyAndTenant(sessionKey, tenantID)
+	if err == nil {
+		return session, nil
+	}
+	if !errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, err
+	}
+
+	exists, err := s.repository.ExistsBySessionKey(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		s.logger.Warnf("Tenant %s tentou usar a sessão %s de outro tenant", tenantID, sessionKey)
+		return nil, ErrSessionForbidden
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (s *MultiTenantWhatsAppService) GetQRCode(sessionKey string, tenantID string) (string, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
 	if err != nil {
-		return "", fmt.Errorf("sessão não encontrada ou não pertence a este tenant")
+		return "", err
 	}
 
-	waClient, ok := s.clients.Get(sessionKey)
+	waClient, ok := s.clients.Get(clientKey(tenantID, sessionKey))
 	if !ok {
 		if session.QRCode != nil && session.QRCodeExpiresAt != nil {
 			if time.Now().Before(*session.QRCodeExpiresAt) {
@@ -508,6 +705,9 @@ func (s *MultiTenantWhatsAppService) refreshQRCode(session *models.WhatsAppSessi
 
 	qrCtx, cancelQR := context.WithCancel(context.Background())
 	waClient.cancelQR = cancelQR
+	// Descarta o código anterior para aguardar um QR do novo websocket de
+	// login; o código de pareamento depende dele estar pronto.
+	waClient.setQR("", time.Time{})
 
 	qrChan, err := waClient.Client.GetQRChannel(qrCtx)
 	if err != nil {
@@ -541,10 +741,21 @@ func (s *MultiTenantWhatsAppService) refreshQRCode(session *models.WhatsAppSessi
 	}
 }
 
-func (s *MultiTenantWhatsAppService) GetClient(sessionKey string) (*whatsmeow.Client, error) {
-	waClient, ok := s.clients.Get(sessionKey)
+func (s *MultiTenantWhatsAppService) GetClient(sessionKey, tenantID string) (*whatsmeow.Client, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return waClient.Client, nil
+}
+
+// getConnectedClient espera tenant e chave já validados por resolveSession
+// (ou gravados num job que passou por ele); nunca deve receber valores
+// vindos diretamente da requisição.
+func (s *MultiTenantWhatsAppService) getConnectedClient(tenantID, sessionKey string) (*WhatsAppClient, error) {
+	waClient, ok := s.clients.Get(clientKey(tenantID, sessionKey))
 	if !ok {
-		return nil, fmt.Errorf("sessão não encontrada: %s", sessionKey)
+		return nil, fmt.Errorf("sessão não está ativa: %s", sessionKey)
 	}
 
 	if waClient.Client == nil || waClient.Client.Store == nil || waClient.Client.Store.ID == nil {
@@ -553,67 +764,170 @@ func (s *MultiTenantWhatsAppService) GetClient(sessionKey string) (*whatsmeow.Cl
 	if !waClient.Client.IsConnected() {
 		return nil, fmt.Errorf("sessão não está conectada")
 	}
-	return waClient.Client, nil
+	return waClient, nil
 }
 
-func (s *MultiTenantWhatsAppService) SendTextMessage(sessionKey, number, text string) error {
-	client, err := s.GetClient(sessionKey)
+// connectedSession resolve a sessão no tenant e devolve o cliente conectado.
+func (s *MultiTenantWhatsAppService) connectedSession(sessionKey, tenantID string) (*WhatsAppClient, error) {
+	session, err := s.resolveSession(sessionKey, tenantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return s.getConnectedClient(session.TenantID, session.WhatsAppSessionKey)
+}
 
-	jid, err := s.parsePhoneNumber(number)
+func (s *MultiTenantWhatsAppService) SendTextMessage(sessionKey, tenantID string, req *models.MessageRequest) (*models.MessageSent, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	_, err = client.SendMessage(ctx, jid, &waE2E.Message{
-		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
-			Text: proto.String(text),
-		},
-	})
+	return s.sendText(ctx, waClient, req)
+}
+
+func (s *MultiTenantWhatsAppService) sendText(ctx context.Context, waClient *WhatsAppClient, req *models.MessageRequest) (*models.MessageSent, error) {
+	preview, err := s.textPreview(ctx, req)
 	if err != nil {
-		return fmt.Errorf("falha ao enviar mensagem: %w", err)
+		return nil, err
 	}
-	return nil
+
+	return s.sendBuilt(ctx, waClient, req.Number, req.Mentions, req.QuotedMessageID, func(ctxInfo *waE2E.ContextInfo) *waE2E.Message {
+		ext := &waE2E.ExtendedTextMessage{
+			Text:        proto.String(req.Text),
+			ContextInfo: ctxInfo,
+		}
+		if preview != nil {
+			preview.apply(ext)
+		}
+		return &waE2E.Message{ExtendedTextMessage: ext}
+	})
 }
 
-func (s *MultiTenantWhatsAppService) SendMediaMessage(sessionKey, number, caption, mediaURL, mediaBase64, mimeType string) error {
-	client, err := s.GetClient(sessionKey)
+// sendBuilt resolve destinatário e contexto (menções/citação), respeita os
+// limites de envio e registra a mensagem montada por build.
+func (s *MultiTenantWhatsAppService) sendBuilt(ctx context.Context, waClient *WhatsAppClient, number string, mentions []string, quotedID string, build func(*waE2E.ContextInfo) *waE2E.Message) (*models.MessageSent, error) {
+	jid, err := s.resolveRecipient(ctx, waClient, number)
 	if err != nil {
-		return err
+		return nil, recipientError(err)
 	}
-
-	jid, err := s.parsePhoneNumber(number)
+	ctxInfo, err := s.messageContext(waClient, jid, mentions, quotedID)
 	if err != nil {
-		return err
+		return nil, recipientError(err)
+	}
+	if err := s.acquireSendSlot(waClient.Session, jid); err != nil {
+		return nil, err
 	}
 
-	mediaData, contentType, filename, err := s.prepareMedia(mediaURL, mediaBase64, mimeType)
+	msg := build(ctxInfo)
+	resp, err := waClient.Client.SendMessage(ctx, jid, msg)
 	if err != nil {
-		return err
+		msgType, _ := messageContentSummary(msg)
+		observeSend(waClient, msgType, err)
+		return nil, sendError(jid, err, "falha ao enviar mensagem")
 	}
+	return s.recordOutboundMessage(waClient, number, jid, msg, resp), nil
+}
 
-	mediaType := s.determineMediaType(contentType)
+func (s *MultiTenantWhatsAppService) SendMediaMessage(sessionKey, tenantID string, req *models.MediaRequest) (*models.MessageSent, error) {
+	return s.SendMediaFile(sessionKey, tenantID, req, nil)
+}
+
+// SendMediaFile envia uma mídia já gravada em disco (upload multipart). Com
+// file nil, a mídia vem de media_url ou media_base64. O arquivo é fechado em
+// qualquer caso.
+func (s *MultiTenantWhatsAppService) SendMediaFile(sessionKey, tenantID string, req *models.MediaRequest, file *media.File) (*models.MessageSent, error) {
+	waClient, err := s.connectedSession(sessionKey, tenantID)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	uploaded, err := client.Upload(ctx, mediaData, mediaType)
+	return s.sendMedia(ctx, waClient, req, file)
+}
+
+func (s *MultiTenantWhatsAppService) sendMedia(ctx context.Context, waClient *WhatsAppClient, req *models.MediaRequest, file *media.File) (*models.MessageSent, error) {
+	defer func() { file.Close() }()
+
+	jid, err := s.resolveRecipient(ctx, waClient, req.Number)
+	if err != nil {
+		return nil, recipientError(err)
+	}
+	ctxInfo, err := s.messageContext(waClient, jid, req.Mentions, req.QuotedMessageID)
 	if err != nil {
-		return fmt.Errorf("falha ao fazer upload da mídia: %w", err)
+		return nil, recipientError(err)
+	}
+
+	var extra whatsmeow.SendRequestExtra
+	var uploaded whatsmeow.UploadResponse
+	var d *mediaDescription
+
+	if req.MediaID != "" {
+		// Mídia pré-enviada: upload e descrição vêm do registro.
+		if jid.Server == types.NewsletterServer {
+			return nil, permanent(ErrMediaIDNewsletter)
+		}
+		if uploaded, d, err = s.storedMedia(waClient.Session.TenantID, req.MediaID); err != nil {
+			return nil, err
+		}
+		if req.FileName != "" {
+			d.FileName = req.FileName
+		}
+	} else {
+		contentType, filename := req.MimeType, req.FileName
+		if file == nil {
+			file, contentType, filename, err = s.prepareMedia(req.MediaURL, req.MediaBase64, req.MimeType)
+			if err != nil {
+				return nil, err
+			}
+			if req.FileName != "" {
+				filename = req.FileName
+			}
+		}
+
+		var mediaType whatsmeow.MediaType
+		mediaType, d, err = s.describeMedia(req.Mode, req.ThumbnailBase64, file, contentType, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		// Canais recebem mídia sem criptografia, por um upload próprio cujo
+		// handle acompanha o envio. Os demais destinos reaproveitam uploads
+		// idênticos. O upload lê o arquivo em streaming.
+		if jid.Server == types.NewsletterServer {
+			uploaded, err = waClient.Client.UploadNewsletterReader(ctx, file.Reader(), mediaType)
+			extra.MediaHandle = uploaded.Handle
+			if err == nil {
+				observeUpload(waClient, mediaTypeNames[mediaType], uploaded.FileLength)
+			}
+		} else {
+			uploaded, _, err = s.uploadCached(ctx, waClient, file, mediaType, d, false)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("falha ao fazer upload da mídia: %w", err)
+		}
 	}
 
-	msg := s.buildMediaMessage(uploaded, mediaData, contentType, caption, filename)
+	msg := s.buildMedia(uploaded, d, req.Caption)
+	setMediaContext(msg, ctxInfo)
 
-	_, err = client.SendMessage(ctx, jid, msg)
+	// O slot só é tomado com a mídia pronta: downloads, validações e
+	// uploads que falham não contam como envio nos limites.
+	if err := s.acquireSendSlot(waClient.Session, jid); err != nil {
+		return nil, err
+	}
+	resp, err := waClient.Client.SendMessage(ctx, jid, msg, extra)
 	if err != nil {
-		return fmt.Errorf("falha ao enviar mensagem de mídia: %w", err)
+		msgType, _ := messageContentSummary(msg)
+		observeSend(waClient, msgType, err)
+		return nil, sendError(jid, err, "falha ao enviar mensagem de mídia")
 	}
-	return nil
+	return s.recordOutboundMessage(waClient, req.Number, jid, msg, resp), nil
 }
 
 func (s *MultiTenantWhatsAppService) ListSessions() ([]*models.WhatsAppSession, error) {
@@ -625,16 +939,15 @@ func (s *MultiTenantWhatsAppService) ListSessionsByTenant(tenantID string) ([]*m
 }
 
 func (s *MultiTenantWhatsAppService) GetSessionByKeyAndTenant(sessionKey string, tenantID string) (*models.WhatsAppSession, error) {
-	return s.repository.GetBySessionKeyAndTenant(sessionKey, tenantID)
+	return s.resolveSession(sessionKey, tenantID)
 }
 
 func (s *MultiTenantWhatsAppService) DisconnectSession(sessionKey string, tenantID string) error {
-	_, err := s.repository.GetBySessionKeyAndTenant(sessionKey, tenantID)
-	if err != nil {
-		return fmt.Errorf("sessão não encontrada ou não pertence a este tenant")
+	if _, err := s.resolveSession(sessionKey, tenantID); err != nil {
+		return err
 	}
 
-	waClient, ok := s.clients.Delete(sessionKey)
+	waClient, ok := s.clients.Delete(clientKey(tenantID, sessionKey))
 	if !ok {
 		return fmt.Errorf("sessão não está conectada")
 	}
@@ -659,103 +972,120 @@ func (s *MultiTenantWhatsAppService) DisconnectSession(sessionKey string, tenant
 func (s *MultiTenantWhatsAppService) DeleteSession(sessionKey string, tenantID string) error {
 	_ = s.DisconnectSession(sessionKey, tenantID)
 
-	session, err := s.repository.GetBySessionKeyAndTenant(sessionKey, tenantID)
+	session, err := s.resolveSession(sessionKey, tenantID)
 	if err != nil {
-		return fmt.Errorf("sessão não encontrada ou não pertence a este tenant")
+		return err
 	}
+	s.webhooks.SetTarget(session.ID, false)
+	s.events.Remove(session.ID)
+	s.limiter.Remove(sessionKey, tenantID)
+	s.contacts.Remove(session.ID)
+	forgetSessionMetrics(session.TenantID, session.WhatsAppSessionKey)
 	return s.repository.Delete(session.ID)
 }
 
+// parsePhoneNumber converte o número para o JID da grafia canônica, sem
+// consultar o WhatsApp. Envios usam resolvePhone, que escolhe entre as
+// grafias alternativas a registrada.
 func (s *MultiTenantWhatsAppService) parsePhoneNumber(number string) (types.JID, error) {
 	n := strings.TrimSpace(number)
-	n = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(n)
-
-	if !strings.HasSuffix(n, "@s.whatsapp.net") {
-		if !strings.HasPrefix(n, s.config.WhatsApp.DefaultCountry) {
-			n = s.config.WhatsApp.DefaultCountry + n
+	if strings.HasSuffix(n, "@"+types.DefaultUserServer) {
+		jid, err := types.ParseJID(n)
+		if err != nil {
+			return types.JID{}, fmt.Errorf("número de telefone inválido: %w", err)
 		}
-		n = n + "@s.whatsapp.net"
+		return jid, nil
 	}
 
-	jid, err := types.ParseJID(n)
+	phone, err := validator.NormalizePhone(n, s.config.WhatsApp.DefaultCountry)
 	if err != nil {
 		return types.JID{}, fmt.Errorf("número de telefone inválido: %w", err)
 	}
-	return jid, nil
+	return types.NewJID(phone.Digits(), types.DefaultUserServer), nil
 }
 
-func (s *MultiTenantWhatsAppService) prepareMedia(mediaURL, mediaBase64, mimeType string) ([]byte, string, string, error) {
+// mediaLimit é o tamanho máximo de mídia aceito em qualquer origem.
+func (s *MultiTenantWhatsAppService) mediaLimit() int64 {
+	if limit := s.config.Server.MaxUploadSize; limit > 0 {
+		return limit
+	}
+	return 25 << 20 // fallback 25MB
+}
+
+// prepareMedia grava a mídia do pedido num arquivo temporário. Quem chama
+// fecha o arquivo.
+func (s *MultiTenantWhatsAppService) prepareMedia(mediaURL, mediaBase64, mimeType string) (*media.File, string, string, error) {
 	switch {
 	case mediaBase64 != "":
-		data, ct, err := s.decodeBase64Media(mediaBase64, mimeType)
+		file, ct, err := s.decodeBase64Media(mediaBase64, mimeType)
 		if err != nil {
 			return nil, "", "", err
 		}
-		ext := ""
-		if exts, _ := mime.ExtensionsByType(ct); len(exts) > 0 {
-			ext = exts[0]
-		}
-		if ext == "" {
-			return data, ct, "media", nil
-		}
-		return data, ct, "media" + ext, nil
+		return file, ct, mediaFileName("", ct), nil
 
 	case mediaURL != "":
-		data, ct, err := s.downloadMedia(mediaURL)
+		file, ct, err := s.downloadMedia(mediaURL)
 		if err != nil {
 			return nil, "", "", err
 		}
-		ext := filepath.Ext(mediaURL)
-		if ext == "" {
-			if exts, _ := mime.ExtensionsByType(ct); len(exts) > 0 {
-				ext = exts[0]
-			}
-		}
-		if ext == "" {
-			return data, ct, "media", nil
-		}
-		return data, ct, "media" + ext, nil
+		return file, ct, mediaFileName(filepath.Ext(mediaURL), ct), nil
 
 	default:
-		return nil, "", "", fmt.Errorf("é necessário fornecer media_url ou media_base64")
+		return nil, "", "", permanent(fmt.Errorf("é necessário fornecer media_url ou media_base64"))
 	}
 }
 
-func (s *MultiTenantWhatsAppService) decodeBase64Media(base64Str, mimeType string) ([]byte, string, error) {
-	b := strings.TrimSpace(base64Str)
-
-	if strings.HasPrefix(b, "data:") {
-		if idx := strings.IndexByte(b, ','); idx != -1 {
-			b = b[idx+1:]
+// mediaFileName monta o nome do documento a partir da extensão conhecida ou,
+// na falta dela, do tipo MIME.
+func mediaFileName(ext, contentType string) string {
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			ext = exts[0]
 		}
 	}
+	return "media" + ext
+}
+
+func (s *MultiTenantWhatsAppService) decodeBase64Media(base64Str, mimeType string) (*media.File, string, error) {
+	b := stripDataURI(base64Str)
 	b = strings.ReplaceAll(b, "\\n", "")
 	b = strings.ReplaceAll(b, "\\r", "")
 	b = strings.TrimSpace(b)
 
-	data, err := base64.StdEncoding.DecodeString(b)
+	file, err := media.Spool(base64.NewDecoder(base64.StdEncoding, strings.NewReader(b)), s.mediaLimit())
 	if err != nil {
-		return nil, "", fmt.Errorf("falha ao decodificar base64: %w", err)
+		var corrupt base64.CorruptInputError
+		if errors.As(err, &corrupt) {
+			return nil, "", permanent(fmt.Errorf("falha ao decodificar base64: %w", err))
+		}
+		return nil, "", mediaSpoolError(err)
 	}
 
 	ct := mimeType
 	if ct == "" {
-		ct = http.DetectContentType(data)
+		ct = file.DetectContentType()
 	}
-	return data, ct, nil
+	return file, ct, nil
 }
 
-func (s *MultiTenantWhatsAppService) downloadMedia(url string) ([]byte, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+// downloadMedia baixa media_url pelo cliente com política de rede. O
+// Content-Length é conferido antes de ler qualquer byte e o corpo é cortado
+// no limite; em ambos os casos o erro é ErrMediaTooLarge, nunca uma mídia
+// truncada. O Content-Type informado precisa bater com o conteúdo.
+func (s *MultiTenantWhatsAppService) downloadMedia(url string) (*media.File, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Fetch.Timeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("falha ao criar requisição: %w", err)
+		return nil, "", permanent(fmt.Errorf("falha ao criar requisição: %w", err))
 	}
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
+		if errors.Is(err, fetcher.ErrBlocked) || errors.Is(err, fetcher.ErrTooManyRedirects) {
+			return nil, "", permanent(fmt.Errorf("%w: %v", ErrMediaURLBlocked, err))
+		}
 		return nil, "", fmt.Errorf("falha ao baixar mídia: %w", err)
 	}
 	defer func(Body io.ReadCloser) {
@@ -766,24 +1096,39 @@ func (s *MultiTenantWhatsAppService) downloadMedia(url string) ([]byte, string,
 	}(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("falha ao baixar mídia: status %d", resp.StatusCode)
+		err := fmt.Errorf("falha ao baixar mídia: status %d", resp.StatusCode)
+		// 4xx não muda com novas tentativas, exceto limite de taxa.
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, "", permanent(err)
+		}
+		return nil, "", err
 	}
 
-	limit := s.config.Server.MaxUploadSize
-	if limit <= 0 {
-		limit = 25 << 20 // fallback 25MB
+	limit := s.mediaLimit()
+	if resp.ContentLength > limit {
+		return nil, "", permanent(fmt.Errorf("%w: %d bytes (máximo %d)", ErrMediaTooLarge, resp.ContentLength, limit))
 	}
-
-	data, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	file, err := media.Spool(resp.Body, limit)
 	if err != nil {
-		return nil, "", fmt.Errorf("falha ao ler mídia: %w", err)
+		return nil, "", mediaSpoolError(err)
 	}
 
 	ct := resp.Header.Get("Content-Type")
+	if err := file.CheckContentType(ct); err != nil {
+		file.Close()
+		return nil, "", permanent(err)
+	}
 	if ct == "" {
-		ct = http.DetectContentType(data)
+		ct = file.DetectContentType()
 	}
-	return data, ct, nil
+	return file, ct, nil
+}
+
+func mediaSpoolError(err error) error {
+	if errors.Is(err, ErrMediaTooLarge) {
+		return permanent(err)
+	}
+	return fmt.Errorf("falha ao ler mídia: %w", err)
 }
 
 func (s *MultiTenantWhatsAppService) determineMediaType(contentType string) whatsmeow.MediaType {
@@ -799,9 +1144,13 @@ func (s *MultiTenantWhatsAppService) determineMediaType(contentType string) what
 	}
 }
 
-func (s *MultiTenantWhatsAppService) buildMediaMessage(uploaded whatsmeow.UploadResponse, mediaData []byte, contentType, caption, filename string) *waE2E.Message {
+func (s *MultiTenantWhatsAppService) buildMediaMessage(uploaded whatsmeow.UploadResponse, size uint64, contentType, caption, filename string, meta *mediaMeta) *waE2E.Message {
 	mt := s.determineMediaType(contentType)
-	size := uint64(len(mediaData))
+
+	var thumb []byte
+	if meta.Thumbnail != nil {
+		thumb = meta.Thumbnail.JPEG
+	}
 
 	switch mt {
 	case whatsmeow.MediaImage:
@@ -815,6 +1164,9 @@ func (s *MultiTenantWhatsAppService) buildMediaMessage(uploaded whatsmeow.Upload
 				FileSHA256:    uploaded.FileSHA256,
 				FileLength:    proto.Uint64(size),
 				Caption:       proto.String(caption),
+				Width:         optionalUint32(meta.Width),
+				Height:        optionalUint32(meta.Height),
+				JPEGThumbnail: thumb,
 			},
 		}
 	case whatsmeow.MediaVideo:
@@ -828,6 +1180,10 @@ func (s *MultiTenantWhatsAppService) buildMediaMessage(uploaded whatsmeow.Upload
 				FileSHA256:    uploaded.FileSHA256,
 				FileLength:    proto.Uint64(size),
 				Caption:       proto.String(caption),
+				Seconds:       optionalUint32(meta.Seconds),
+				Width:         optionalUint32(meta.Width),
+				Height:        optionalUint32(meta.Height),
+				JPEGThumbnail: thumb,
 			},
 		}
 	case whatsmeow.MediaAudio:
@@ -843,23 +1199,41 @@ func (s *MultiTenantWhatsAppService) buildMediaMessage(uploaded whatsmeow.Upload
 			},
 		}
 	default:
-		return &waE2E.Message{
-			DocumentMessage: &waE2E.DocumentMessage{
-				URL:           proto.String(uploaded.URL),
-				DirectPath:    proto.String(uploaded.DirectPath),
-				MediaKey:      uploaded.MediaKey,
-				Mimetype:      proto.String(contentType),
-				FileEncSHA256: uploaded.FileEncSHA256,
-				FileSHA256:    uploaded.FileSHA256,
-				FileLength:    proto.Uint64(size),
-				FileName:      proto.String(filename),
-				Caption:       proto.String(caption),
-			},
+		doc := &waE2E.DocumentMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			Mimetype:      proto.String(contentType),
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(size),
+			FileName:      proto.String(filename),
+			Caption:       proto.String(caption),
+			PageCount:     optionalUint32(meta.PageCount),
 		}
+		if meta.Thumbnail != nil {
+			doc.JPEGThumbnail = meta.Thumbnail.JPEG
+			doc.ThumbnailWidth = proto.Uint32(uint32(meta.Thumbnail.Width))
+			doc.ThumbnailHeight = proto.Uint32(uint32(meta.Thumbnail.Height))
+		}
+		return &waE2E.Message{DocumentMessage: doc}
 	}
 }
 
+// optionalUint32 omite o campo quando o valor não pôde ser determinado.
+func optionalUint32(v uint32) *uint32 {
+	if v == 0 {
+		return nil
+	}
+	return proto.Uint32(v)
+}
+
 func (s *MultiTenantWhatsAppService) Shutdown() {
+	s.logger.Info("Drenando fila de envio...")
+	s.queue.Stop()
+	s.runner.Stop()
+	s.webhooks.Stop()
+
 	s.logger.Info("Desconectando todas as sessões...")
 	s.clients.Range(func(key string, waClient *WhatsAppClient) {
 		if waClient != nil && waClient.cancelQR != nil {
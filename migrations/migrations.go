@@ -0,0 +1,284 @@
+// Package migrations embute o schema do serviço no binário, com uma variante
+// de cada migração por driver (postgres e sqlite3), e registra as versões
+// aplicadas na tabela schema_migrations.
+//
+// Toda tabela nova precisa de NNN_nome.up.sql e NNN_nome.down.sql nos dois
+// diretórios; New recusa conjuntos divergentes entre drivers.
+package migrations
+
+import (
+	"boot-whatsapp-golang/pkg/logger"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite3/*.sql
+var files embed.FS
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
+// advisoryLockID serializa migrações de réplicas que sobem juntas no Postgres.
+const advisoryLockID = 7310418022
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+	logger     *logger.Logger
+}
+
+func New(db *sql.DB, driver string, log *logger.Logger) (*Migrator, error) {
+	if driver != DriverPostgres && driver != DriverSQLite {
+		return nil, fmt.Errorf("driver sem migrações: %s", driver)
+	}
+
+	byDriver := make(map[string][]Migration, 2)
+	for _, d := range []string{DriverPostgres, DriverSQLite} {
+		list, err := load(d)
+		if err != nil {
+			return nil, err
+		}
+		byDriver[d] = list
+	}
+
+	pg, lite := byDriver[DriverPostgres], byDriver[DriverSQLite]
+	if len(pg) != len(lite) {
+		return nil, fmt.Errorf("migrações divergentes: %d para postgres, %d para sqlite3", len(pg), len(lite))
+	}
+	for i := range pg {
+		if pg[i].Version != lite[i].Version || pg[i].Name != lite[i].Name {
+			return nil, fmt.Errorf("migrações divergentes entre drivers: %03d_%s x %03d_%s",
+				pg[i].Version, pg[i].Name, lite[i].Version, lite[i].Name)
+		}
+	}
+
+	return &Migrator{db: db, driver: driver, migrations: byDriver[driver], logger: log}, nil
+}
+
+func load(driver string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, driver)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler migrações de %s: %w", driver, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s/%s", driver, entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(files, driver+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler %s/%s: %w", driver, entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("versão %03d com nomes diferentes em %s", version, driver)
+		}
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migração %03d_%s de %s sem up ou down", m.Version, m.Name, driver)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// withConn executa fn numa conexão dedicada, com a tabela de controle criada
+// e, no Postgres, sob advisory lock.
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("falha ao obter conexão: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Errorf("Erro ao fechar conexão de migração: %v", err)
+		}
+	}()
+
+	if m.driver == DriverPostgres {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+			return fmt.Errorf("falha ao obter lock de migração: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID); err != nil {
+				m.logger.Errorf("Erro ao liberar lock de migração: %v", err)
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("falha ao criar schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar schema_migrations: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.logger.Errorf("Erro ao fechar linhas de schema_migrations: %v", err)
+		}
+	}()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("falha ao escanear schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// run executa o SQL da migração e atualiza schema_migrations na mesma transação.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	body, record, args := mig.down, `DELETE FROM schema_migrations WHERE version = $1`, []any{mig.Version}
+	if up {
+		body = mig.up
+		record = `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`
+		args = []any{mig.Version, mig.Name, time.Now().UTC()}
+	}
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Up aplica, em ordem, todas as migrações pendentes.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, conn, mig, true); err != nil {
+				return fmt.Errorf("falha ao aplicar migração %03d_%s: %w", mig.Version, mig.Name, err)
+			}
+			m.logger.Infof("Migração aplicada: %03d_%s", mig.Version, mig.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverte as últimas steps migrações aplicadas, da mais recente para a
+// mais antiga.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, conn, mig, false); err != nil {
+				return fmt.Errorf("falha ao reverter migração %03d_%s: %w", mig.Version, mig.Name, err)
+			}
+			m.logger.Infof("Migração revertida: %03d_%s", mig.Version, mig.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var status []Status
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		status = make([]Status, 0, len(m.migrations))
+		for _, mig := range m.migrations {
+			st := Status{Version: mig.Version, Name: mig.Name}
+			if at, ok := applied[mig.Version]; ok {
+				st.Applied = true
+				st.AppliedAt = &at
+			}
+			status = append(status, st)
+		}
+		return nil
+	})
+	return status, err
+}
@@ -0,0 +1,136 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"boot-whatsapp-golang/pkg/logger"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// userTables lista as tabelas criadas pelas migrações, fora a de controle e
+// as internas do SQLite.
+func userTables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'
+		ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestDriverParity(t *testing.T) {
+	pg, err := load(DriverPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lite, err := load(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pg) == 0 || len(pg) != len(lite) {
+		t.Fatalf("%d migrações para postgres, %d para sqlite3", len(pg), len(lite))
+	}
+	for i := range pg {
+		if pg[i].Version != i+1 {
+			t.Errorf("versão %03d_%s fora de sequência, esperado %03d", pg[i].Version, pg[i].Name, i+1)
+		}
+	}
+
+	for _, driver := range []string{DriverPostgres, DriverSQLite} {
+		if _, err := New(nil, driver, nil); err != nil {
+			t.Errorf("New(%s) = %v", driver, err)
+		}
+	}
+	if _, err := New(nil, "mysql", nil); err == nil {
+		t.Error("New(mysql) não recusou o driver")
+	}
+}
+
+// Sobe todas as migrações, desfaz todas e sobe de novo: uma migração sem a
+// variante sqlite3 ou com down incompleto falha aqui, não no deploy.
+func TestSQLiteRoundTrip(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "migrations.db") + "?_foreign_keys=on"
+	db, err := sql.Open(DriverSQLite, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	m, err := New(db, DriverSQLite, logger.New("test", logger.ERROR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := len(m.migrations)
+
+	n, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != total {
+		t.Fatalf("Up aplicou %d migrações, esperado %d", n, total)
+	}
+	tables := userTables(t, db)
+	if len(tables) == 0 {
+		t.Fatal("nenhuma tabela criada")
+	}
+
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("Up repetido = %d, %v; esperado 0", n, err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range status {
+		if !st.Applied || st.AppliedAt == nil {
+			t.Errorf("%03d_%s não consta como aplicada", st.Version, st.Name)
+		}
+	}
+
+	// Um passo de cada vez, para apontar qual down falhou.
+	for i := total - 1; i >= 0; i-- {
+		if n, err := m.Down(ctx, 1); err != nil || n != 1 {
+			mig := m.migrations[i]
+			t.Fatalf("Down de %03d_%s = %d, %v", mig.Version, mig.Name, n, err)
+		}
+	}
+	if left := userTables(t, db); len(left) != 0 {
+		t.Fatalf("tabelas restantes depois de reverter tudo: %v", left)
+	}
+	if n, err := m.Down(ctx, 1); err != nil || n != 0 {
+		t.Fatalf("Down sem migrações aplicadas = %d, %v; esperado 0", n, err)
+	}
+
+	n, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up depois de reverter tudo: %v", err)
+	}
+	if n != total {
+		t.Fatalf("Up aplicou %d migrações, esperado %d", n, total)
+	}
+	if again := userTables(t, db); !slices.Equal(again, tables) {
+		t.Errorf("tabelas depois do segundo Up = %v, esperado %v", again, tables)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS whatsapp_sessions;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -14,10 +14,10 @@ CREATE TABLE IF NOT EXISTS whatsapp_sessions (
     CONSTRAINT chk_status CHECK (status IN ('pending', 'connected', 'disconnected', 'error'))
 );
 
-CREATE INDEX idx_whatsapp_sessions_key ON whatsapp_sessions(whatsapp_session_key);
-CREATE INDEX idx_whatsapp_sessions_email ON whatsapp_sessions(email_pessoa);
-CREATE INDEX idx_whatsapp_sessions_status ON whatsapp_sessions(status);
-CREATE INDEX idx_whatsapp_sessions_created_at ON whatsapp_sessions(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_key ON whatsapp_sessions(whatsapp_session_key);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_email ON whatsapp_sessions(email_pessoa);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_status ON whatsapp_sessions(status);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_created_at ON whatsapp_sessions(created_at DESC);
 
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -27,6 +27,8 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS update_whatsapp_sessions_updated_at ON whatsapp_sessions;
+
 CREATE TRIGGER update_whatsapp_sessions_updated_at
     BEFORE UPDATE ON whatsapp_sessions
     FOR EACH ROW
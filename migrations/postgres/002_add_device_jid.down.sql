@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_whatsapp_sessions_device_jid;
+
+ALTER TABLE whatsapp_sessions DROP COLUMN IF EXISTS device_jid;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_whatsapp_sessions_tenant_id;
+
+ALTER TABLE whatsapp_sessions DROP COLUMN IF EXISTS tenant_id;
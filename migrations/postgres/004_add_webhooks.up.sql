@@ -0,0 +1,27 @@
+ALTER TABLE whatsapp_sessions ADD COLUMN IF NOT EXISTS webhook_url TEXT;
+ALTER TABLE whatsapp_sessions ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    last_status_code INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_session ON webhook_deliveries(session_id, status, created_at DESC);
+
+COMMENT ON TABLE webhook_deliveries IS 'Fila persistente de entregas de webhook por sessão';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending: aguardando envio/retry, delivered: entregue, dead: esgotou tentativas';
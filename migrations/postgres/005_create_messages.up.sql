@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id UUID PRIMARY KEY,
+    message_id VARCHAR(128) NOT NULL,
+    session_id UUID NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    direction VARCHAR(10) NOT NULL DEFAULT 'outbound',
+    recipient_jid VARCHAR(255) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    payload_summary TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'sent',
+    server_timestamp TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    read_at TIMESTAMP,
+    played_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_messages_session_message UNIQUE (session_id, message_id),
+    CONSTRAINT chk_messages_direction CHECK (direction IN ('outbound', 'inbound')),
+    CONSTRAINT chk_messages_status CHECK (status IN ('sent', 'delivered', 'read', 'played'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_message ON messages(tenant_id, message_id);
+CREATE INDEX IF NOT EXISTS idx_messages_session_created ON messages(session_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_recipient ON messages(session_id, recipient_jid);
+
+COMMENT ON TABLE messages IS 'Histórico de mensagens enviadas com rastreamento de entrega/leitura';
+COMMENT ON COLUMN messages.message_id IS 'ID da mensagem atribuído pelo WhatsApp';
+COMMENT ON COLUMN messages.status IS 'Status de entrega: sent, delivered, read, played';
@@ -0,0 +1,3 @@
+ALTER TABLE whatsapp_sessions DROP COLUMN IF EXISTS pairing_code_expires_at;
+ALTER TABLE whatsapp_sessions DROP COLUMN IF EXISTS pairing_code;
+ALTER TABLE whatsapp_sessions DROP COLUMN IF EXISTS pairing_phone;
@@ -0,0 +1,6 @@
+ALTER TABLE whatsapp_sessions ADD COLUMN IF NOT EXISTS pairing_phone VARCHAR(50);
+ALTER TABLE whatsapp_sessions ADD COLUMN IF NOT EXISTS pairing_code VARCHAR(16);
+ALTER TABLE whatsapp_sessions ADD COLUMN IF NOT EXISTS pairing_code_expires_at TIMESTAMP;
+
+COMMENT ON COLUMN whatsapp_sessions.pairing_phone IS 'Número informado no login por código de pareamento';
+COMMENT ON COLUMN whatsapp_sessions.pairing_code IS 'Código de 8 caracteres gerado pelo PairPhone, alternativo ao QR code';
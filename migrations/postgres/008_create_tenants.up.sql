@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_tenant_status CHECK (status IN ('active', 'disabled'))
+);
+
+CREATE TABLE IF NOT EXISTS tenant_api_keys (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    key_prefix VARCHAR(32) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_api_keys_tenant ON tenant_api_keys(tenant_id, created_at DESC);
+
+-- Cada valor de SESSIONKEY já usado como tenant vira um registro de tenant,
+-- pronto para receber chaves de API pelos endpoints de administração.
+INSERT INTO tenants (id, name, status, created_at, updated_at)
+SELECT DISTINCT tenant_id, tenant_id, 'active', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM whatsapp_sessions
+WHERE tenant_id IS NOT NULL AND tenant_id <> ''
+ON CONFLICT (id) DO NOTHING;
+
+COMMENT ON TABLE tenants IS 'Registro de tenants; o tenant de cada requisição é resolvido pela chave de API';
+COMMENT ON COLUMN tenant_api_keys.key_hash IS 'SHA-256 (hex) da chave; o valor em claro nunca é armazenado';
@@ -0,0 +1,6 @@
+-- Falha se duas sessões de tenants diferentes usarem a mesma chave.
+DROP INDEX IF EXISTS idx_whatsapp_sessions_tenant_session_key;
+
+ALTER TABLE whatsapp_sessions ALTER COLUMN tenant_id DROP NOT NULL;
+
+ALTER TABLE whatsapp_sessions ADD CONSTRAINT whatsapp_sessions_whatsapp_session_key_key UNIQUE (whatsapp_session_key);
@@ -0,0 +1,10 @@
+-- A chave da sessão passa a ser única por tenant: dois tenants podem usar o
+-- mesmo nome sem conflito.
+ALTER TABLE whatsapp_sessions DROP CONSTRAINT IF EXISTS whatsapp_sessions_whatsapp_session_key_key;
+
+ALTER TABLE whatsapp_sessions ALTER COLUMN tenant_id SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_whatsapp_sessions_tenant_session_key
+    ON whatsapp_sessions(tenant_id, whatsapp_session_key);
+
+COMMENT ON COLUMN whatsapp_sessions.whatsapp_session_key IS 'Chave de identificação da sessão, única dentro do tenant (ex: botwhat01)';
@@ -0,0 +1,3 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS sender_jid;
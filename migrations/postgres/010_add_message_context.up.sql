@@ -0,0 +1,6 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS sender_jid VARCHAR(255);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+COMMENT ON COLUMN messages.recipient_jid IS 'Chat da mensagem (contato ou grupo)';
+COMMENT ON COLUMN messages.sender_jid IS 'Autor da mensagem; necessário para citar, reagir e revogar em grupos';
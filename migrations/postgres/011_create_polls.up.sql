@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS polls (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(128) NOT NULL,
+    chat_jid VARCHAR(255) NOT NULL,
+    creator_jid VARCHAR(255) NOT NULL,
+    question TEXT NOT NULL,
+    options TEXT NOT NULL,
+    selectable_count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_polls_session_message UNIQUE (session_id, message_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    voter_jid VARCHAR(255) NOT NULL,
+    selected_options TEXT NOT NULL,
+    voted_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (poll_id, voter_jid)
+);
+
+COMMENT ON TABLE polls IS 'Enquetes enviadas ou recebidas pelas sessões, para apuração dos votos';
+COMMENT ON COLUMN polls.options IS 'Opções da enquete (array JSON); os votos chegam como SHA-256 de cada opção';
+COMMENT ON COLUMN polls.selectable_count IS 'Quantidade de opções selecionáveis; 0 = qualquer quantidade';
+COMMENT ON TABLE poll_votes IS 'Último voto descriptografado de cada participante; voto vazio = voto retirado';
@@ -0,0 +1,3 @@
+DELETE FROM outbound_jobs WHERE kind NOT IN ('text', 'media');
+ALTER TABLE outbound_jobs DROP CONSTRAINT IF EXISTS chk_outbound_job_kind;
+ALTER TABLE outbound_jobs ADD CONSTRAINT chk_outbound_job_kind CHECK (kind IN ('text', 'media'));
@@ -0,0 +1,3 @@
+ALTER TABLE outbound_jobs DROP CONSTRAINT IF EXISTS chk_outbound_job_kind;
+ALTER TABLE outbound_jobs ADD CONSTRAINT chk_outbound_job_kind
+    CHECK (kind IN ('text', 'media', 'location', 'contacts', 'poll'));
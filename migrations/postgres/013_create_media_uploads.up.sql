@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS media_uploads (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    media_type VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    direct_path TEXT NOT NULL,
+    media_key BYTEA NOT NULL,
+    file_enc_sha256 BYTEA NOT NULL,
+    file_length BIGINT NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT '',
+    mime_type VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+
+    CONSTRAINT uq_media_uploads_content UNIQUE (tenant_id, sha256, media_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_uploads_expires ON media_uploads(tenant_id, expires_at);
+
+COMMENT ON TABLE media_uploads IS 'Uploads de mídia reutilizáveis, endereçados pelo SHA-256 do conteúdo original';
+COMMENT ON COLUMN media_uploads.id IS 'media_id devolvido ao pré-upload e aceito nos envios';
+COMMENT ON COLUMN media_uploads.expires_at IS 'Fim da retenção da mídia nos servidores do WhatsApp; depois disso é preciso novo upload';
+COMMENT ON COLUMN media_uploads.metadata IS 'Miniatura, dimensões, duração, páginas e forma de onda (JSON) para montar a mensagem sem o arquivo';
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    session_id UUID NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    session_key VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    window_start VARCHAR(5) NOT NULL DEFAULT '',
+    window_end VARCHAR(5) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    per_minute INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT chk_campaign_status CHECK (status IN ('draft', 'running', 'paused', 'completed', 'canceled')),
+    CONSTRAINT chk_campaign_kind CHECK (kind IN ('text', 'media'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_tenant ON campaigns(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    number VARCHAR(255) NOT NULL,
+    variables TEXT NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    message_id VARCHAR(128),
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (campaign_id, position),
+    CONSTRAINT chk_campaign_recipient_status CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'canceled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_queue ON campaign_recipients(campaign_id, status, next_attempt_at);
+
+COMMENT ON TABLE campaigns IS 'Envios em massa de uma mensagem com variáveis para uma lista de destinatários';
+COMMENT ON COLUMN campaigns.message IS 'Mensagem da campanha (JSON): texto ou legenda com {{variaveis}} e a mídia, se houver';
+COMMENT ON COLUMN campaigns.window_start IS 'Horário diário (HH:MM, no fuso timezone) a partir do qual a campanha envia; vazio = o dia todo';
+COMMENT ON COLUMN campaigns.per_minute IS 'Ritmo máximo da campanha; 0 = só os limites da sessão';
+COMMENT ON TABLE campaign_recipients IS 'Destinatários da campanha, na ordem do upload, com o resultado de cada envio';
+COMMENT ON COLUMN campaign_recipients.status IS 'queued: aguardando, processing: em envio, sent: enviado (entrega/leitura vêm de messages), failed, canceled';
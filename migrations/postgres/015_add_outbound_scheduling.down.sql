@@ -0,0 +1,10 @@
+DELETE FROM outbound_jobs WHERE status = 'canceled';
+DROP INDEX IF EXISTS idx_outbound_jobs_scheduled;
+
+ALTER TABLE outbound_jobs DROP CONSTRAINT IF EXISTS chk_outbound_job_status;
+ALTER TABLE outbound_jobs ADD CONSTRAINT chk_outbound_job_status
+    CHECK (status IN ('queued', 'processing', 'sent', 'failed'));
+
+ALTER TABLE outbound_jobs DROP COLUMN IF EXISTS scheduled_at;
+
+COMMENT ON COLUMN outbound_jobs.status IS 'queued: aguardando envio/retry, processing: em envio, sent: enviado, failed: falha definitiva';
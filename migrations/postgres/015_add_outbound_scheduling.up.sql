@@ -0,0 +1,11 @@
+ALTER TABLE outbound_jobs ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+
+ALTER TABLE outbound_jobs DROP CONSTRAINT IF EXISTS chk_outbound_job_status;
+ALTER TABLE outbound_jobs ADD CONSTRAINT chk_outbound_job_status
+    CHECK (status IN ('queued', 'processing', 'sent', 'failed', 'canceled'));
+
+CREATE INDEX IF NOT EXISTS idx_outbound_jobs_scheduled ON outbound_jobs(session_id, status, scheduled_at)
+    WHERE scheduled_at IS NOT NULL;
+
+COMMENT ON COLUMN outbound_jobs.scheduled_at IS 'Horário agendado pelo send_at; NULL para envios imediatos';
+COMMENT ON COLUMN outbound_jobs.status IS 'queued: aguardando envio/retry, processing: em envio, sent: enviado, failed: falha definitiva, canceled: agendamento cancelado';
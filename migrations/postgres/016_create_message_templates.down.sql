@@ -0,0 +1,4 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS template_version;
+ALTER TABLE messages DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS message_template_versions;
+DROP TABLE IF EXISTS message_templates;
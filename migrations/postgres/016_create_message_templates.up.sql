@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS message_templates (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    language VARCHAR(16) NOT NULL,
+    current_version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_message_templates_name UNIQUE (tenant_id, name, language)
+);
+
+CREATE TABLE IF NOT EXISTS message_template_versions (
+    template_id UUID NOT NULL REFERENCES message_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    media TEXT,
+    variables TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, version)
+);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS template_id UUID;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS template_version INTEGER;
+
+COMMENT ON TABLE message_templates IS 'Templates de mensagem do tenant; o par nome + idioma identifica o template';
+COMMENT ON TABLE message_template_versions IS 'Versões imutáveis do template; cada alteração grava uma nova versão';
+COMMENT ON COLUMN message_template_versions.media IS 'Mídia do template (JSON); o corpo vira a legenda. NULL para texto';
+COMMENT ON COLUMN messages.template_version IS 'Versão do template usada no envio, se a mensagem veio de um template';
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS update_whatsapp_sessions_updated_at;
+
+DROP TABLE IF EXISTS whatsapp_sessions;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS whatsapp_sessions (
+    id TEXT PRIMARY KEY,
+    whatsapp_session_key VARCHAR(255) NOT NULL,
+    nome_pessoa VARCHAR(255) NOT NULL,
+    email_pessoa VARCHAR(255) UNIQUE NOT NULL,
+    phone_number VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    qr_code TEXT,
+    qr_code_expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_connected_at TIMESTAMP,
+
+    CONSTRAINT chk_status CHECK (status IN ('pending', 'connected', 'disconnected', 'error'))
+);
+
+-- Índice nomeado como a constraint do Postgres: o SQLite não remove
+-- constraints de coluna, e a 009 precisa trocar esta unicidade.
+CREATE UNIQUE INDEX IF NOT EXISTS whatsapp_sessions_whatsapp_session_key_key ON whatsapp_sessions(whatsapp_session_key);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_key ON whatsapp_sessions(whatsapp_session_key);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_email ON whatsapp_sessions(email_pessoa);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_status ON whatsapp_sessions(status);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_sessions_created_at ON whatsapp_sessions(created_at DESC);
+
+CREATE TRIGGER IF NOT EXISTS update_whatsapp_sessions_updated_at
+    AFTER UPDATE ON whatsapp_sessions
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE whatsapp_sessions SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+ALTER TABLE whatsapp_sessions DROP COLUMN webhook_secret;
+ALTER TABLE whatsapp_sessions DROP COLUMN webhook_url;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS outbound_jobs (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    session_key VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    message_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT chk_outbound_job_status CHECK (status IN ('queued', 'processing', 'sent', 'failed')),
+    CONSTRAINT chk_outbound_job_kind CHECK (kind IN ('text', 'media'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbound_jobs_session_queue ON outbound_jobs(session_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_outbound_jobs_tenant ON outbound_jobs(tenant_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE whatsapp_sessions ADD COLUMN pairing_phone VARCHAR(50);
+ALTER TABLE whatsapp_sessions ADD COLUMN pairing_code VARCHAR(16);
+ALTER TABLE whatsapp_sessions ADD COLUMN pairing_code_expires_at TIMESTAMP;
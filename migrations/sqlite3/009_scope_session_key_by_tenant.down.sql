@@ -0,0 +1,4 @@
+-- Falha se duas sessões de tenants diferentes usarem a mesma chave.
+DROP INDEX IF EXISTS idx_whatsapp_sessions_tenant_session_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS whatsapp_sessions_whatsapp_session_key_key ON whatsapp_sessions(whatsapp_session_key);
@@ -0,0 +1,6 @@
+-- O SQLite não altera NOT NULL de colunas existentes; o tenant_id já é
+-- sempre preenchido pela aplicação e pela 003.
+DROP INDEX IF EXISTS whatsapp_sessions_whatsapp_session_key_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_whatsapp_sessions_tenant_session_key
+    ON whatsapp_sessions(tenant_id, whatsapp_session_key);
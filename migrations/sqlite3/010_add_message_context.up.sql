@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN sender_jid VARCHAR(255);
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN revoked_at TIMESTAMP;
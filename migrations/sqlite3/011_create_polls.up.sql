@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS polls (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(128) NOT NULL,
+    chat_jid VARCHAR(255) NOT NULL,
+    creator_jid VARCHAR(255) NOT NULL,
+    question TEXT NOT NULL,
+    options TEXT NOT NULL,
+    selectable_count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_polls_session_message UNIQUE (session_id, message_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id TEXT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    voter_jid VARCHAR(255) NOT NULL,
+    selected_options TEXT NOT NULL,
+    voted_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (poll_id, voter_jid)
+);
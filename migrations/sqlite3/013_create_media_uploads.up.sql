@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS media_uploads (
+    id TEXT PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    media_type VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    direct_path TEXT NOT NULL,
+    media_key BLOB NOT NULL,
+    file_enc_sha256 BLOB NOT NULL,
+    file_length INTEGER NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT '',
+    mime_type VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+
+    CONSTRAINT uq_media_uploads_content UNIQUE (tenant_id, sha256, media_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_uploads_expires ON media_uploads(tenant_id, expires_at);
@@ -0,0 +1,37 @@
+-- SQLite não altera CHECK constraints; a tabela é recriada.
+CREATE TABLE outbound_jobs_new (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES whatsapp_sessions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    session_key VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    message_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+
+    CONSTRAINT chk_outbound_job_status CHECK (status IN ('queued', 'processing', 'sent', 'failed')),
+    CONSTRAINT chk_outbound_job_kind CHECK (kind IN ('text', 'media', 'location', 'contacts', 'poll'))
+);
+
+INSERT INTO outbound_jobs_new (
+    id, session_id, tenant_id, session_key, kind, recipient, payload, status, attempts,
+    next_attempt_at, last_error, message_id, created_at, updated_at, completed_at
+)
+SELECT
+    id, session_id, tenant_id, session_key, kind, recipient, payload, status, attempts,
+    next_attempt_at, last_error, message_id, created_at, updated_at, completed_at
+FROM outbound_jobs
+WHERE status <> 'canceled';
+
+DROP TABLE outbound_jobs;
+ALTER TABLE outbound_jobs_new RENAME TO outbound_jobs;
+
+CREATE INDEX IF NOT EXISTS idx_outbound_jobs_session_queue ON outbound_jobs(session_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_outbound_jobs_tenant ON outbound_jobs(tenant_id, created_at DESC);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS message_templates (
+    id TEXT PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    language VARCHAR(16) NOT NULL,
+    current_version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_message_templates_name UNIQUE (tenant_id, name, language)
+);
+
+CREATE TABLE IF NOT EXISTS message_template_versions (
+    template_id TEXT NOT NULL REFERENCES message_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    media TEXT,
+    variables TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, version)
+);
+
+ALTER TABLE messages ADD COLUMN template_id TEXT;
+ALTER TABLE messages ADD COLUMN template_version INTEGER;
@@ -0,0 +1,176 @@
+// Package fetcher monta clientes HTTP para buscar URLs informadas por
+// clientes da API sem expor a rede interna do servidor (SSRF).
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrBlocked           = errors.New("destino bloqueado pela política de download")
+	ErrTooManyRedirects  = errors.New("redirecionamentos demais")
+	ErrSchemeNotAllowed  = fmt.Errorf("%w: esquema não permitido", ErrBlocked)
+	ErrHostNotAllowed    = fmt.Errorf("%w: host não permitido", ErrBlocked)
+	ErrAddressNotAllowed = fmt.Errorf("%w: endereço de rede interna", ErrBlocked)
+)
+
+// Policy define o que pode ser buscado. Hosts aceitam o nome exato ou
+// "*.dominio", que casa com qualquer subdomínio (mas não com o próprio
+// domínio). Lista de permitidos vazia libera qualquer host não negado.
+type Policy struct {
+	AllowedSchemes []string
+	AllowedHosts   []string
+	DeniedHosts    []string
+	// AllowPrivate libera loopback, redes privadas, link-local e afins.
+	// Só para desenvolvimento ou redes confiáveis.
+	AllowPrivate bool
+	MaxRedirects int
+}
+
+// Faixas bloqueadas além das que netip.Addr já identifica (loopback,
+// privadas, link-local, multicast e não especificado).
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "esta rede"
+	netip.MustParsePrefix("100.64.0.0/10"),  // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // atribuições de protocolo IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // testes de desempenho
+	netip.MustParsePrefix("240.0.0.0/4"),    // reservado, inclui broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, embute um IPv4 qualquer
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 local
+	netip.MustParsePrefix("2002::/16"),      // 6to4, idem
+	netip.MustParsePrefix("fec0::/10"),      // site-local (obsoleto)
+}
+
+// IsPublic informa se o endereço é roteável na internet pública.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClient devolve um cliente que aplica a política a cada requisição,
+// inclusive às de redirecionamento. O endereço é conferido no momento da
+// conexão, depois da resolução DNS, para que um nome que resolva para a
+// rede interna (ou mude de resolução entre a checagem e a conexão) não
+// escape do bloqueio. Proxies de ambiente não são usados: através deles o
+// endereço conferido seria o do proxy.
+func NewClient(policy Policy, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !policy.AllowPrivate {
+		dialer.Control = checkAddress
+	}
+
+	tr := &http.Transport{
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          256,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: &policyTransport{policy: policy, next: tr},
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > policy.MaxRedirects {
+				return fmt.Errorf("%w: limite de %d", ErrTooManyRedirects, policy.MaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+	}
+	if !IsPublic(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, ap.Addr())
+	}
+	return nil
+}
+
+// policyTransport confere esquema e host antes de qualquer conexão. Como o
+// http.Client passa cada redirecionamento pelo Transport, a checagem vale
+// para todos os saltos.
+type policyTransport struct {
+	policy Policy
+	next   http.RoundTripper
+}
+
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.Check(req.URL.Scheme, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// Check aplica esquema e listas de hosts. A resolução DNS fica para a
+// conexão.
+func (p Policy) Check(scheme, host string) error {
+	if len(p.AllowedSchemes) > 0 && !containsFold(p.AllowedSchemes, scheme) {
+		return fmt.Errorf("%w: %s", ErrSchemeNotAllowed, scheme)
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return fmt.Errorf("%w: host vazio", ErrHostNotAllowed)
+	}
+	if matchHost(p.DeniedHosts, host) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchHost(p.AllowedHosts, host) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+	// Sem DNS não há o que esperar da conexão; o literal já diz tudo.
+	if addr, err := netip.ParseAddr(host); err == nil && !p.AllowPrivate && !IsPublic(addr) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+	}
+	return nil
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.ToLower(p), ".")
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
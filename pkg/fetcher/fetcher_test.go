@@ -0,0 +1,203 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"1.1.1.1", true},
+		{"2001:4860:4860::8888", true},
+		{"::ffff:8.8.8.8", true},
+
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.127.255.255", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"fec0::1", false},
+		{"ff02::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::7f00:1", false},
+		{"64:ff9b::808:808", false},
+		{"64:ff9b:1::1", false},
+		{"2002:7f00:1::1", false},
+		{"2002:808:808::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPublic(%s) = %v, esperado %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+	if IsPublic(netip.Addr{}) {
+		t.Error("IsPublic(endereço zero) = true")
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := Policy{
+		AllowedSchemes: []string{"https"},
+		AllowedHosts:   []string{"*.example.com", "cdn.example.org"},
+		DeniedHosts:    []string{"evil.example.com"},
+	}
+	tests := []struct {
+		name   string
+		policy Policy
+		scheme string
+		host   string
+		want   error
+	}{
+		{"subdomínio permitido", policy, "https", "img.example.com", nil},
+		{"subdomínio profundo", policy, "https", "a.b.example.com", nil},
+		{"maiúsculas e ponto final", policy, "HTTPS", "IMG.Example.COM.", nil},
+		{"host exato", policy, "https", "cdn.example.org", nil},
+		{"curinga não casa com o domínio", policy, "https", "example.com", ErrHostNotAllowed},
+		{"sufixo sem ponto não casa", policy, "https", "badexample.com", ErrHostNotAllowed},
+		{"host exato não casa com subdomínio", policy, "https", "x.cdn.example.org", ErrHostNotAllowed},
+		{"negado vence permitido", policy, "https", "evil.example.com", ErrHostNotAllowed},
+		{"fora da lista", policy, "https", "example.net", ErrHostNotAllowed},
+		{"esquema", policy, "http", "img.example.com", ErrSchemeNotAllowed},
+		{"host vazio", policy, "https", "", ErrHostNotAllowed},
+
+		{"sem listas", Policy{}, "https", "example.net", nil},
+		{"negado por curinga", Policy{DeniedHosts: []string{"*.internal"}}, "http", "db.internal", ErrHostNotAllowed},
+		{"IPv4 literal privado", Policy{}, "http", "10.0.0.1", ErrAddressNotAllowed},
+		{"IPv6 literal loopback", Policy{}, "http", "::1", ErrAddressNotAllowed},
+		{"IPv4 mapeado", Policy{}, "http", "::ffff:127.0.0.1", ErrAddressNotAllowed},
+		{"literal público", Policy{}, "http", "8.8.8.8", nil},
+		{"AllowPrivate libera literal", Policy{AllowPrivate: true}, "http", "127.0.0.1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.scheme, tt.host)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Check(%q, %q) = %v", tt.scheme, tt.host, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) || !errors.Is(err, ErrBlocked) {
+				t.Fatalf("Check(%q, %q) = %v, esperado %v", tt.scheme, tt.host, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		ok      bool
+	}{
+		{"8.8.8.8:443", true},
+		{"[2001:4860:4860::8888]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"[::ffff:10.0.0.1]:80", false},
+		{"[64:ff9b::a00:1]:80", false},
+		{"[2002:a00:1::1]:80", false},
+		{"sem-porta", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkAddress("tcp", tt.address, nil)
+			if tt.ok != (err == nil) {
+				t.Fatalf("checkAddress(%s) = %v", tt.address, err)
+			}
+			if err != nil && !errors.Is(err, ErrAddressNotAllowed) {
+				t.Fatalf("checkAddress(%s) = %v, esperado ErrAddressNotAllowed", tt.address, err)
+			}
+		})
+	}
+}
+
+// Um nome que resolve para loopback passa pelo Check (não é literal) e tem
+// de ser barrado na conexão.
+func TestClientRefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("requisição chegou ao servidor de loopback")
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(Policy{}, 5*time.Second)
+
+	for _, host := range []string{u.Host, "localhost:" + u.Port()} {
+		resp, err := client.Get("http://" + host + "/")
+		if err == nil {
+			resp.Body.Close()
+			t.Fatalf("GET %s não foi bloqueado", host)
+		}
+		if !errors.Is(err, ErrAddressNotAllowed) {
+			t.Fatalf("GET %s = %v, esperado ErrAddressNotAllowed", host, err)
+		}
+	}
+}
+
+func TestClientRedirects(t *testing.T) {
+	hops := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ftp":
+			http.Redirect(w, r, "ftp://files.example.com/x", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/loop"):
+			hops++
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	// O servidor de teste está em loopback; AllowPrivate isola a checagem
+	// de redirecionamentos da de endereço.
+	client := NewClient(Policy{AllowedSchemes: []string{"http"}, AllowPrivate: true, MaxRedirects: 2}, 5*time.Second)
+
+	_, err := client.Get(srv.URL + "/loop")
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("GET /loop = %v, esperado ErrTooManyRedirects", err)
+	}
+	if hops != 3 {
+		t.Errorf("servidor recebeu %d requisições, esperado 3 (original + 2 redirecionamentos)", hops)
+	}
+
+	_, err = client.Get(srv.URL + "/ftp")
+	if !errors.Is(err, ErrSchemeNotAllowed) {
+		t.Fatalf("GET /ftp = %v, esperado ErrSchemeNotAllowed", err)
+	}
+
+	resp, err := client.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / = %v", err)
+	}
+	resp.Body.Close()
+}
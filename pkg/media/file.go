@@ -0,0 +1,85 @@
+package media
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+var ErrTooLarge = errors.New("mídia excede o tamanho máximo")
+
+// File é uma mídia guardada em arquivo temporário, para que uploads e
+// downloads grandes não fiquem inteiros em memória. Quem recebe o File é
+// responsável por fechá-lo, o que também apaga o arquivo.
+type File struct {
+	f    *os.File
+	Size int64
+}
+
+// Spool copia r para um arquivo temporário. Conteúdo acima de limit bytes
+// (quando limit > 0) descarta o arquivo e devolve ErrTooLarge.
+func Spool(r io.Reader, limit int64) (*File, error) {
+	f, err := os.CreateTemp("", "whatsapp-media-*")
+	if err != nil {
+		return nil, err
+	}
+	file := &File{f: f}
+
+	src := r
+	if limit > 0 {
+		src = io.LimitReader(r, limit+1)
+	}
+	n, err := io.Copy(f, src)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if limit > 0 && n > limit {
+		file.Close()
+		return nil, ErrTooLarge
+	}
+	file.Size = n
+	return file, nil
+}
+
+// Reader devolve um leitor independente desde o início do arquivo; vários
+// podem ser usados em sequência sem reposicionar nada.
+func (m *File) Reader() *io.SectionReader {
+	return io.NewSectionReader(m.f, 0, m.Size)
+}
+
+// Bytes lê o arquivo inteiro. Só para mídias pequenas por natureza.
+func (m *File) Bytes() ([]byte, error) {
+	return io.ReadAll(m.Reader())
+}
+
+// SHA256 calcula o hash do conteúdo, o mesmo que o WhatsApp usa como
+// FileSHA256.
+func (m *File) SHA256() ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, m.Reader()); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// DetectContentType aplica http.DetectContentType ao início do arquivo.
+func (m *File) DetectContentType() string {
+	head := make([]byte, 512)
+	n, _ := m.f.ReadAt(head, 0)
+	return http.DetectContentType(head[:n])
+}
+
+func (m *File) Close() error {
+	if m == nil || m.f == nil {
+		return nil
+	}
+	err := m.f.Close()
+	if rmErr := os.Remove(m.f.Name()); err == nil && !errors.Is(rmErr, os.ErrNotExist) {
+		err = rmErr
+	}
+	m.f = nil
+	return err
+}
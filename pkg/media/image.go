@@ -0,0 +1,92 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+
+	// Decodificadores registrados para image.Decode.
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ThumbnailMaxSide é o maior lado da miniatura embutida na mensagem. O
+// WhatsApp exibe a miniatura borrada enquanto baixa a mídia, então pouca
+// resolução basta e mantém o protobuf pequeno.
+const ThumbnailMaxSide = 100
+
+const thumbnailQuality = 60
+
+// maxThumbnailPixels evita decodificar imagens enormes só para a
+// miniatura (cada pixel ocupa 4 bytes na memória).
+const maxThumbnailPixels = 40_000_000
+
+var ErrUnsupportedImage = errors.New("imagem em formato não suportado")
+
+// Thumbnail é uma miniatura JPEG pronta para JPEGThumbnail.
+type Thumbnail struct {
+	JPEG   []byte `json:"jpeg"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageSize lê só o cabeçalho da imagem (JPEG, PNG, GIF ou WebP).
+func ImageSize(r io.Reader) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// MakeThumbnail decodifica a imagem e a reduz para caber em
+// ThumbnailMaxSide, preservando a proporção. Imagens menores não são
+// ampliadas.
+func MakeThumbnail(r io.ReadSeeker) (*Thumbnail, error) {
+	width, height, err := ImageSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if width*height > maxThumbnailPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels excede o limite para miniatura", ErrUnsupportedImage, width, height)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("%w: imagem vazia", ErrUnsupportedImage)
+	}
+	if w > ThumbnailMaxSide || h > ThumbnailMaxSide {
+		if w >= h {
+			w, h = ThumbnailMaxSide, max(1, h*ThumbnailMaxSide/b.Dx())
+		} else {
+			w, h = max(1, w*ThumbnailMaxSide/b.Dy()), ThumbnailMaxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	// JPEG não tem transparência: o fundo branco evita áreas pretas em
+	// PNG/WebP com alfa.
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("falha ao gerar miniatura: %w", err)
+	}
+	return &Thumbnail{JPEG: buf.Bytes(), Width: w, Height: h}, nil
+}
@@ -0,0 +1,150 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNotMP4 = errors.New("arquivo não é um contêiner MP4")
+
+// VideoInfo resume os metadados do contêiner MP4/MOV.
+type VideoInfo struct {
+	Seconds uint32
+	Width   int
+	Height  int
+}
+
+// maxMoovSize limita o átomo moov lido para memória; ele guarda só os
+// índices das amostras e raramente passa de alguns MB.
+const maxMoovSize = 32 << 20
+
+// ParseMP4 lê duração (moov/mvhd) e dimensões da primeira trilha de vídeo
+// (trak/tkhd com handler "vide") sem decodificar nenhum quadro. Só os
+// cabeçalhos de primeiro nível e o átomo moov são lidos, esteja ele no
+// início ou no fim do arquivo.
+func ParseMP4(r io.ReaderAt, size int64) (*VideoInfo, error) {
+	header := make([]byte, 16)
+	if _, err := r.ReadAt(header[:8], 0); err != nil || string(header[4:8]) != "ftyp" {
+		return nil, ErrNotMP4
+	}
+
+	var moov []byte
+	for off := int64(0); off+8 <= size; {
+		if _, err := r.ReadAt(header[:8], off); err != nil {
+			return nil, ErrNotMP4
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		hdrLen := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - off
+		case 1:
+			if _, err := r.ReadAt(header[8:16], off+8); err != nil {
+				return nil, ErrNotMP4
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			hdrLen = 16
+		}
+		if boxSize < hdrLen || off+boxSize > size {
+			return nil, ErrNotMP4
+		}
+		if string(header[4:8]) == "moov" {
+			if boxSize-hdrLen > maxMoovSize {
+				return nil, fmt.Errorf("%w: átomo moov muito grande", ErrNotMP4)
+			}
+			moov = make([]byte, boxSize-hdrLen)
+			if _, err := r.ReadAt(moov, off+hdrLen); err != nil {
+				return nil, ErrNotMP4
+			}
+			break
+		}
+		off += boxSize
+	}
+	if moov == nil {
+		return nil, ErrNotMP4
+	}
+
+	info := &VideoInfo{}
+	if mvhd, ok := findBox(moov, "mvhd"); ok && len(mvhd) >= 4 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 && len(mvhd) >= 32 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+			duration = binary.BigEndian.Uint64(mvhd[24:32])
+		} else if len(mvhd) >= 20 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+		}
+		if timescale > 0 {
+			info.Seconds = uint32((duration + timescale/2) / timescale)
+		}
+	}
+
+	eachBox(moov, func(typ string, trak []byte) bool {
+		if typ != "trak" {
+			return true
+		}
+		mdia, _ := findBox(trak, "mdia")
+		hdlr, ok := findBox(mdia, "hdlr")
+		if !ok || len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+			return true
+		}
+		tkhd, ok := findBox(trak, "tkhd")
+		if !ok || len(tkhd) < 4 {
+			return true
+		}
+		// Largura e altura são ponto fixo 16.16 no fim do tkhd.
+		off := 76
+		if tkhd[0] == 1 {
+			off = 88
+		}
+		if len(tkhd) >= off+8 {
+			info.Width = int(binary.BigEndian.Uint32(tkhd[off:off+4]) >> 16)
+			info.Height = int(binary.BigEndian.Uint32(tkhd[off+4:off+8]) >> 16)
+		}
+		return false
+	})
+	return info, nil
+}
+
+// findBox devolve o conteúdo do primeiro átomo do tipo pedido no nível atual.
+func findBox(data []byte, want string) ([]byte, bool) {
+	var found []byte
+	ok := false
+	eachBox(data, func(typ string, body []byte) bool {
+		if typ == want {
+			found, ok = body, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// eachBox percorre os átomos de um nível até fn devolver false ou o
+// conteúdo acabar. Átomos truncados encerram a leitura.
+func eachBox(data []byte, fn func(typ string, body []byte) bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return
+		}
+		if !fn(typ, data[header:size]) {
+			return
+		}
+		data = data[size:]
+	}
+}
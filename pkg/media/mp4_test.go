@@ -0,0 +1,137 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func box(typ string, children ...[]byte) []byte {
+	out := make([]byte, 8)
+	copy(out[4:], typ)
+	for _, c := range children {
+		out = append(out, c...)
+	}
+	binary.BigEndian.PutUint32(out[0:4], uint32(len(out)))
+	return out
+}
+
+func mvhd(timescale, duration uint32) []byte {
+	body := make([]byte, 100)
+	binary.BigEndian.PutUint32(body[12:16], timescale)
+	binary.BigEndian.PutUint32(body[16:20], duration)
+	return box("mvhd", body)
+}
+
+func trak(handler string, width, height uint32) []byte {
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:80], width<<16)
+	binary.BigEndian.PutUint32(tkhd[80:84], height<<16)
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:12], handler)
+	return box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr)))
+}
+
+func mp4File(boxes ...[]byte) []byte {
+	out := box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	for _, b := range boxes {
+		out = append(out, b...)
+	}
+	return out
+}
+
+func parseMP4Bytes(data []byte) (*VideoInfo, error) {
+	return ParseMP4(bytes.NewReader(data), int64(len(data)))
+}
+
+func TestParseMP4(t *testing.T) {
+	moov := box("moov", mvhd(1000, 12400), trak("soun", 0, 0), trak("vide", 720, 1280))
+	mdat := box("mdat", make([]byte, 64))
+
+	tests := []struct {
+		name string
+		data []byte
+		want VideoInfo
+	}{
+		{"moov no início", mp4File(moov, mdat), VideoInfo{Seconds: 12, Width: 720, Height: 1280}},
+		{"moov no fim", mp4File(mdat, moov), VideoInfo{Seconds: 12, Width: 720, Height: 1280}},
+		{"sem trilha de vídeo", mp4File(box("moov", mvhd(600, 1500), trak("soun", 0, 0))), VideoInfo{Seconds: 3}},
+		{"timescale zero", mp4File(box("moov", mvhd(0, 1500), trak("vide", 640, 360))), VideoInfo{Width: 640, Height: 360}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseMP4Bytes(tt.data)
+			if err != nil {
+				t.Fatalf("ParseMP4: %v", err)
+			}
+			if *info != tt.want {
+				t.Errorf("ParseMP4 = %+v, esperado %+v", *info, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMP4LargeSizeBox(t *testing.T) {
+	// mdat com tamanho de 64 bits (size == 1) antes do moov.
+	mdat := make([]byte, 16, 48)
+	binary.BigEndian.PutUint32(mdat[0:4], 1)
+	copy(mdat[4:8], "mdat")
+	mdat = append(mdat, make([]byte, 32)...)
+	binary.BigEndian.PutUint64(mdat[8:16], uint64(len(mdat)))
+
+	info, err := parseMP4Bytes(mp4File(mdat, box("moov", mvhd(1, 7), trak("vide", 2, 2))))
+	if err != nil {
+		t.Fatalf("ParseMP4: %v", err)
+	}
+	if info.Seconds != 7 || info.Width != 2 {
+		t.Errorf("ParseMP4 = %+v", *info)
+	}
+}
+
+func TestParseMP4Errors(t *testing.T) {
+	valid := mp4File(box("moov", mvhd(1000, 1000), trak("vide", 10, 10)))
+	overflow := mp4File(box("free"))
+	binary.BigEndian.PutUint32(overflow[len(overflow)-8:], 0xffffffff)
+	tiny := mp4File(box("free"))
+	binary.BigEndian.PutUint32(tiny[len(tiny)-8:], 4)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"vazio", nil},
+		{"sem ftyp", box("moov", mvhd(1, 1))},
+		{"sem moov", mp4File(box("mdat", make([]byte, 16)))},
+		{"moov truncado", valid[:len(valid)-10]},
+		{"átomo maior que o arquivo", overflow},
+		{"átomo menor que o cabeçalho", tiny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseMP4Bytes(tt.data); !errors.Is(err, ErrNotMP4) {
+				t.Errorf("erro = %v, esperado ErrNotMP4", err)
+			}
+		})
+	}
+}
+
+func FuzzParseMP4(f *testing.F) {
+	f.Add(mp4File(box("moov", mvhd(1000, 12400), trak("vide", 720, 1280))))
+	f.Add(mp4File(box("mdat", make([]byte, 8)), box("moov", mvhd(1, 1))))
+	f.Add(mp4File(box("moov", box("trak", box("mdia")))))
+	f.Add([]byte("\x00\x00\x00\x00ftyp"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		info, err := parseMP4Bytes(data)
+		if err != nil {
+			if !errors.Is(err, ErrNotMP4) {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			return
+		}
+		if info.Width < 0 || info.Height < 0 {
+			t.Fatalf("dimensões negativas: %+v", info)
+		}
+	})
+}
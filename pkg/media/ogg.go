@@ -0,0 +1,164 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Ogg/Opus sempre usa granule position em 48 kHz, independente da taxa
+// original informada no cabeçalho.
+const opusGranuleRate = 48000
+
+// WaveformSamples é o tamanho da forma de onda que o WhatsApp exibe nas
+// mensagens de voz.
+const WaveformSamples = 64
+
+var (
+	ErrNotOgg  = errors.New("arquivo não é um contêiner Ogg")
+	ErrNotOpus = errors.New("stream Ogg não contém áudio Opus")
+)
+
+// OpusInfo resume um arquivo Ogg/Opus.
+type OpusInfo struct {
+	Channels int
+	Seconds  uint32
+	// Waveform tem WaveformSamples valores entre 0 e 100.
+	Waveform []byte
+}
+
+// ParseOggOpus lê as páginas Ogg do primeiro stream lógico, valida o
+// cabeçalho OpusHead e calcula duração e forma de onda. A forma de onda é
+// estimada pelo tamanho dos pacotes: em VBR, trechos mais altos geram
+// pacotes maiores, o que basta para a visualização do app. A leitura é
+// sequencial e só guarda o tamanho de cada pacote.
+func ParseOggOpus(r io.Reader) (*OpusInfo, error) {
+	var (
+		br          = bufio.NewReader(r)
+		hdr         = make([]byte, 27)
+		lacing      = make([]byte, 255)
+		serial      uint32
+		first       = true
+		packet      int
+		pending     int
+		preSkip     int64
+		lastGranule = int64(-1)
+		info        = &OpusInfo{}
+		sizes       []int
+	)
+
+	for {
+		if _, err := io.ReadFull(br, hdr); err != nil {
+			if errors.Is(err, io.EOF) && !first {
+				break
+			}
+			if first {
+				return nil, ErrNotOgg
+			}
+			return nil, fmt.Errorf("%w: página truncada", ErrNotOgg)
+		}
+		if !bytes.Equal(hdr[:4], []byte("OggS")) {
+			return nil, fmt.Errorf("%w: página sem assinatura OggS", ErrNotOgg)
+		}
+		granule := int64(binary.LittleEndian.Uint64(hdr[6:14]))
+		pageSerial := binary.LittleEndian.Uint32(hdr[14:18])
+		segs := lacing[:hdr[26]]
+		if _, err := io.ReadFull(br, segs); err != nil {
+			return nil, fmt.Errorf("%w: tabela de segmentos truncada", ErrNotOgg)
+		}
+
+		if first {
+			serial = pageSerial
+		}
+		first = false
+		if pageSerial != serial {
+			// Streams multiplexados: só o primeiro interessa.
+			total := 0
+			for _, l := range segs {
+				total += int(l)
+			}
+			if _, err := br.Discard(total); err != nil {
+				return nil, fmt.Errorf("%w: segmento truncado", ErrNotOgg)
+			}
+			continue
+		}
+
+		for _, l := range segs {
+			if packet == 0 && pending == 0 {
+				head := make([]byte, l)
+				if _, err := io.ReadFull(br, head); err != nil {
+					return nil, fmt.Errorf("%w: segmento truncado", ErrNotOgg)
+				}
+				if len(head) < 19 || !bytes.Equal(head[:8], []byte("OpusHead")) {
+					return nil, ErrNotOpus
+				}
+				info.Channels = int(head[9])
+				preSkip = int64(binary.LittleEndian.Uint16(head[10:12]))
+			} else if _, err := br.Discard(int(l)); err != nil {
+				return nil, fmt.Errorf("%w: segmento truncado", ErrNotOgg)
+			}
+			pending += int(l)
+			if l < 255 {
+				// OpusHead e OpusTags não são áudio.
+				if packet >= 2 {
+					sizes = append(sizes, pending)
+				}
+				packet++
+				pending = 0
+			}
+		}
+		if granule >= 0 && packet > 0 {
+			lastGranule = granule
+		}
+	}
+
+	if packet == 0 {
+		return nil, ErrNotOpus
+	}
+	if samples := lastGranule - preSkip; samples > 0 {
+		info.Seconds = uint32((samples + opusGranuleRate/2) / opusGranuleRate)
+	}
+	if info.Seconds == 0 && len(sizes) > 0 {
+		info.Seconds = 1
+	}
+	info.Waveform = waveform(sizes)
+	return info, nil
+}
+
+// waveform reduz os tamanhos de pacote a WaveformSamples barras
+// normalizadas pela maior delas.
+func waveform(sizes []int) []byte {
+	out := make([]byte, WaveformSamples)
+	if len(sizes) == 0 {
+		return out
+	}
+
+	avg := make([]float64, WaveformSamples)
+	peak := 0.0
+	for i := range avg {
+		start := i * len(sizes) / WaveformSamples
+		end := (i + 1) * len(sizes) / WaveformSamples
+		if end <= start {
+			end = start + 1
+		}
+		if end > len(sizes) {
+			end = len(sizes)
+		}
+		sum := 0
+		for _, n := range sizes[start:end] {
+			sum += n
+		}
+		avg[i] = float64(sum) / float64(end-start)
+		peak = max(peak, avg[i])
+	}
+	if peak == 0 {
+		return out
+	}
+	for i, v := range avg {
+		out[i] = byte(v / peak * 100)
+	}
+	return out
+}
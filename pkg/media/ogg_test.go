@@ -0,0 +1,156 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// oggPage monta uma página Ogg com os pacotes informados. O CRC não é
+// preenchido: ParseOggOpus não o confere.
+func oggPage(serial uint32, granule int64, packets ...[]byte) []byte {
+	var lacing, body []byte
+	for _, p := range packets {
+		n := len(p)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, p...)
+	}
+
+	hdr := make([]byte, 27)
+	copy(hdr, "OggS")
+	binary.LittleEndian.PutUint64(hdr[6:14], uint64(granule))
+	binary.LittleEndian.PutUint32(hdr[14:18], serial)
+	hdr[26] = byte(len(lacing))
+	return append(append(hdr, lacing...), body...)
+}
+
+func opusHead(channels byte, preSkip uint16) []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = channels
+	binary.LittleEndian.PutUint16(head[10:12], preSkip)
+	binary.LittleEndian.PutUint32(head[12:16], 48000)
+	return head
+}
+
+// opusFile gera um Ogg/Opus com seconds segundos de pacotes de 20 ms, cujo
+// tamanho cresce ao longo do arquivo.
+func opusFile(channels byte, seconds int) []byte {
+	const preSkip = 312
+	var out []byte
+	out = append(out, oggPage(1, 0, opusHead(channels, preSkip))...)
+	out = append(out, oggPage(1, 0, []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00"))...)
+
+	packets := seconds * 50
+	for i := 0; i < packets; i += 10 {
+		page := make([][]byte, 0, 10)
+		for j := i; j < i+10 && j < packets; j++ {
+			page = append(page, bytes.Repeat([]byte{0xfc}, 10+j%300))
+		}
+		granule := int64(preSkip + (i+len(page))*960)
+		out = append(out, oggPage(1, granule, page...)...)
+	}
+	return out
+}
+
+func TestParseOggOpus(t *testing.T) {
+	info, err := ParseOggOpus(bytes.NewReader(opusFile(2, 3)))
+	if err != nil {
+		t.Fatalf("ParseOggOpus: %v", err)
+	}
+	if info.Channels != 2 || info.Seconds != 3 {
+		t.Errorf("channels=%d seconds=%d, esperado 2 e 3", info.Channels, info.Seconds)
+	}
+	if len(info.Waveform) != WaveformSamples {
+		t.Fatalf("waveform com %d amostras", len(info.Waveform))
+	}
+	peak := byte(0)
+	for _, v := range info.Waveform {
+		if v > 100 {
+			t.Fatalf("amostra fora de 0..100: %d", v)
+		}
+		peak = max(peak, v)
+	}
+	if peak != 100 {
+		t.Errorf("pico da waveform = %d, esperado 100", peak)
+	}
+}
+
+func TestParseOggOpusIgnoresOtherStreams(t *testing.T) {
+	file := opusFile(1, 2)
+	// Uma página de outro stream lógico logo após o OpusHead não altera o
+	// resultado.
+	headEnd := len(oggPage(1, 0, opusHead(1, 312)))
+	other := oggPage(2, 999999999, bytes.Repeat([]byte{1}, 600))
+	mixed := append(append(append([]byte{}, file[:headEnd]...), other...), file[headEnd:]...)
+
+	info, err := ParseOggOpus(bytes.NewReader(mixed))
+	if err != nil {
+		t.Fatalf("ParseOggOpus: %v", err)
+	}
+	if info.Seconds != 2 || info.Channels != 1 {
+		t.Errorf("channels=%d seconds=%d, esperado 1 e 2", info.Channels, info.Seconds)
+	}
+}
+
+func TestParseOggOpusErrors(t *testing.T) {
+	valid := opusFile(1, 1)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"vazio", nil, ErrNotOgg},
+		{"não ogg", []byte("ID3\x03\x00 não é ogg, é mp3 com texto suficiente"), ErrNotOgg},
+		{"cabeçalho truncado", valid[:20], ErrNotOgg},
+		{"segmento truncado", valid[:len(valid)-5], ErrNotOgg},
+		{"sem OpusHead", oggPage(1, 0, []byte("\x01vorbis0123456789abcdef")), ErrNotOpus},
+		{"OpusHead curto", oggPage(1, 0, []byte("OpusHead\x01")), ErrNotOpus},
+		{"lixo após página", append(append([]byte{}, valid...), "lixo"...), ErrNotOgg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOggOpus(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("erro = %v, esperado %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaveformShortInput(t *testing.T) {
+	if w := waveform(nil); len(w) != WaveformSamples {
+		t.Fatalf("waveform(nil) com %d amostras", len(w))
+	}
+	w := waveform([]int{5, 10})
+	if len(w) != WaveformSamples || w[0] != 50 || w[WaveformSamples-1] != 100 {
+		t.Errorf("waveform([5 10]) = %v", w)
+	}
+}
+
+func FuzzParseOggOpus(f *testing.F) {
+	valid := opusFile(2, 1)
+	f.Add(valid)
+	f.Add(valid[:27])
+	f.Add(valid[:len(valid)/2])
+	f.Add(oggPage(1, -1, opusHead(1, 0)))
+	f.Add([]byte("OggS"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		info, err := ParseOggOpus(bytes.NewReader(data))
+		if err != nil {
+			if !errors.Is(err, ErrNotOgg) && !errors.Is(err, ErrNotOpus) {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			return
+		}
+		if len(info.Waveform) != WaveformSamples {
+			t.Fatalf("waveform com %d amostras", len(info.Waveform))
+		}
+	})
+}
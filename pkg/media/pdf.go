@@ -0,0 +1,67 @@
+package media
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	pdfPagesRegex = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+	pdfPageRegex  = regexp.MustCompile(`/Type\s*/Page\b`)
+)
+
+// O PDF é varrido em blocos com sobreposição, para que um dicionário
+// cortado na fronteira apareça inteiro em algum bloco.
+const (
+	pdfChunkSize = 1 << 20
+	pdfOverlap   = 4 << 10
+)
+
+// PDFPageCount conta as páginas de um PDF sem interpretá-lo por completo:
+// usa o maior /Count entre os nós /Pages (a raiz da árvore) e, sem ele,
+// conta os objetos /Page. PDFs com a árvore dentro de object streams
+// comprimidos não expõem nenhum dos dois e devolvem ok=false.
+func PDFPageCount(r io.ReaderAt, size int64) (int, bool) {
+	magic := make([]byte, 5)
+	if _, err := r.ReadAt(magic, 0); err != nil || !bytes.Equal(magic, []byte("%PDF-")) {
+		return 0, false
+	}
+
+	count, pages := 0, 0
+	buf := make([]byte, pdfChunkSize)
+	step := int64(pdfChunkSize - pdfOverlap)
+	for off := int64(0); off < size; off += step {
+		n, err := r.ReadAt(buf, off)
+		if n == 0 && err != nil {
+			break
+		}
+		chunk := buf[:n]
+		last := off+int64(n) >= size
+
+		for _, m := range pdfPagesRegex.FindAllSubmatch(chunk, -1) {
+			digits := m[1]
+			if len(digits) == 0 {
+				digits = m[2]
+			}
+			if v, err := strconv.Atoi(string(digits)); err == nil && v > count {
+				count = v
+			}
+		}
+		// Ocorrências na sobreposição são contadas pelo bloco seguinte.
+		for _, loc := range pdfPageRegex.FindAllIndex(chunk, -1) {
+			if last || int64(loc[0]) < step {
+				pages++
+			}
+		}
+		if last {
+			break
+		}
+	}
+
+	if count == 0 {
+		count = pages
+	}
+	return count, count > 0
+}
@@ -0,0 +1,90 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func pdfCount(data []byte) (int, bool) {
+	return PDFPageCount(bytes.NewReader(data), int64(len(data)))
+}
+
+func pdfWithPages(n int) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.7\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&b, "2 0 obj << /Type /Pages /Kids [] /Count %d >> endobj\n", n)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%d 0 obj << /Type /Page /Parent 2 0 R >> endobj\n", i+3)
+	}
+	b.WriteString("%%EOF\n")
+	return []byte(b.String())
+}
+
+func TestPDFPageCount(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+		ok   bool
+	}{
+		{"árvore de páginas", string(pdfWithPages(3)), 3, true},
+		{"Count antes do Type", "%PDF-1.4\n<< /Count 12 /Kids [] /Type /Pages >>", 12, true},
+		{"maior Count é a raiz", "%PDF-1.4\n<< /Type /Pages /Count 2 >> << /Type /Pages /Count 9 >> << /Type /Pages /Count 7 >>", 9, true},
+		{"sem Pages conta os objetos Page", "%PDF-1.4\n<< /Type /Page >> << /Type/Page >> << /Type /Pages >>", 2, true},
+		{"sem árvore visível", "%PDF-1.5\n<< /Type /ObjStm /N 3 >> stream ... endstream", 0, false},
+		{"sem cabeçalho", "<< /Type /Pages /Count 3 >>", 0, false},
+		{"vazio", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pdfCount([]byte(tt.data))
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("PDFPageCount = %d, %v; esperado %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// Os objetos /Page na sobreposição entre blocos aparecem em dois blocos e
+// devem ser contados uma vez só; um dicionário cortado na fronteira de um
+// bloco aparece inteiro no seguinte.
+func TestPDFPageCountAcrossChunks(t *testing.T) {
+	page := []byte("<< /Type /Page >>\n")
+	pad := func(b []byte, upTo int) []byte {
+		return append(b, bytes.Repeat([]byte{' '}, upTo-len(b))...)
+	}
+
+	t.Run("páginas na sobreposição", func(t *testing.T) {
+		data := pad([]byte("%PDF-1.4\n"), pdfChunkSize-pdfOverlap/2)
+		data = append(data, page...)
+		data = pad(data, pdfChunkSize+pdfOverlap)
+		data = append(data, page...)
+		if got, ok := pdfCount(data); got != 2 || !ok {
+			t.Errorf("PDFPageCount = %d, %v; esperado 2, true", got, ok)
+		}
+	})
+
+	t.Run("Count cortado na fronteira", func(t *testing.T) {
+		pages := []byte("<< /Type /Pages /Count 42 >>")
+		data := pad([]byte("%PDF-1.4\n"), pdfChunkSize-10)
+		data = append(data, pages...)
+		data = pad(data, 2*pdfChunkSize)
+		if got, ok := pdfCount(data); got != 42 || !ok {
+			t.Errorf("PDFPageCount = %d, %v; esperado 42, true", got, ok)
+		}
+	})
+}
+
+func FuzzPDFPageCount(f *testing.F) {
+	f.Add(pdfWithPages(2))
+	f.Add([]byte("%PDF-1.4\n<< /Count 99999999999999999999 /Type /Pages >>"))
+	f.Add([]byte("%PDF-"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if n, ok := pdfCount(data); n < 0 || ok != (n > 0) {
+			t.Fatalf("PDFPageCount = %d, %v", n, ok)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+var ErrTypeMismatch = errors.New("conteúdo não corresponde ao tipo declarado")
+
+// CheckContentType compara o tipo declarado com os bytes iniciais do
+// arquivo. Só famílias são comparadas (imagem, áudio, vídeo, HTML, outros):
+// subtipos variam demais entre servidores para servir de critério. Quando
+// nenhum dos lados é conclusivo, o tipo declarado é aceito.
+func (m *File) CheckContentType(declared string) error {
+	want := typeFamily(declared)
+	got := typeFamily(m.DetectContentType())
+	if want == familyUnknown || got == familyUnknown || want == got {
+		return nil
+	}
+	// Contêineres que servem tanto a áudio quanto a vídeo.
+	if got == familyAudioVideo && (want == familyAudio || want == familyVideo) {
+		return nil
+	}
+	if want == familyAudioVideo && (got == familyAudio || got == familyVideo) {
+		return nil
+	}
+	return fmt.Errorf("%w: declarado %s, detectado %s", ErrTypeMismatch, baseType(declared), baseType(m.DetectContentType()))
+}
+
+type family int
+
+const (
+	familyUnknown family = iota
+	familyImage
+	familyAudio
+	familyVideo
+	familyAudioVideo
+	familyHTML
+	familyOther
+)
+
+func typeFamily(contentType string) family {
+	t := baseType(contentType)
+	switch t {
+	case "", "application/octet-stream", "binary/octet-stream", "text/plain", "text/xml", "application/xml":
+		return familyUnknown
+	case "application/ogg", "video/mp4", "video/webm", "audio/mp4", "audio/webm":
+		return familyAudioVideo
+	case "text/html", "application/xhtml+xml":
+		return familyHTML
+	}
+	switch {
+	case strings.HasPrefix(t, "image/"):
+		return familyImage
+	case strings.HasPrefix(t, "audio/"):
+		return familyAudio
+	case strings.HasPrefix(t, "video/"):
+		return familyVideo
+	}
+	return familyOther
+}
+
+func baseType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return t
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNotWebP = errors.New("arquivo não é uma imagem WebP")
+
+// WebPInfo resume o cabeçalho de uma imagem WebP.
+type WebPInfo struct {
+	Width    int
+	Height   int
+	Animated bool
+}
+
+// ParseWebP lê as dimensões do contêiner RIFF/WEBP nos formatos simples
+// (VP8 com perdas, VP8L sem perdas) e estendido (VP8X, usado por figurinhas
+// animadas). Só os cabeçalhos são lidos.
+func ParseWebP(r io.Reader) (*WebPInfo, error) {
+	riff := make([]byte, 12)
+	if _, err := io.ReadFull(r, riff); err != nil || !bytes.Equal(riff[0:4], []byte("RIFF")) || !bytes.Equal(riff[8:12], []byte("WEBP")) {
+		return nil, ErrNotWebP
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, fmt.Errorf("%w: nenhum quadro encontrado", ErrNotWebP)
+		}
+		fourCC := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch fourCC {
+		case "VP8X", "VP8 ", "VP8L":
+			// Os campos usados estão nos primeiros 10 bytes do chunk.
+			chunk := make([]byte, min(size, 10))
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("%w: chunk %q truncado", ErrNotWebP, fourCC)
+			}
+			return parseWebPFrame(fourCC, chunk)
+		}
+
+		// Chunks têm tamanho par; o byte de preenchimento não entra em size.
+		if _, err := io.CopyN(io.Discard, r, size+size&1); err != nil {
+			return nil, fmt.Errorf("%w: chunk %q truncado", ErrNotWebP, fourCC)
+		}
+	}
+}
+
+func parseWebPFrame(fourCC string, chunk []byte) (*WebPInfo, error) {
+	switch fourCC {
+	case "VP8X":
+		if len(chunk) < 10 {
+			return nil, fmt.Errorf("%w: cabeçalho VP8X curto", ErrNotWebP)
+		}
+		return &WebPInfo{
+			Width:    1 + int(uint24(chunk[4:7])),
+			Height:   1 + int(uint24(chunk[7:10])),
+			Animated: chunk[0]&0x02 != 0,
+		}, nil
+	case "VP8 ":
+		if len(chunk) < 10 || chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return nil, fmt.Errorf("%w: quadro VP8 inválido", ErrNotWebP)
+		}
+		return &WebPInfo{
+			Width:  int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff),
+			Height: int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff),
+		}, nil
+	default: // VP8L
+		if len(chunk) < 5 || chunk[0] != 0x2f {
+			return nil, fmt.Errorf("%w: quadro VP8L inválido", ErrNotWebP)
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		return &WebPInfo{
+			Width:  int(bits&0x3fff) + 1,
+			Height: int((bits>>14)&0x3fff) + 1,
+		}, nil
+	}
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// riffChunk monta um chunk RIFF com o byte de preenchimento dos tamanhos
+// ímpares.
+func riffChunk(fourCC string, body []byte) []byte {
+	out := make([]byte, 8, 8+len(body)+1)
+	copy(out, fourCC)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(body)))
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out
+}
+
+func vp8xChunk(width, height int, animated bool) []byte {
+	body := make([]byte, 10)
+	if animated {
+		body[0] = 0x02
+	}
+	w, h := uint32(width-1), uint32(height-1)
+	body[4], body[5], body[6] = byte(w), byte(w>>8), byte(w>>16)
+	body[7], body[8], body[9] = byte(h), byte(h>>8), byte(h>>16)
+	return riffChunk("VP8X", body)
+}
+
+func vp8Chunk(width, height int) []byte {
+	body := []byte{0x50, 0x01, 0x00, 0x9d, 0x01, 0x2a, 0, 0, 0, 0, 0xaa, 0xbb}
+	binary.LittleEndian.PutUint16(body[6:8], uint16(width))
+	binary.LittleEndian.PutUint16(body[8:10], uint16(height))
+	return riffChunk("VP8 ", body)
+}
+
+func vp8lChunk(width, height int) []byte {
+	body := make([]byte, 7)
+	body[0] = 0x2f
+	binary.LittleEndian.PutUint32(body[1:5], uint32(width-1)|uint32(height-1)<<14)
+	return riffChunk("VP8L", body)
+}
+
+func TestParseWebP(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want WebPInfo
+	}{
+		{"VP8 com perdas", webpFile(vp8Chunk(512, 384)), WebPInfo{Width: 512, Height: 384}},
+		{"VP8L sem perdas", webpFile(vp8lChunk(512, 512)), WebPInfo{Width: 512, Height: 512}},
+		{"VP8X animado", webpFile(vp8xChunk(512, 512, true), riffChunk("ANIM", make([]byte, 6))), WebPInfo{Width: 512, Height: 512, Animated: true}},
+		{"VP8X estático", webpFile(vp8xChunk(96, 96, false)), WebPInfo{Width: 96, Height: 96}},
+		{"chunk desconhecido de tamanho ímpar antes do quadro", webpFile(riffChunk("XYZW", []byte("abc")), vp8lChunk(30, 20)), WebPInfo{Width: 30, Height: 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseWebP(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ParseWebP: %v", err)
+			}
+			if *info != tt.want {
+				t.Errorf("ParseWebP = %+v, esperado %+v", *info, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWebPErrors(t *testing.T) {
+	valid := webpFile(vp8Chunk(512, 384))
+	badVP8 := vp8Chunk(10, 10)
+	badVP8[8+3] = 0
+	badVP8L := vp8lChunk(10, 10)
+	badVP8L[8] = 0
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"vazio", nil},
+		{"não riff", []byte("GIF89a não é webp mesmo")},
+		{"riff sem WEBP", []byte("RIFF\x04\x00\x00\x00WAVEfmt ")},
+		{"sem quadro", webpFile(riffChunk("EXIF", []byte("1234")))},
+		{"chunk truncado", valid[:len(valid)-8]},
+		{"VP8X curto", webpFile(riffChunk("VP8X", make([]byte, 4)))},
+		{"VP8 sem assinatura", webpFile(badVP8)},
+		{"VP8L sem assinatura", webpFile(badVP8L)},
+		{"chunk desconhecido maior que o arquivo", webpFile([]byte("XYZW\xff\xff\xff\xff"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseWebP(bytes.NewReader(tt.data)); !errors.Is(err, ErrNotWebP) {
+				t.Errorf("erro = %v, esperado ErrNotWebP", err)
+			}
+		})
+	}
+}
+
+func FuzzParseWebP(f *testing.F) {
+	f.Add(webpFile(vp8Chunk(512, 384)))
+	f.Add(webpFile(vp8lChunk(512, 512)))
+	f.Add(webpFile(vp8xChunk(512, 512, true)))
+	f.Add(webpFile(riffChunk("XYZW", []byte("abc")), vp8lChunk(1, 1)))
+	f.Add([]byte("RIFF\x00\x00\x00\x00WEBP"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		info, err := ParseWebP(bytes.NewReader(data))
+		if err != nil {
+			if !errors.Is(err, ErrNotWebP) {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			return
+		}
+		if info.Width < 0 || info.Height < 0 {
+			t.Fatalf("dimensões negativas: %+v", info)
+		}
+	})
+}
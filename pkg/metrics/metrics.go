@@ -0,0 +1,339 @@
+// Package metrics implementa contadores, gauges e histogramas expostos no
+// formato texto do Prometheus (0.0.4), sem dependências externas.
+//
+// Os valores dos labels devem vir de conjuntos limitados (templates de rota,
+// tipos de mensagem, sessões cadastradas): cada combinação vira uma série
+// mantida em memória até ser removida com Delete.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType é o tipo do formato de exposição servido por Handler.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets são os limites padrão dos histogramas de latência, em segundos.
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default é o registro usado pelo endpoint /metrics.
+var Default = NewRegistry()
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry agrupa as métricas de um endpoint de scrape.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.collectors[c.name()]; dup {
+		panic("metrics: métrica registrada duas vezes: " + c.name())
+	}
+	r.collectors[c.name()] = c
+}
+
+// Write escreve todas as métricas, em ordem alfabética, no formato texto.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serve o registro para o scrape do Prometheus.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.Write(w)
+	})
+}
+
+type desc struct {
+	fqName string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) name() string { return d.fqName }
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.fqName, escapeHelp(d.help), d.fqName, d.typ)
+}
+
+func (d *desc) checkLabels(values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s espera %d labels, recebeu %d", d.fqName, len(d.labels), len(values)))
+	}
+}
+
+// series guarda os valores de uma combinação de labels, indexada pela
+// junção dos valores.
+type series[T any] struct {
+	mu     sync.Mutex
+	values map[string]*T
+	labels map[string][]string
+}
+
+func newSeries[T any]() series[T] {
+	return series[T]{values: make(map[string]*T), labels: make(map[string][]string)}
+}
+
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// get devolve o valor da série, criando-o na primeira vez. Deve ser chamado
+// com mu travado.
+func (s *series[T]) get(values []string) *T {
+	key := seriesKey(values)
+	v, ok := s.values[key]
+	if !ok {
+		v = new(T)
+		s.values[key] = v
+		s.labels[key] = append([]string(nil), values...)
+	}
+	return v
+}
+
+// deletePrefix remove as séries cujos primeiros labels são values.
+func (s *series[T]) deletePrefix(values []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, lv := range s.labels {
+		if len(lv) >= len(values) && equal(lv[:len(values)], values) {
+			delete(s.values, key)
+			delete(s.labels, key)
+		}
+	}
+}
+
+// sorted devolve as chaves em ordem, para uma saída estável.
+func (s *series[T]) sorted() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec é um contador monotônico particionado por labels.
+type CounterVec struct {
+	desc
+	series series[float64]
+}
+
+// NewCounterVec cria e registra um contador.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{fqName: name, help: help, typ: "counter", labels: labels}, series: newSeries[float64]()}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add soma v (>= 0) ao contador.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: contador não pode diminuir: " + c.fqName)
+	}
+	c.checkLabels(labelValues)
+	c.series.mu.Lock()
+	*c.series.get(labelValues) += v
+	c.series.mu.Unlock()
+}
+
+// Delete remove as séries cujos primeiros labels são labelValues.
+func (c *CounterVec) Delete(labelValues ...string) {
+	c.series.deletePrefix(labelValues)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	c.series.mu.Lock()
+	defer c.series.mu.Unlock()
+	for _, key := range c.series.sorted() {
+		writeSample(w, c.fqName, c.labels, c.series.labels[key], "", "", *c.series.values[key])
+	}
+}
+
+// HistogramVec distribui observações em buckets cumulativos.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	series  series[histogram]
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec cria e registra um histograma. Sem buckets, usa
+// DefBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{desc: desc{fqName: name, help: help, typ: "histogram", labels: labels}, buckets: buckets, series: newSeries[histogram]()}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.checkLabels(labelValues)
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	s := h.series.get(labelValues)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	for i, le := range h.buckets {
+		if v <= le {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Delete remove as séries cujos primeiros labels são labelValues.
+func (h *HistogramVec) Delete(labelValues ...string) {
+	h.series.deletePrefix(labelValues)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.series.mu.Lock()
+	defer h.series.mu.Unlock()
+	for _, key := range h.series.sorted() {
+		s, lv := h.series.values[key], h.series.labels[key]
+		for i, le := range h.buckets {
+			writeSample(w, h.fqName+"_bucket", h.labels, lv, "le", formatFloat(le), float64(s.counts[i]))
+		}
+		writeSample(w, h.fqName+"_bucket", h.labels, lv, "le", "+Inf", float64(s.count))
+		writeSample(w, h.fqName+"_sum", h.labels, lv, "", "", s.sum)
+		writeSample(w, h.fqName+"_count", h.labels, lv, "", "", float64(s.count))
+	}
+}
+
+// GaugeFunc é um gauge calculado no momento do scrape, para estados que já
+// existem em outro lugar (sessões conectadas, tamanho de caches).
+type GaugeFunc struct {
+	desc
+	collect func(set func(v float64, labelValues ...string))
+}
+
+// NewGaugeFunc cria e registra um gauge. collect chama set uma vez por
+// série a cada scrape.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func(set func(v float64, labelValues ...string))) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{fqName: name, help: help, typ: "gauge", labels: labels}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	type sample struct {
+		labels []string
+		value  float64
+	}
+	samples := make([]sample, 0)
+	g.collect(func(v float64, labelValues ...string) {
+		g.checkLabels(labelValues)
+		samples = append(samples, sample{append([]string(nil), labelValues...), v})
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		return seriesKey(samples[i].labels) < seriesKey(samples[j].labels)
+	})
+
+	g.writeHeader(w)
+	for _, s := range samples {
+		writeSample(w, g.fqName, g.labels, s.labels, "", "", s.value)
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraName, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", l, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+func equal(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
@@ -0,0 +1,235 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func expose(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func checkExposition(t *testing.T, r *Registry, want string) {
+	t.Helper()
+	if got := expose(t, r); got != want {
+		t.Errorf("exposição:\n%s\nesperado:\n%s", got, want)
+	}
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("msgs_total", "Mensagens.", "tenant", "type")
+	c.Inc("t2", "text")
+	c.Inc("t1", "text")
+	c.Add(2.5, "t1", "text")
+	c.Add(0, "t1", "image")
+
+	checkExposition(t, r, `# HELP msgs_total Mensagens.
+# TYPE msgs_total counter
+msgs_total{tenant="t1",type="image"} 0
+msgs_total{tenant="t1",type="text"} 3.5
+msgs_total{tenant="t2",type="text"} 1
+`)
+}
+
+func TestCounterWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("up_total", "Sem labels.").Inc()
+
+	checkExposition(t, r, `# HELP up_total Sem labels.
+# TYPE up_total counter
+up_total 1
+`)
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("esc_total", "Ajuda com \\ e\nquebra \"literal\".", "v")
+	c.Inc(`a\b`)
+	c.Inc(`diz "oi"`)
+	c.Inc("linha\nnova")
+
+	checkExposition(t, r, `# HELP esc_total Ajuda com \\ e\nquebra "literal".
+# TYPE esc_total counter
+esc_total{v="a\\b"} 1
+esc_total{v="diz \"oi\""} 1
+esc_total{v="linha\nnova"} 1
+`)
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("lat_seconds", "Latência.", []float64{1, 0.1, 0.5}, "route")
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		h.Observe(v, "/a")
+	}
+	h.Observe(0.2, "/b")
+
+	// Buckets ordenados e cumulativos; +Inf é o total de observações.
+	checkExposition(t, r, `# HELP lat_seconds Latência.
+# TYPE lat_seconds histogram
+lat_seconds_bucket{route="/a",le="0.1"} 2
+lat_seconds_bucket{route="/a",le="0.5"} 3
+lat_seconds_bucket{route="/a",le="1"} 4
+lat_seconds_bucket{route="/a",le="+Inf"} 5
+lat_seconds_sum{route="/a"} 3.15
+lat_seconds_count{route="/a"} 5
+lat_seconds_bucket{route="/b",le="0.1"} 0
+lat_seconds_bucket{route="/b",le="0.5"} 1
+lat_seconds_bucket{route="/b",le="1"} 1
+lat_seconds_bucket{route="/b",le="+Inf"} 1
+lat_seconds_sum{route="/b"} 0.2
+lat_seconds_count{route="/b"} 1
+`)
+}
+
+func TestHistogramDefaultBuckets(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("d_seconds", "Padrão.", nil)
+	h.Observe(20)
+
+	out := expose(t, r)
+	if n := strings.Count(out, "d_seconds_bucket{"); n != len(DefBuckets)+1 {
+		t.Errorf("%d buckets, esperado %d", n, len(DefBuckets)+1)
+	}
+	for _, line := range []string{`d_seconds_bucket{le="10"} 0`, `d_seconds_bucket{le="+Inf"} 1`, `d_seconds_sum 20`} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("sem a linha %q em:\n%s", line, out)
+		}
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("c_total", "C.", "tenant", "session", "type")
+	h := r.NewHistogramVec("h_seconds", "H.", []float64{1}, "tenant", "session")
+	c.Inc("t1", "s1", "text")
+	c.Inc("t1", "s1", "image")
+	c.Inc("t1", "s10", "text")
+	c.Inc("t2", "s1", "text")
+	h.Observe(0.5, "t1", "s1")
+	h.Observe(0.5, "t1", "s2")
+
+	c.Delete("t1", "s1")
+	h.Delete("t1", "s1")
+
+	checkExposition(t, r, `# HELP c_total C.
+# TYPE c_total counter
+c_total{tenant="t1",session="s10",type="text"} 1
+c_total{tenant="t2",session="s1",type="text"} 1
+# HELP h_seconds H.
+# TYPE h_seconds histogram
+h_seconds_bucket{tenant="t1",session="s2",le="1"} 1
+h_seconds_bucket{tenant="t1",session="s2",le="+Inf"} 1
+h_seconds_sum{tenant="t1",session="s2"} 0.5
+h_seconds_count{tenant="t1",session="s2"} 1
+`)
+
+	// A série removida recomeça do zero.
+	c.Inc("t1", "s1", "text")
+	if out := expose(t, r); !strings.Contains(out, `c_total{tenant="t1",session="s1",type="text"} 1`+"\n") {
+		t.Errorf("série recriada não recomeçou do zero:\n%s", out)
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("conn", "Conectada.", []string{"session"}, func(set func(float64, ...string)) {
+		set(0, "s2")
+		set(1, "s1")
+	})
+	r.NewGaugeFunc("clients", "Clientes.", nil, func(set func(float64, ...string)) {
+		set(math.Inf(1))
+	})
+
+	// As métricas saem em ordem alfabética, e as séries ordenadas por label.
+	checkExposition(t, r, `# HELP clients Clientes.
+# TYPE clients gauge
+clients +Inf
+# HELP conn Conectada.
+# TYPE conn gauge
+conn{session="s1"} 1
+conn{session="s2"} 0
+`)
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{0.25, "0.25"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.v); got != tt.want {
+			t.Errorf("formatFloat(%v) = %s, esperado %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+		want string
+	}{
+		{"labels a menos no contador", func(r *Registry) {
+			r.NewCounterVec("a_total", "A.", "tenant", "session").Inc("t1")
+		}, "a_total espera 2 labels, recebeu 1"},
+		{"labels a mais no histograma", func(r *Registry) {
+			r.NewHistogramVec("b_seconds", "B.", nil, "route").Observe(1, "/a", "GET")
+		}, "b_seconds espera 1 labels, recebeu 2"},
+		{"labels errados no gauge", func(r *Registry) {
+			r.NewGaugeFunc("c", "C.", []string{"session"}, func(set func(float64, ...string)) { set(1) })
+			_ = r.Write(&strings.Builder{})
+		}, "c espera 1 labels, recebeu 0"},
+		{"contador diminuindo", func(r *Registry) {
+			r.NewCounterVec("d_total", "D.").Add(-1)
+		}, "contador não pode diminuir: d_total"},
+		{"registro duplicado", func(r *Registry) {
+			r.NewCounterVec("e_total", "E.")
+			r.NewCounterVec("e_total", "E.")
+		}, "métrica registrada duas vezes: e_total"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("não entrou em pânico")
+				}
+				if msg, _ := r.(string); !strings.Contains(msg, tt.want) {
+					t.Errorf("pânico = %v, esperado %q", r, tt.want)
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("h_total", "H.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, esperado %q", ct, ContentType)
+	}
+	if want := "# HELP h_total H.\n# TYPE h_total counter\nh_total 1\n"; rec.Body.String() != want {
+		t.Errorf("corpo = %q, esperado %q", rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,198 @@
+package msgtemplate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Formatação do WhatsApp: *negrito*, _itálico_, ~tachado~ e ```monoespaçado```.
+// O WhatsApp só reconhece um marcador que abre antes de um caractere visível
+// e fecha depois de um, sem letra ou dígito colado do lado de fora; por isso
+// "snake_case" e "2*3" continuam literais. As variáveis são preenchidas dentro
+// da estrutura do template: um valor com espaços nas pontas não quebra o
+// negrito em volta dele, e um trecho formatado que ficou vazio é removido.
+
+var ErrUnbalancedFormat = errors.New("formatação sem fechamento")
+
+// FormatError aponta o marcador aberto e não fechado.
+type FormatError struct {
+	Marker string
+	Line   int
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s: %s na linha %d", ErrUnbalancedFormat, e.Marker, e.Line)
+}
+
+func (e *FormatError) Unwrap() error { return ErrUnbalancedFormat }
+
+const monoMarker = "```"
+
+// Cada variável vira um único caractere de uso privado durante a análise,
+// para que nomes com _ não sejam lidos como itálico.
+const slotBase = 0xF0000
+
+type formatNode struct {
+	marker   string // vazio para texto literal
+	text     string
+	children []formatNode
+}
+
+// CheckFormat confere se todo marcador de formatação aberto no texto é
+// fechado (trechos inline na mesma linha).
+func CheckFormat(text string) error {
+	_, _, err := parseFormat(text)
+	return err
+}
+
+func slots(text string) (string, []string) {
+	names := make([]string, 0)
+	out := placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		names = append(names, strings.ToLower(placeholder.FindStringSubmatch(m)[1]))
+		return string(rune(slotBase + len(names) - 1))
+	})
+	return out, names
+}
+
+// parseFormat separa os blocos monoespaçados e os trechos inline do texto.
+// Marcadores sem fechamento ficam literais e são devolvidos como erro.
+func parseFormat(text string) ([]formatNode, []string, error) {
+	text, names := slots(text)
+	var firstErr error
+
+	nodes := make([]formatNode, 0)
+	parts := strings.Split(text, monoMarker)
+	line := 1
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			nodes = append(nodes, formatNode{marker: monoMarker, text: part})
+		case i%2 == 1:
+			// ``` sem par: o restante é texto comum.
+			if firstErr == nil {
+				firstErr = &FormatError{Marker: monoMarker, Line: line}
+			}
+			nodes = append(nodes, formatNode{text: monoMarker})
+			fallthrough
+		default:
+			for j, l := range strings.Split(part, "\n") {
+				if j > 0 {
+					nodes = append(nodes, formatNode{text: "\n"})
+				}
+				inline, err := parseInline([]rune(l), line+j)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				nodes = append(nodes, inline...)
+			}
+		}
+		line += strings.Count(part, "\n")
+	}
+	return nodes, names, firstErr
+}
+
+func isFormatMarker(r rune) bool {
+	return r == '*' || r == '_' || r == '~'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func parseInline(runes []rune, line int) ([]formatNode, error) {
+	var firstErr error
+	nodes := make([]formatNode, 0)
+	var buf []rune
+	flush := func() {
+		if len(buf) > 0 {
+			nodes = append(nodes, formatNode{text: string(buf)})
+			buf = nil
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if isFormatMarker(r) && canOpen(runes, i) {
+			if j := findClose(runes, i); j > 0 {
+				flush()
+				children, err := parseInline(runes[i+1:j], line)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				nodes = append(nodes, formatNode{marker: string(r), children: children})
+				i = j
+				continue
+			}
+			if firstErr == nil {
+				firstErr = &FormatError{Marker: string(r), Line: line}
+			}
+		}
+		buf = append(buf, r)
+	}
+	flush()
+	return nodes, firstErr
+}
+
+func canOpen(runes []rune, i int) bool {
+	if i > 0 && (isWordRune(runes[i-1]) || runes[i-1] == runes[i]) {
+		return false
+	}
+	return i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != runes[i]
+}
+
+func findClose(runes []rune, i int) int {
+	for j := i + 2; j < len(runes); j++ {
+		if runes[j] != runes[i] || unicode.IsSpace(runes[j-1]) {
+			continue
+		}
+		if j+1 == len(runes) || !isWordRune(runes[j+1]) {
+			return j
+		}
+	}
+	return -1
+}
+
+// renderFormat monta o texto final, preenchendo as variáveis e ajustando os
+// trechos formatados.
+func renderFormat(nodes []formatNode, names []string, vars map[string]string) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.marker {
+		case "":
+			b.WriteString(expandSlots(n.text, names, vars))
+			continue
+		case monoMarker:
+			// O bloco monoespaçado preserva espaços e quebras de linha.
+			if inner := expandSlots(n.text, names, vars); strings.TrimSpace(inner) != "" {
+				b.WriteString(monoMarker + inner + monoMarker)
+			}
+			continue
+		}
+
+		inner := renderFormat(n.children, names, vars)
+		core := strings.TrimSpace(inner)
+		if core == "" {
+			b.WriteString(inner)
+			continue
+		}
+		start := strings.Index(inner, core)
+		b.WriteString(inner[:start])
+		b.WriteString(n.marker + core + n.marker)
+		b.WriteString(inner[start+len(core):])
+	}
+	return b.String()
+}
+
+func expandSlots(s string, names []string, vars map[string]string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= slotBase && int(r-slotBase) < len(names) {
+			b.WriteString(vars[names[r-slotBase]])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
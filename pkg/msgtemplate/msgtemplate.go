@@ -0,0 +1,77 @@
+// Package msgtemplate preenche mensagens com variáveis no formato {{nome}}.
+// Nomes aceitam letras, dígitos e _ e não diferenciam maiúsculas de
+// minúsculas; espaços dentro das chaves são ignorados.
+package msgtemplate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrMissingVariables = errors.New("variáveis sem valor")
+
+var placeholder = regexp.MustCompile(`\{\{\s*([\p{L}\p{N}_]+)\s*\}\}`)
+
+// MissingError lista as variáveis do texto que não receberam valor.
+type MissingError struct {
+	Names []string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrMissingVariables, strings.Join(e.Names, ", "))
+}
+
+func (e *MissingError) Unwrap() error { return ErrMissingVariables }
+
+// Variables devolve, em ordem alfabética e sem repetição, os nomes das
+// variáveis usadas no texto.
+func Variables(text string) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(m[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Normalize devolve as variáveis com os nomes em minúsculas, como Render
+// as procura.
+func Normalize(vars map[string]string) map[string]string {
+	out := make(map[string]string, len(vars))
+	for k, v := range vars {
+		out[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return out
+}
+
+// Missing devolve as variáveis do texto ausentes em vars (já normalizado).
+// Valores vazios contam como informados.
+func Missing(text string, vars map[string]string) []string {
+	missing := make([]string, 0)
+	for _, name := range Variables(text) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Render substitui as variáveis do texto e ajusta a formatação do WhatsApp
+// em volta delas (ver format.go). Se alguma não tiver valor, nada é
+// substituído e o erro é um *MissingError.
+func Render(text string, vars map[string]string) (string, error) {
+	vars = Normalize(vars)
+	if missing := Missing(text, vars); len(missing) > 0 {
+		return "", &MissingError{Names: missing}
+	}
+	nodes, names, _ := parseFormat(text)
+	return renderFormat(nodes, names, vars), nil
+}
@@ -0,0 +1,106 @@
+package msgtemplate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVariables(t *testing.T) {
+	got := Variables("Olá {{ Nome }}, {{valor}} vence em {{data_vencimento}}. {{nome}} {{}} {x}")
+	want := []string{"data_vencimento", "nome", "valor"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables = %v, esperado %v", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		vars map[string]string
+		want string
+	}{
+		{"simples", "Olá {{nome}}!", map[string]string{"nome": "Ana"}, "Olá Ana!"},
+		{"nome sem diferenciar maiúsculas", "{{ Nome }}", map[string]string{" NOME ": "Ana"}, "Ana"},
+		{"valor vazio informado", "a{{x}}b", map[string]string{"x": ""}, "ab"},
+		{"snake_case literal", "snake_case e {{x}}", map[string]string{"x": "v"}, "snake_case e v"},
+		{"variável com _ no nome", "_{{primeiro_nome}}_", map[string]string{"primeiro_nome": "Ana"}, "_Ana_"},
+		{"2*3 literal", "2*3 = {{x}}", map[string]string{"x": "6"}, "2*3 = 6"},
+		{"multiplicação espaçada", "a * b * {{x}}", map[string]string{"x": "c"}, "a * b * c"},
+		{"negrito", "*{{x}}*", map[string]string{"x": "Ana"}, "*Ana*"},
+		{"negrito com valor em branco", "Oi *{{x}}*!", map[string]string{"x": ""}, "Oi !"},
+		{"negrito com valor só de espaços", "Oi *{{x}}*!", map[string]string{"x": "  "}, "Oi   !"},
+		{"negrito com valor acolchoado", "Oi *{{x}}*!", map[string]string{"x": " Ana  "}, "Oi  *Ana*  !"},
+		{"texto fixo e variável no negrito", "*Total: {{x}}*", map[string]string{"x": "R$ 10 "}, "*Total: R$ 10* "},
+		{"aninhado", "*_{{x}}_*", map[string]string{"x": " a "}, " *_a_* "},
+		{"itálico e tachado", "_{{a}}_ ~{{b}}~", map[string]string{"a": "x", "b": "y"}, "_x_ ~y~"},
+		{"marcador sem fechamento fica literal", "*oi {{x}}", map[string]string{"x": "Ana"}, "*oi Ana"},
+		{"marcador colado em letra", "a*b* {{x}}", map[string]string{"x": "c"}, "a*b* c"},
+		{"bloco monoespaçado preserva espaços", "```{{x}}```", map[string]string{"x": "  a  b "}, "```  a  b ```"},
+		{"bloco monoespaçado vazio é removido", "antes ```{{x}}``` depois", map[string]string{"x": " \n "}, "antes  depois"},
+		{"bloco não interpreta marcadores", "```*{{x}}*```", map[string]string{"x": ""}, "```**```"},
+		{"bloco em várias linhas", "```\n{{x}}\n```\n*{{y}}*", map[string]string{"x": "l1", "y": "b"}, "```\nl1\n```\n*b*"},
+		{"``` sem par fica literal", "```{{x}}", map[string]string{"x": "a"}, "```a"},
+		{"negrito não atravessa linhas", "*{{x}}\n{{y}}*", map[string]string{"x": "a", "y": "b"}, "*a\nb*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, tt.vars)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, esperado %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMissing(t *testing.T) {
+	_, err := Render("{{b}} {{a}} {{c}}", map[string]string{"C": "x"})
+	var missing *MissingError
+	if !errors.As(err, &missing) || !errors.Is(err, ErrMissingVariables) {
+		t.Fatalf("erro = %v, esperado *MissingError", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(missing.Names, want) {
+		t.Errorf("Names = %v, esperado %v", missing.Names, want)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		marker string
+		line   int
+	}{
+		{"balanceado", "*negrito* _itálico_ ~tachado~ ```mono```", "", 0},
+		{"literais", "snake_case, 2*3, a * b, 10_000 e *", "", 0},
+		{"variável com _", "Olá {{primeiro_nome}} e {{sobre_nome}}", "", 0},
+		{"negrito aberto", "Olá *{{nome}}", "*", 1},
+		{"itálico aberto na segunda linha", "ok\n_linha dois", "_", 2},
+		{"tachado aberto dentro de negrito", "*a ~b*", "~", 1},
+		{"mono sem fechamento", "```\ncódigo", "```", 1},
+		{"erro depois de um bloco", "```a\nb```\n\n~x", "~", 4},
+		{"marcadores dentro do bloco são ignorados", "```*_~```", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFormat(tt.text)
+			if tt.marker == "" {
+				if err != nil {
+					t.Fatalf("CheckFormat(%q) = %v", tt.text, err)
+				}
+				return
+			}
+			var fe *FormatError
+			if !errors.As(err, &fe) || !errors.Is(err, ErrUnbalancedFormat) {
+				t.Fatalf("CheckFormat(%q) = %v, esperado *FormatError", tt.text, err)
+			}
+			if fe.Marker != tt.marker || fe.Line != tt.line {
+				t.Errorf("FormatError = %s na linha %d, esperado %s na linha %d", fe.Marker, fe.Line, tt.marker, tt.line)
+			}
+		})
+	}
+}
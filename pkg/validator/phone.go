@@ -0,0 +1,230 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normalização de números para E.164 (+<país><número nacional>). Números com
+// + ou 00 são internacionais. Sem prefixo, o número é lido primeiro no plano
+// de numeração do país padrão (com ou sem o código do país) e depois como
+// internacional; o código do país padrão só é acrescentado quando o número
+// cabe no plano dele.
+
+// PhoneNumber é um número normalizado. CountryCode fica vazio quando o
+// código do país não está na tabela de regras; nesse caso National traz o
+// número completo.
+type PhoneNumber struct {
+	CountryCode string
+	National    string
+	Region      string
+	// Alternates são outras grafias do mesmo número que podem estar
+	// registradas no WhatsApp, como o celular brasileiro sem o nono dígito.
+	Alternates []string
+}
+
+// Digits devolve o número completo, sem o +.
+func (p PhoneNumber) Digits() string {
+	return p.CountryCode + p.National
+}
+
+func (p PhoneNumber) E164() string {
+	return "+" + p.Digits()
+}
+
+// Candidates devolve o número canônico seguido das grafias alternativas.
+func (p PhoneNumber) Candidates() []string {
+	return append([]string{p.Digits()}, p.Alternates...)
+}
+
+type countryRule struct {
+	code     string
+	region   string
+	min, max int // dígitos do número nacional
+	// normalize, quando existe, substitui a checagem de tamanho e devolve o
+	// número nacional canônico e suas grafias alternativas.
+	normalize func(national string) (string, []string, error)
+}
+
+func (c countryRule) parse(national string) (PhoneNumber, error) {
+	var alternates []string
+	if c.normalize != nil {
+		n, alts, err := c.normalize(national)
+		if err != nil {
+			return PhoneNumber{}, err
+		}
+		national, alternates = n, alts
+	} else if len(national) < c.min || len(national) > c.max {
+		if c.min == c.max {
+			return PhoneNumber{}, fmt.Errorf("números de %s (+%s) têm %d dígitos após o código do país", c.region, c.code, c.min)
+		}
+		return PhoneNumber{}, fmt.Errorf("números de %s (+%s) têm de %d a %d dígitos após o código do país", c.region, c.code, c.min, c.max)
+	}
+
+	p := PhoneNumber{CountryCode: c.code, National: national, Region: c.region}
+	for _, a := range alternates {
+		p.Alternates = append(p.Alternates, c.code+a)
+	}
+	return p, nil
+}
+
+var countryRules = map[string]countryRule{}
+
+func init() {
+	for _, c := range []countryRule{
+		{code: "1", region: "US", min: 10, max: 10, normalize: normalizeNANP},
+		{code: "7", region: "RU", min: 10, max: 10},
+		{code: "27", region: "ZA", min: 9, max: 9},
+		{code: "33", region: "FR", min: 9, max: 9},
+		{code: "34", region: "ES", min: 9, max: 9},
+		{code: "39", region: "IT", min: 6, max: 11},
+		{code: "44", region: "GB", min: 9, max: 10},
+		{code: "49", region: "DE", min: 6, max: 13},
+		{code: "51", region: "PE", min: 8, max: 9},
+		{code: "52", region: "MX", min: 10, max: 10, normalize: normalizeMX},
+		{code: "54", region: "AR", min: 10, max: 11},
+		{code: "55", region: "BR", normalize: normalizeBR},
+		{code: "56", region: "CL", min: 9, max: 9},
+		{code: "57", region: "CO", min: 10, max: 10},
+		{code: "58", region: "VE", min: 10, max: 10},
+		{code: "61", region: "AU", min: 9, max: 9},
+		{code: "81", region: "JP", min: 9, max: 10},
+		{code: "86", region: "CN", min: 10, max: 11},
+		{code: "91", region: "IN", min: 10, max: 10},
+		{code: "351", region: "PT", min: 9, max: 9},
+		{code: "591", region: "BO", min: 8, max: 8},
+		{code: "593", region: "EC", min: 8, max: 9},
+		{code: "595", region: "PY", min: 9, max: 9},
+		{code: "598", region: "UY", min: 8, max: 8},
+	} {
+		countryRules[c.code] = c
+	}
+}
+
+// DDDs em uso no Brasil.
+var brAreaCodes = map[string]bool{}
+
+func init() {
+	for _, ddd := range strings.Fields(`11 12 13 14 15 16 17 18 19 21 22 24 27 28 31 32 33 34 35 37 38
+		41 42 43 44 45 46 47 48 49 51 53 54 55 61 62 63 64 65 66 67 68 69 71 73 74 75 77 79
+		81 82 83 84 85 86 87 88 89 91 92 93 94 95 96 97 98 99`) {
+		brAreaCodes[ddd] = true
+	}
+}
+
+// normalizeBR aceita DDD + 8 ou 9 dígitos, com ou sem o 0 de discagem
+// interurbana. Celulares são devolvidos com o nono dígito e têm como
+// alternativa a grafia de 8 dígitos, com que muitas contas antigas seguem
+// registradas no WhatsApp.
+func normalizeBR(national string) (string, []string, error) {
+	if strings.HasPrefix(national, "0") && (len(national) == 11 || len(national) == 12) {
+		national = national[1:]
+	}
+	if len(national) != 10 && len(national) != 11 {
+		return "", nil, fmt.Errorf("números do Brasil (+55) têm DDD e 8 ou 9 dígitos")
+	}
+	ddd, subscriber := national[:2], national[2:]
+	if !brAreaCodes[ddd] {
+		return "", nil, fmt.Errorf("DDD %s inexistente", ddd)
+	}
+
+	switch {
+	case len(subscriber) == 9:
+		if subscriber[0] != '9' {
+			return "", nil, fmt.Errorf("celulares do Brasil com 9 dígitos começam com 9")
+		}
+		if subscriber[1] >= '6' {
+			return national, []string{ddd + subscriber[1:]}, nil
+		}
+		return national, nil, nil
+	case subscriber[0] >= '6':
+		return ddd + "9" + subscriber, []string{national}, nil
+	case subscriber[0] >= '2':
+		return national, nil, nil
+	default:
+		return "", nil, fmt.Errorf("número do Brasil inválido após o DDD %s", ddd)
+	}
+}
+
+// normalizeNANP valida códigos de área e prefixos dos EUA/Canadá, que não
+// começam com 0 ou 1.
+func normalizeNANP(national string) (string, []string, error) {
+	if len(national) != 10 || national[0] < '2' || national[3] < '2' {
+		return "", nil, fmt.Errorf("números dos EUA/Canadá (+1) têm 10 dígitos, com código de área e prefixo iniciando de 2 a 9")
+	}
+	return national, nil, nil
+}
+
+// normalizeMX remove o 1 que celulares mexicanos usavam após o +52.
+func normalizeMX(national string) (string, []string, error) {
+	if len(national) == 11 && national[0] == '1' {
+		national = national[1:]
+	}
+	if len(national) != 10 {
+		return "", nil, fmt.Errorf("números do México (+52) têm 10 dígitos após o código do país")
+	}
+	return national, nil, nil
+}
+
+var phoneFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "", "\t", "")
+
+// NormalizePhone converte o número informado para E.164, usando
+// defaultCountry (código do país, ex.: "55") para números sem prefixo
+// internacional.
+func NormalizePhone(raw, defaultCountry string) (PhoneNumber, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return PhoneNumber{}, fmt.Errorf("número de telefone é obrigatório")
+	}
+	international := strings.HasPrefix(s, "+")
+	digits := phoneFormatting.Replace(strings.TrimPrefix(s, "+"))
+	if !digitsRegex.MatchString(digits) {
+		return PhoneNumber{}, fmt.Errorf("número de telefone deve conter apenas dígitos")
+	}
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+	if international {
+		return parseInternational(digits)
+	}
+
+	rule, known := countryRules[defaultCountry]
+	if !known {
+		// Sem regra para o país padrão, o código só é acrescentado quando o
+		// número não é reconhecido como internacional.
+		if p, err := parseInternational(digits); err == nil && p.CountryCode != "" {
+			return p, nil
+		}
+		if !strings.HasPrefix(digits, defaultCountry) {
+			digits = defaultCountry + digits
+		}
+		return parseInternational(digits)
+	}
+
+	if rest, ok := strings.CutPrefix(digits, defaultCountry); ok {
+		if p, err := rule.parse(rest); err == nil {
+			return p, nil
+		}
+	}
+	p, err := rule.parse(digits)
+	if err == nil {
+		return p, nil
+	}
+	if intl, ierr := parseInternational(digits); ierr == nil && intl.CountryCode != "" {
+		return intl, nil
+	}
+	return PhoneNumber{}, fmt.Errorf("%v; para outros países use +<código do país><número>", err)
+}
+
+func parseInternational(digits string) (PhoneNumber, error) {
+	for l := 1; l <= 3 && l < len(digits); l++ {
+		if rule, ok := countryRules[digits[:l]]; ok {
+			return rule.parse(digits[l:])
+		}
+	}
+	if len(digits) < 8 || len(digits) > 15 {
+		return PhoneNumber{}, fmt.Errorf("número internacional deve ter de 8 a 15 dígitos")
+	}
+	return PhoneNumber{National: digits}, nil
+}
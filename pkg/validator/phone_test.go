@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            string
+		defaultCountry string
+		want           string // E.164; vazio espera erro
+		alternates     []string
+	}{
+		// Brasil, país padrão.
+		{"celular com código do país", "5511987654321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular formatado", "+55 (11) 98765-4321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular sem código do país", "11987654321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular de 8 dígitos ganha o nono", "1187654321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular de 8 dígitos com código do país", "551187654321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular com 0 de longa distância", "011987654321", "55", "+5511987654321", []string{"551187654321"}},
+		{"celular sem grafia de 8 dígitos", "11951234567", "55", "+5511951234567", nil},
+		{"fixo", "1133334444", "55", "+551133334444", nil},
+		{"fixo com 0 de longa distância", "01133334444", "55", "+551133334444", nil},
+		{"DDD 55 sem código do país", "55999998888", "55", "+5555999998888", []string{"555599998888"}},
+		{"DDD 55 de 8 dígitos sem código do país", "5591234567", "55", "+5555991234567", []string{"555591234567"}},
+		{"DDD 55 com código do país", "5555999998888", "55", "+5555999998888", []string{"555599998888"}},
+		{"prefixo 00", "00 55 11 98765 4321", "55", "+5511987654321", []string{"551187654321"}},
+		{"DDD inexistente", "2098765432", "55", "", nil},
+		{"assinante começando com 1", "1113334444", "55", "", nil},
+		{"9 dígitos sem começar com 9", "11887654321", "55", "", nil},
+		{"curto demais", "119876", "55", "", nil},
+
+		// Outro país a partir do padrão brasileiro.
+		{"internacional sem + cai no plano do país", "351912345678", "55", "+351912345678", nil},
+		{"internacional com +", "+351 912 345 678", "55", "+351912345678", nil},
+		{"+55 não aceita número de outro plano", "+55123", "55", "", nil},
+
+		// Entrada.
+		{"vazio", "  ", "55", "", nil},
+		{"letras", "11 9876-ABCD", "55", "", nil},
+		{"+ no meio", "+55+11987654321", "55", "", nil},
+
+		// País padrão sem regra na tabela.
+		{"padrão sem regra acrescenta o código", "612345678", "31", "+31612345678", nil},
+		{"padrão sem regra reconhece internacional", "5511987654321", "31", "+5511987654321", []string{"551187654321"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NormalizePhone(tt.raw, tt.defaultCountry)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("NormalizePhone(%q) = %s, esperado erro", tt.raw, p.E164())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizePhone(%q): %v", tt.raw, err)
+			}
+			if p.E164() != tt.want {
+				t.Errorf("NormalizePhone(%q) = %s, esperado %s", tt.raw, p.E164(), tt.want)
+			}
+			if !reflect.DeepEqual(p.Alternates, tt.alternates) {
+				t.Errorf("Alternates = %v, esperado %v", p.Alternates, tt.alternates)
+			}
+		})
+	}
+}
+
+func TestPhoneNumberCandidates(t *testing.T) {
+	p, err := NormalizePhone("1187654321", "55")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.CountryCode != "55" || p.National != "11987654321" || p.Region != "BR" {
+		t.Errorf("PhoneNumber = %+v", p)
+	}
+	want := []string{"5511987654321", "551187654321"}
+	if got := p.Candidates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates = %v, esperado %v", got, want)
+	}
+}
+
+func TestNormalizeBR(t *testing.T) {
+	tests := []struct {
+		national   string
+		want       string // vazio espera erro
+		alternates []string
+	}{
+		{"11987654321", "11987654321", []string{"1187654321"}},
+		{"1187654321", "11987654321", []string{"1187654321"}},
+		{"1166554433", "11966554433", []string{"1166554433"}},
+		{"11951234567", "11951234567", nil},
+		{"1133334444", "1133334444", nil},
+		{"1123334444", "1123334444", nil},
+		{"011987654321", "11987654321", []string{"1187654321"}},
+		{"01133334444", "1133334444", nil},
+		{"0133334444", "", nil},
+		{"00987654321", "", nil},
+		{"1013334444", "", nil},
+		{"11887654321", "", nil},
+		{"119876543210", "", nil},
+		{"118765432", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.national, func(t *testing.T) {
+			got, alts, err := normalizeBR(tt.national)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("normalizeBR(%s) = %s, esperado erro", tt.national, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeBR(%s): %v", tt.national, err)
+			}
+			if got != tt.want || !reflect.DeepEqual(alts, tt.alternates) {
+				t.Errorf("normalizeBR(%s) = %s %v, esperado %s %v", tt.national, got, alts, tt.want, tt.alternates)
+			}
+		})
+	}
+}
+
+func TestNormalizeMXAndNANP(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) (string, []string, error)
+		in   string
+		want string // vazio espera erro
+	}{
+		{"MX 10 dígitos", normalizeMX, "5512345678", "5512345678"},
+		{"MX com o 1 antigo", normalizeMX, "15512345678", "5512345678"},
+		{"MX 11 dígitos sem 1", normalizeMX, "25512345678", ""},
+		{"MX curto", normalizeMX, "551234567", ""},
+		{"NANP", normalizeNANP, "2025550123", "2025550123"},
+		{"NANP área começando com 1", normalizeNANP, "1025550123", ""},
+		{"NANP área começando com 0", normalizeNANP, "0025550123", ""},
+		{"NANP prefixo começando com 1", normalizeNANP, "2021550123", ""},
+		{"NANP 11 dígitos", normalizeNANP, "12025550123", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := tt.fn(tt.in)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("%s = %s, esperado erro", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("%s = %s, %v; esperado %s", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInternational(t *testing.T) {
+	tests := []struct {
+		digits  string
+		code    string
+		region  string
+		wantErr bool
+	}{
+		{"12025550123", "1", "US", false},
+		{"5215512345678", "52", "MX", false},
+		{"525512345678", "52", "MX", false},
+		{"4930123456", "49", "DE", false},
+		{"351912345678", "351", "PT", false},
+		{"59899123456", "598", "UY", false},
+		{"5511987654321", "55", "BR", false},
+		{"447911123456", "44", "GB", false},
+		{"8613912345678", "86", "CN", false},
+		// Código fora da tabela: o número inteiro fica em National.
+		{"31612345678", "", "", false},
+		{"999123456789012", "", "", false},
+		{"3161234", "", "", true},
+		{"9991234567890123", "", "", true},
+		// Código conhecido com número fora do plano do país.
+		{"11025550123", "", "", true},
+		{"3519123", "", "", true},
+		{"49", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.digits, func(t *testing.T) {
+			p, err := parseInternational(tt.digits)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseInternational(%s) = %+v, esperado erro", tt.digits, p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseInternational(%s): %v", tt.digits, err)
+			}
+			if p.CountryCode != tt.code || p.Region != tt.region {
+				t.Errorf("parseInternational(%s) = %+v, esperado +%s (%s)", tt.digits, p, tt.code, tt.region)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tipos de destinatário aceitos no campo number dos envios.
+const (
+	RecipientPhone      = "phone"
+	RecipientUser       = "user"
+	RecipientGroup      = "group"
+	RecipientNewsletter = "newsletter"
+	RecipientInvite     = "invite"
+)
+
+var (
+	phoneUserRegex  = regexp.MustCompile(`^[0-9]{8,15}$`)
+	lidUserRegex    = regexp.MustCompile(`^[0-9]{1,20}$`)
+	groupIDRegex    = regexp.MustCompile(`^[0-9]{16,24}$|^[0-9]{8,15}-[0-9]{8,12}$`)
+	newsletterRegex = regexp.MustCompile(`^[0-9]{8,24}$`)
+	inviteCodeRegex = regexp.MustCompile(`^[A-Za-z0-9]{16,32}$`)
+	digitsRegex     = regexp.MustCompile(`^[0-9]+$`)
+	phoneInputRegex = regexp.MustCompile(`^\+?[0-9][0-9 ().-]*$`)
+)
+
+// ValidateRecipient identifica o tipo do destinatário e valida o formato
+// correspondente. Aceita número de telefone (com ou sem +, espaços, hífens e
+// parênteses), JID completo (usuário, LID, grupo ou canal), ID de grupo sem
+// sufixo e link de convite de grupo.
+func ValidateRecipient(raw string) (string, error) {
+	r := strings.TrimSpace(raw)
+	if r == "" {
+		return "", fmt.Errorf("destinatário é obrigatório")
+	}
+
+	if _, ok := InviteCode(r); ok {
+		return RecipientInvite, nil
+	}
+	if strings.Contains(r, "chat.whatsapp.com") {
+		return "", fmt.Errorf("link de convite inválido")
+	}
+
+	if user, server, ok := strings.Cut(r, "@"); ok {
+		return validateJID(user, server)
+	}
+
+	if groupIDRegex.MatchString(r) {
+		return RecipientGroup, nil
+	}
+	if phoneInputRegex.MatchString(r) {
+		digits := phoneFormatting.Replace(strings.TrimPrefix(r, "+"))
+		if len(digits) <= 15 {
+			if err := ValidatePhoneNumber(digits); err != nil {
+				return "", err
+			}
+			return RecipientPhone, nil
+		}
+	}
+	return "", fmt.Errorf("formato de destinatário inválido: use número, JID ou link de convite")
+}
+
+func validateJID(user, server string) (string, error) {
+	switch server {
+	case "s.whatsapp.net", "c.us":
+		if !phoneUserRegex.MatchString(user) {
+			return "", fmt.Errorf("JID de usuário inválido")
+		}
+		return RecipientUser, nil
+	case "lid":
+		if !lidUserRegex.MatchString(user) {
+			return "", fmt.Errorf("LID inválido")
+		}
+		return RecipientUser, nil
+	case "g.us":
+		if !groupIDRegex.MatchString(user) {
+			return "", fmt.Errorf("JID de grupo inválido")
+		}
+		return RecipientGroup, nil
+	case "newsletter":
+		if !newsletterRegex.MatchString(user) {
+			return "", fmt.Errorf("JID de canal inválido")
+		}
+		return RecipientNewsletter, nil
+	case "broadcast":
+		return "", fmt.Errorf("listas de transmissão e status não são suportados pelo WhatsApp multi-dispositivo")
+	default:
+		return "", fmt.Errorf("servidor de JID não suportado: %s", server)
+	}
+}
+
+// ValidateMention aceita apenas destinatários que identificam uma pessoa
+// (número ou JID de usuário/LID), que são os únicos mencionáveis.
+func ValidateMention(raw string) error {
+	kind, err := ValidateRecipient(raw)
+	if err != nil {
+		return err
+	}
+	if kind != RecipientPhone && kind != RecipientUser {
+		return fmt.Errorf("menções aceitam apenas números ou JIDs de usuário")
+	}
+	return nil
+}
+
+// InviteCode extrai o código de um link https://chat.whatsapp.com/<código>,
+// com ou sem esquema.
+func InviteCode(raw string) (string, bool) {
+	r := strings.TrimSpace(raw)
+	r = strings.TrimPrefix(r, "https://")
+	r = strings.TrimPrefix(r, "http://")
+	code, ok := strings.CutPrefix(r, "chat.whatsapp.com/")
+	if !ok {
+		return "", false
+	}
+	code = strings.TrimSuffix(code, "/")
+	if !inviteCodeRegex.MatchString(code) {
+		return "", false
+	}
+	return code, true
+}
@@ -0,0 +1,349 @@
+package validator
+
+import (
+	"fmt"
+	"mime"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validação por tags, no formato validate:"regra,regra=parâmetro":
+//
+//	required              valor não vazio (strings só com espaços contam como vazias)
+//	omitempty             dispensa as demais regras quando o valor está vazio
+//	required_with=A B     obrigatório quando algum dos campos A, B está preenchido
+//	required_without=A B  obrigatório quando algum dos campos A, B está vazio
+//	required_without_all=A B  obrigatório quando todos os campos A, B estão vazios
+//	required_if=A valor   obrigatório quando o campo A vale valor
+//	excluded_with=A B     proibido quando algum dos campos A, B está preenchido
+//	min=N, max=N          tamanho de strings (em caracteres) e listas, ou valor de números
+//	oneof=a b c           um dos valores listados
+//	email, url, e164, mime  formato de e-mail, URL http(s), telefone e tipo MIME
+//	dive                  aplica as regras seguintes a cada item da lista
+//
+// As regras condicionais dispensam as seguintes quando o campo não é exigido
+// e está vazio. Structs aninhadas (inclusive em listas) são validadas pelas
+// próprias tags, com o caminho do campo no JSON (preview.title,
+// contacts[0].full_name).
+
+// FieldErrors são os erros de validação indexados pelo caminho do campo no
+// JSON. Vai direto para os details do ErrorResponse.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f + ": " + e[f]
+	}
+	return strings.Join(parts, "; ")
+}
+
+var defaultCountry string
+
+// SetDefaultCountry define o código do país usado pela regra e164 para
+// números sem prefixo internacional (WHATSAPP_DEFAULT_COUNTRY).
+func SetDefaultCountry(code string) {
+	defaultCountry = code
+}
+
+// Struct valida v (struct ou ponteiro para struct) pelas tags validate.
+// Devolve nil quando não há erros.
+func Struct(v any) FieldErrors {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validator: Struct espera uma struct, recebeu %T", v))
+	}
+	errs := FieldErrors{}
+	validateStruct(rv, "", errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func validateStruct(rv reflect.Value, prefix string, errs FieldErrors) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Tag.Get("json") == "" {
+			if s := reflect.Indirect(fv); s.Kind() == reflect.Struct {
+				validateStruct(s, prefix, errs)
+			}
+			continue
+		}
+
+		path := prefix + name
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			checkField(rv, fv, path, strings.Split(tag, ","), errs)
+		}
+		if _, failed := errs[path]; !failed {
+			validateNested(fv, path, errs)
+		}
+	}
+}
+
+// validateNested desce em structs, ponteiros para struct e listas de
+// structs.
+func validateNested(fv reflect.Value, path string, errs FieldErrors) {
+	fv = reflect.Indirect(fv)
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != timeType {
+			validateStruct(fv, path+".", errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for j := 0; j < fv.Len(); j++ {
+			if elem := reflect.Indirect(fv.Index(j)); elem.Kind() == reflect.Struct && elem.Type() != timeType {
+				validateStruct(elem, fmt.Sprintf("%s[%d].", path, j), errs)
+			}
+		}
+	}
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+func checkField(parent, fv reflect.Value, path string, rules []string, errs FieldErrors) {
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			if isEmpty(fv) {
+				return
+			}
+			continue
+		case "dive":
+			v := reflect.Indirect(fv)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				panic("validator: dive exige uma lista em " + path)
+			}
+			for j := 0; j < v.Len(); j++ {
+				checkField(parent, v.Index(j), fmt.Sprintf("%s[%d]", path, j), rules[i+1:], errs)
+			}
+			return
+		}
+
+		if conditional, ok := conditionalRules[name]; ok {
+			required, msg := conditional(parent, param)
+			switch {
+			case !isEmpty(fv):
+				continue
+			case required:
+				errs[path] = msg
+			}
+			return
+		}
+
+		if msg := checkRule(parent, fv, name, param); msg != "" {
+			errs[path] = msg
+			return
+		}
+	}
+}
+
+// conditionalRules decidem, a partir dos outros campos, se o campo é
+// exigido, e devolvem a mensagem para quando ele falta.
+var conditionalRules = map[string]func(parent reflect.Value, param string) (bool, string){
+	"required_with": func(parent reflect.Value, param string) (bool, string) {
+		fields := strings.Fields(param)
+		return anyField(parent, fields, false), "obrigatório com " + jsonNames(parent, fields, " ou ")
+	},
+	"required_without": func(parent reflect.Value, param string) (bool, string) {
+		fields := strings.Fields(param)
+		return anyField(parent, fields, true), "obrigatório sem " + jsonNames(parent, fields, " ou ")
+	},
+	"required_without_all": func(parent reflect.Value, param string) (bool, string) {
+		fields := strings.Fields(param)
+		return !anyField(parent, fields, false), "obrigatório sem " + jsonNames(parent, fields, " e ")
+	},
+	"required_if": func(parent reflect.Value, param string) (bool, string) {
+		field, value, _ := strings.Cut(param, " ")
+		other := fieldByName(parent, field)
+		return fmt.Sprint(reflect.Indirect(other).Interface()) == value, "obrigatório com " + jsonNames(parent, []string{field}, "") + " " + value
+	},
+}
+
+func checkRule(parent, fv reflect.Value, name, param string) string {
+	if name == "required" {
+		if isEmpty(fv) {
+			return "obrigatório"
+		}
+		return ""
+	}
+	if name == "excluded_with" {
+		fields := strings.Fields(param)
+		if !isEmpty(fv) && anyField(parent, fields, false) {
+			return "não permitido com " + jsonNames(parent, fields, " ou ")
+		}
+		return ""
+	}
+
+	v := reflect.Indirect(fv)
+	if !v.IsValid() {
+		// Ponteiro nil: só as regras de presença se aplicam.
+		return ""
+	}
+	switch name {
+	case "min", "max":
+		return checkBound(v, name, param)
+	case "oneof":
+		options := strings.Fields(param)
+		value := fmt.Sprint(v.Interface())
+		for _, o := range options {
+			if value == o {
+				return ""
+			}
+		}
+		return "use " + joinOptions(options)
+	}
+
+	s, ok := v.Interface().(string)
+	if !ok {
+		panic(fmt.Sprintf("validator: regra %s exige string", name))
+	}
+	switch name {
+	case "email":
+		if !validEmail(s) {
+			return "e-mail inválido"
+		}
+	case "url":
+		if err := ValidateURL(s); err != nil {
+			return err.Error()
+		}
+	case "e164":
+		if _, err := NormalizePhone(s, defaultCountry); err != nil {
+			return err.Error()
+		}
+	case "mime":
+		mt, _, err := mime.ParseMediaType(s)
+		if typ, sub, ok := strings.Cut(mt, "/"); err != nil || !ok || typ == "" || sub == "" {
+			return "tipo MIME inválido (ex.: image/jpeg)"
+		}
+	default:
+		panic("validator: regra desconhecida: " + name)
+	}
+	return ""
+}
+
+func checkBound(v reflect.Value, name, param string) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("validator: parâmetro inválido em " + name + "=" + param)
+	}
+
+	var n float64
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " caracteres"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), " itens"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic("validator: " + name + " não se aplica a " + v.Kind().String())
+	}
+
+	switch {
+	case name == "min" && n < limit && unit == "":
+		return "mínimo de " + param
+	case name == "min" && n < limit:
+		return "mínimo de " + param + unit
+	case name == "max" && n > limit && unit == "":
+		return "máximo de " + param
+	case name == "max" && n > limit:
+		return "máximo de " + param + unit
+	}
+	return ""
+}
+
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return false
+	}
+	_, domain, _ := strings.Cut(addr.Address, "@")
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func fieldByName(parent reflect.Value, name string) reflect.Value {
+	f := parent.FieldByName(name)
+	if !f.IsValid() {
+		panic(fmt.Sprintf("validator: campo %s não existe em %s", name, parent.Type()))
+	}
+	return f
+}
+
+// anyField indica se algum dos campos está vazio (empty) ou preenchido.
+func anyField(parent reflect.Value, fields []string, empty bool) bool {
+	for _, f := range fields {
+		if isEmpty(fieldByName(parent, f)) == empty {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonNames nomeia os campos nas mensagens. Campos fora do JSON (json:"-")
+// podem ser citados nas regras, mas não aparecem para o cliente.
+func jsonNames(parent reflect.Value, fields []string, sep string) string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		sf, _ := parent.Type().FieldByName(f)
+		if name := jsonName(sf); name != "-" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, sep)
+}
+
+func joinOptions(options []string) string {
+	if len(options) <= 1 {
+		return strings.Join(options, "")
+	}
+	return strings.Join(options[:len(options)-1], ", ") + " ou " + options[len(options)-1]
+}
@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Street string `json:"street" validate:"required"`
+	Zip    string `json:"zip" validate:"omitempty,min=8,max=9"`
+}
+
+type Common struct {
+	Tag string `json:"tag" validate:"omitempty,oneof=a b"`
+}
+
+type sample struct {
+	Common
+	Name     string     `json:"name" validate:"required,max=5"`
+	Email    string     `json:"email,omitempty" validate:"omitempty,email"`
+	Site     string     `json:"site" validate:"omitempty,url"`
+	Kind     string     `json:"kind" validate:"omitempty,oneof=person company"`
+	Document string     `json:"document" validate:"required_if=Kind company"`
+	Phone    string     `json:"phone" validate:"required_if=Kind person,e164"`
+	URL      string     `json:"url" validate:"required_without_all=Base64 Upload,excluded_with=Base64,omitempty,url"`
+	Base64   string     `json:"base64" validate:"required_without_all=URL Upload"`
+	Mime     string     `json:"mime" validate:"required_with=Base64,mime"`
+	Thumb    string     `json:"thumb" validate:"required_without=URL Kind"`
+	Age      int        `json:"age" validate:"min=18,max=130"`
+	Score    float64    `json:"score" validate:"max=1.5"`
+	Tags     []string   `json:"tags" validate:"max=2,dive,min=2"`
+	Phones   []string   `json:"phones" validate:"dive,e164"`
+	Home     *address   `json:"home"`
+	Others   []address  `json:"others"`
+	When     *time.Time `json:"when" validate:"required"`
+	Upload   bool       `json:"-"`
+	Ignored  string     `json:"-" validate:"required"`
+	hidden   string     `validate:"required"`
+}
+
+func validSample() sample {
+	now := time.Now()
+	return sample{
+		Name:   "Ana",
+		Kind:   "person",
+		Phone:  "11987654321",
+		URL:    "https://example.com/a.jpg",
+		Thumb:  "x",
+		Age:    30,
+		Tags:   []string{"ab"},
+		Phones: []string{"+5511987654321"},
+		Home:   &address{Street: "Rua A"},
+		When:   &now,
+	}
+}
+
+func TestStructValid(t *testing.T) {
+	SetDefaultCountry("55")
+	if errs := Struct(validSample()); errs != nil {
+		t.Fatalf("Struct = %v", errs)
+	}
+	s := validSample()
+	if errs := Struct(&s); errs != nil {
+		t.Fatalf("Struct(ponteiro) = %v", errs)
+	}
+}
+
+func TestStructRules(t *testing.T) {
+	SetDefaultCountry("55")
+	tests := []struct {
+		name   string
+		modify func(*sample)
+		want   map[string]string
+	}{
+		{"required vazio", func(s *sample) { s.Name = "" }, map[string]string{"name": "obrigatório"}},
+		{"required só espaços", func(s *sample) { s.Name = "   " }, map[string]string{"name": "obrigatório"}},
+		{"required ponteiro nil", func(s *sample) { s.When = nil }, map[string]string{"when": "obrigatório"}},
+		{"max em caracteres", func(s *sample) { s.Name = "Ana Maria" }, map[string]string{"name": "máximo de 5 caracteres"}},
+		{"max conta runas", func(s *sample) { s.Name = "Ângel" }, nil},
+		{"min de número", func(s *sample) { s.Age = 17 }, map[string]string{"age": "mínimo de 18"}},
+		{"max de número", func(s *sample) { s.Age = 131 }, map[string]string{"age": "máximo de 130"}},
+		{"max de float", func(s *sample) { s.Score = 1.6 }, map[string]string{"score": "máximo de 1.5"}},
+		{"max de lista", func(s *sample) { s.Tags = []string{"aa", "bb", "cc"} }, map[string]string{"tags": "máximo de 2 itens"}},
+		{"email", func(s *sample) { s.Email = "Ana <ana@example.com>" }, map[string]string{"email": "e-mail inválido"}},
+		{"email sem ponto no domínio", func(s *sample) { s.Email = "ana@localhost" }, map[string]string{"email": "e-mail inválido"}},
+		{"email válido", func(s *sample) { s.Email = "ana@example.com" }, nil},
+		{"url", func(s *sample) { s.Site = "ftp://example.com" }, map[string]string{"site": ""}},
+		{"oneof", func(s *sample) { s.Kind = "robot" }, map[string]string{"kind": "use person ou company"}},
+		{"oneof em struct embutida", func(s *sample) { s.Tag = "c" }, map[string]string{"tag": "use a ou b"}},
+		{"mime", func(s *sample) { s.Mime = "imagem" }, map[string]string{"mime": "tipo MIME inválido (ex.: image/jpeg)"}},
+		{"mime com parâmetros", func(s *sample) { s.Mime = "text/plain; charset=utf-8" }, nil},
+
+		{"required_if exigido", func(s *sample) { s.Kind = "company" }, map[string]string{"document": "obrigatório com kind company"}},
+		{"required_if exigido e ausente", func(s *sample) { s.Phone = "" }, map[string]string{"phone": "obrigatório com kind person"}},
+		{"required_if dispensa as regras seguintes", func(s *sample) { s.Kind, s.Phone, s.Document = "company", "", "123" }, nil},
+		{"required_if preenchido segue para e164", func(s *sample) { s.Phone = "123" }, map[string]string{"phone": ""}},
+		{"e164 validado mesmo sem exigência", func(s *sample) { s.Kind, s.Document, s.Phone = "company", "1", "abc" }, map[string]string{"phone": ""}},
+
+		{"required_without_all", func(s *sample) { s.URL = "" }, map[string]string{
+			"url":    "obrigatório sem base64",
+			"base64": "obrigatório sem url",
+		}},
+		{"required_without_all satisfeito por campo fora do JSON", func(s *sample) { s.URL, s.Upload, s.Thumb = "", true, "x" }, nil},
+		{"required_without com um vazio", func(s *sample) { s.Kind, s.Phone, s.Thumb = "", "", "" }, map[string]string{"thumb": "obrigatório sem url ou kind"}},
+		{"required_with", func(s *sample) { s.URL, s.Base64 = "", "aGk=" }, map[string]string{"mime": "obrigatório com base64"}},
+		{"required_with satisfeito", func(s *sample) { s.URL, s.Base64, s.Mime = "", "aGk=", "image/png" }, nil},
+		{"excluded_with", func(s *sample) { s.Base64, s.Mime = "aGk=", "image/png" }, map[string]string{"url": "não permitido com base64"}},
+		{"url depois das regras condicionais", func(s *sample) { s.URL = "example.com/a.jpg" }, map[string]string{"url": "URL deve usar http ou https"}},
+		{"url sem host depois das regras condicionais", func(s *sample) { s.URL = "https:///a.jpg" }, map[string]string{"url": "URL deve conter host"}},
+		{"excluded_with antes de url", func(s *sample) { s.URL, s.Base64, s.Mime = "x", "aGk=", "image/png" }, map[string]string{"url": "não permitido com base64"}},
+
+		{"dive", func(s *sample) { s.Tags = []string{"ab", "c"} }, map[string]string{"tags[1]": "mínimo de 2 caracteres"}},
+		{"dive com e164", func(s *sample) { s.Phones = []string{"+5511987654321", "x", "11"} }, map[string]string{"phones[1]": "", "phones[2]": ""}},
+		{"dive em lista vazia", func(s *sample) { s.Tags = nil }, nil},
+
+		{"struct aninhada", func(s *sample) { s.Home = &address{Zip: "123"} }, map[string]string{
+			"home.street": "obrigatório",
+			"home.zip":    "mínimo de 8 caracteres",
+		}},
+		{"ponteiro aninhado nil", func(s *sample) { s.Home = nil }, nil},
+		{"lista de structs", func(s *sample) { s.Others = []address{{Street: "B"}, {Zip: "0123456789"}} }, map[string]string{
+			"others[1].street": "obrigatório",
+			"others[1].zip":    "máximo de 9 caracteres",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validSample()
+			tt.modify(&s)
+			errs := Struct(s)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Struct = %v, esperado %v", errs, tt.want)
+			}
+			for field, msg := range tt.want {
+				got, ok := errs[field]
+				if !ok {
+					t.Fatalf("sem erro em %s: %v", field, errs)
+				}
+				// Mensagem vazia na tabela: só confere o campo.
+				if msg != "" && got != msg {
+					t.Errorf("%s = %q, esperado %q", field, got, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldErrorsError(t *testing.T) {
+	errs := FieldErrors{"b": "obrigatório", "a": "máximo de 5 caracteres"}
+	if got, want := errs.Error(), "a: máximo de 5 caracteres; b: obrigatório"; got != want {
+		t.Errorf("Error = %q, esperado %q", got, want)
+	}
+}
+
+func TestStructPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"não é struct", "texto", "Struct espera uma struct"},
+		{"regra desconhecida", struct {
+			A string `validate:"cpf"`
+		}{A: "1"}, "regra desconhecida: cpf"},
+		{"dive fora de lista", struct {
+			A string `validate:"dive,required"`
+		}{}, "dive exige uma lista"},
+		{"campo inexistente", struct {
+			A string `validate:"required_with=B"`
+		}{}, "campo B não existe"},
+		{"parâmetro inválido", struct {
+			A string `validate:"max=dez"`
+		}{A: "x"}, "parâmetro inválido em max=dez"},
+		{"regra de texto em número", struct {
+			A int `validate:"email"`
+		}{A: 1}, "regra email exige string"},
+		{"limite em tipo sem tamanho", struct {
+			A bool `validate:"min=1"`
+		}{A: true}, "min não se aplica a bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("Struct não entrou em pânico")
+				}
+				if msg, _ := r.(string); !strings.Contains(msg, tt.want) {
+					t.Errorf("pânico = %v, esperado %q", r, tt.want)
+				}
+			}()
+			Struct(tt.value)
+		})
+	}
+}
+
+func TestJSONNamesSkipsHiddenFields(t *testing.T) {
+	var s sample
+	got := jsonNames(reflect.ValueOf(s), []string{"URL", "Upload", "Base64"}, " e ")
+	if got != "url e base64" {
+		t.Errorf("jsonNames = %q", got)
+	}
+}
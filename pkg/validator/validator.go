@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 func ValidatePhoneNumber(number string) error {
@@ -20,6 +22,33 @@ func ValidatePhoneNumber(number string) error {
 	return nil
 }
 
+func ValidateURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("URL é obrigatória")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("URL inválida: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL deve usar http ou https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL deve conter host")
+	}
+
+	return nil
+}
+
+var textURLRegex = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// FirstURL devolve o primeiro link http(s) do texto, sem a pontuação que
+// costuma encostar no fim da frase.
+func FirstURL(text string) string {
+	return strings.TrimRight(textURLRegex.FindString(text), ".,;:!?)]}")
+}
+
 func ValidateJSON(r *http.Request, v interface{}) error {
 	if r.Body == nil {
 		return fmt.Errorf("corpo da requisição vazio")